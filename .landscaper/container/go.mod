@@ -6,10 +6,11 @@ require (
 	github.com/gardener/component-cli v0.29.0
 	github.com/gardener/component-spec/bindings-go v0.0.56
 	github.com/gardener/gardener v1.31.0
+	github.com/gardener/gardenlogin-controller-manager/api/config v0.0.0
 	github.com/gardener/landscaper/apis v0.13.0
 	github.com/golang/mock v1.6.0
 	github.com/onsi/ginkgo v1.16.4
-	github.com/onsi/gomega v1.13.0
+	github.com/onsi/gomega v1.18.1
 	github.com/sirupsen/logrus v1.8.1
 	github.com/spf13/cobra v1.2.0
 	github.com/spf13/pflag v1.0.5
@@ -110,7 +111,7 @@ require (
 	golang.org/x/net v0.0.0-20210428140749-89ef3d95e781 // indirect
 	golang.org/x/oauth2 v0.0.0-20210402161424-2e8d93401602 // indirect
 	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c // indirect
-	golang.org/x/sys v0.0.0-20210603081109-ebe580a85c40 // indirect
+	golang.org/x/sys v0.0.0-20211216021012-1d35b9e2eb4e // indirect
 	golang.org/x/term v0.0.0-20210220032956-6a3ed077a48d // indirect
 	golang.org/x/text v0.3.6 // indirect
 	golang.org/x/time v0.0.0-20210611083556-38a9dc6acbc6 // indirect
@@ -140,6 +141,7 @@ require (
 
 replace (
 	github.com/gardener/gardener-resource-manager/api => github.com/gardener/gardener-resource-manager/api v0.25.0
+	github.com/gardener/gardenlogin-controller-manager/api/config => ../../api/config
 	github.com/googleapis/gnostic => github.com/googleapis/gnostic v0.4.1
 	google.golang.org/grpc => google.golang.org/grpc v1.27.1
 	k8s.io/client-go => k8s.io/client-go v0.21.2
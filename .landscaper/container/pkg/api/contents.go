@@ -35,6 +35,11 @@ type Contents struct {
 	// SingleClusterPath holds the path of the single-cluster kustomize overlay
 	SingleClusterPath string
 
+	// ShootStateRBACPath holds the path of the kustomization for the shootstates permission, which is only added to the overlays in case the ShootState ca source is configured
+	ShootStateRBACPath string
+	// SecretRBACPath holds the path of the kustomization for the secrets permission, which is only added to the overlays in case the Secret ca source is configured
+	SecretRBACPath string
+
 	// ManagerConfigurationRuntimePath holds the path of the ControllerManagerConfiguration under the runtime overlay
 	ManagerConfigurationRuntimePath string
 	// ManagerConfigurationSingleClusterPath holds the path of the ControllerManagerConfiguration under the single-cluster overlay
@@ -58,6 +63,9 @@ func NewContentsFromPath(contentPath string) *Contents {
 		RuntimeOverlayPath:       filepath.Join(contentPath, "config", "overlay", "multi-cluster", "runtime"),
 		SingleClusterPath:        filepath.Join(contentPath, "config", "overlay", "single-cluster"),
 
+		ShootStateRBACPath: filepath.Join(contentPath, "config", "rbac-shootstate"),
+		SecretRBACPath:     filepath.Join(contentPath, "config", "rbac-secret"),
+
 		ManagerConfigurationRuntimePath:       filepath.Join(contentPath, "config", "overlay", "multi-cluster", "runtime", "manager", "config.yaml"),
 		ManagerConfigurationSingleClusterPath: filepath.Join(contentPath, "config", "overlay", "single-cluster", "manager", "config.yaml"),
 	}
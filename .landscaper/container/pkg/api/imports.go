@@ -46,7 +46,7 @@ type Imports struct {
 	KubeRBACProxyResources corev1.ResourceRequirements `json:"kubeRbacProxyResources" yaml:"kubeRbacProxyResources"`
 
 	// ManagerConfig is the ControllerManagerConfiguration for the "manager" (gardenlogin-controller-manager) container
-	// map[string]interface{} type is used instead of using the ControllerManagerConfiguration type which would result in a hard dependency to the github.com/gardener/gardenlogin-controller-manager module
-	// within this multi-module repository.
+	// map[string]interface{} type is used instead of using the ControllerManagerConfiguration type so that the imported configuration is deployed as is, without defaults.
+	// The configuration is strictly decoded into the ControllerManagerConfiguration type of the github.com/gardener/gardenlogin-controller-manager/api/config module when the imports are validated.
 	ManagerConfig map[string]interface{} `json:"managerConfig" yaml:"managerConfig"`
 }
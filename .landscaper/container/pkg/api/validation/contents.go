@@ -47,6 +47,14 @@ func ValidateContents(obj *api.Contents) error {
 		return fmt.Errorf("validation failed for single cluster overlay path: %w", err)
 	}
 
+	if err := validatePathExists(obj.ShootStateRBACPath); err != nil {
+		return fmt.Errorf("validation failed for shootstate rbac path: %w", err)
+	}
+
+	if err := validatePathExists(obj.SecretRBACPath); err != nil {
+		return fmt.Errorf("validation failed for secret rbac path: %w", err)
+	}
+
 	return nil
 }
 
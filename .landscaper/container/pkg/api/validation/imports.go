@@ -13,6 +13,9 @@ import (
 	lsv1alpha1 "github.com/gardener/landscaper/apis/core/v1alpha1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
+	configdecoder "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1/decoder"
+	configvalidation "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1/validation"
+
 	"github.com/gardener/gardenlogin-controller-manager/.landscaper/container/pkg/api"
 )
 
@@ -43,6 +46,32 @@ func ValidateImports(obj *api.Imports) field.ErrorList {
 		allErrs = append(allErrs, field.Forbidden(field.NewPath("namespace"), "must not be the garden namespace"))
 	}
 
+	allErrs = append(allErrs, validateManagerConfig(obj.ManagerConfig, field.NewPath("managerConfig"))...)
+
+	return allErrs
+}
+
+// validateManagerConfig strictly decodes the manager config into the ControllerManagerConfiguration type and validates it like the gardenlogin-controller-manager does on startup.
+func validateManagerConfig(managerConfig map[string]interface{}, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	data, err := json.Marshal(managerConfig)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, nil, fmt.Sprintf("unable to marshal manager configuration: %s", err.Error())))
+		return allErrs
+	}
+
+	cfg, err := configdecoder.Decode(data)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, nil, fmt.Sprintf("unable to decode manager configuration: %s", err.Error())))
+		return allErrs
+	}
+
+	for _, fieldErr := range configvalidation.ValidateControllerManagerConfiguration(cfg) {
+		fieldErr.Field = fldPath.String() + "." + fieldErr.Field
+		allErrs = append(allErrs, fieldErr)
+	}
+
 	return allErrs
 }
 
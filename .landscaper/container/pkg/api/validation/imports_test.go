@@ -147,6 +147,78 @@ var _ = Describe("Imports", func() {
 			))
 		})
 
+		It("should pass for a valid manager configuration", func() {
+			obj.ManagerConfig = map[string]interface{}{
+				"apiVersion": "config.gardenlogin.gardener.cloud/v1alpha1",
+				"kind":       "ControllerManagerConfiguration",
+				"controllers": map[string]interface{}{
+					"shoot": map[string]interface{}{
+						"maxConcurrentReconciles": 10,
+						"quotaExceededRetryDelay": "1h",
+					},
+				},
+			}
+
+			Expect(ValidateImports(obj)).To(BeEmpty())
+		})
+
+		It("should pass for a manager configuration with the legacy apiVersion", func() {
+			obj.ManagerConfig = map[string]interface{}{
+				"apiVersion": "v1alpha1",
+				"kind":       "ControllerManagerConfiguration",
+			}
+
+			Expect(ValidateImports(obj)).To(BeEmpty())
+		})
+
+		It("should pass for a manager configuration with the legacy integer form of the quota exceeded retry delay", func() {
+			obj.ManagerConfig = map[string]interface{}{
+				"apiVersion": "v1alpha1",
+				"controllers": map[string]interface{}{
+					"shoot": map[string]interface{}{
+						"quotaExceededRetryDelay": float64(86400000000000),
+					},
+				},
+			}
+
+			Expect(ValidateImports(obj)).To(BeEmpty())
+		})
+
+		It("should fail for a manager configuration with unknown fields", func() {
+			obj.ManagerConfig = map[string]interface{}{
+				"controllers": map[string]interface{}{
+					"shoot": map[string]interface{}{
+						"maxConcurrentReconcile": 10,
+					},
+				},
+			}
+
+			Expect(ValidateImports(obj)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("managerConfig"),
+				})),
+			))
+		})
+
+		It("should fail for an invalid manager configuration", func() {
+			obj.ManagerConfig = map[string]interface{}{
+				"controllers": map[string]interface{}{
+					"shoot": map[string]interface{}{
+						"maxConcurrentReconciles":             1,
+						"maxConcurrentReconcilesPerNamespace": 2,
+					},
+				},
+			}
+
+			Expect(ValidateImports(obj)).To(ConsistOf(
+				PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("managerConfig.controllers.shoot.maxConcurrentReconcilesPerNamespace"),
+				})),
+			))
+		})
+
 		It("should fail if target configuration is invalid", func() {
 			obj.ApplicationClusterTarget.Spec.Configuration = lsv1alpha1.NewAnyJSON([]byte("invalid-config"))
 
@@ -31,6 +31,13 @@ type Interface interface {
 // TLSSecretSuffix is the suffix for the secret that holds the tls certificate for the webhook-service.
 const TLSSecretSuffix = "-tls"
 
+const (
+	// caSourceShootState is the kubeconfig.caSources value of the ControllerManagerConfiguration for reading the certificate authorities from the ShootStates
+	caSourceShootState = "ShootState"
+	// caSourceSecret is the kubeconfig.caSources value of the ControllerManagerConfiguration for reading the certificate authorities from the <shoot>.ca-cluster Secrets
+	caSourceSecret = "Secret"
+)
+
 // operation contains the configuration for a operation.
 type operation struct {
 	// multiCluster holds the data for the multi-cluster deployment scenario with which the runtime part and application part is deployed into separate clusters.
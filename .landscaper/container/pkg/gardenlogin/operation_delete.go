@@ -85,6 +85,13 @@ func (o *operation) deleteApplicationResources(ctx context.Context) error {
 		return err
 	}
 
+	if err := o.deleteShootStateRBAC(ctx); err != nil {
+		return err
+	}
+	if err := o.deleteSecretRBAC(ctx); err != nil {
+		return err
+	}
+
 	vwcKey := client.ObjectKey{Name: fmt.Sprintf("%svalidating-webhook-configuration", o.imports.NamePrefix)}
 	vwc := &admissionregistrationv1.ValidatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: vwcKey.Name}}
 
@@ -92,9 +99,50 @@ func (o *operation) deleteApplicationResources(ctx context.Context) error {
 		return err
 	}
 
+	mwcKey := client.ObjectKey{Name: fmt.Sprintf("%smutating-webhook-configuration", o.imports.NamePrefix)}
+	mwc := &admissionregistrationv1.MutatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: mwcKey.Name}}
+
+	if err := ensureDeleted(ctx, appClient, mwcKey, mwc); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// deleteShootStateRBAC deletes the optional shootstates permission of the gardenlogin-controller-manager from the application cluster if not already deleted
+func (o *operation) deleteShootStateRBAC(ctx context.Context) error {
+	appClient := o.applicationCluster().client
+
+	crbKey := client.ObjectKey{Name: fmt.Sprintf("%smanager-shootstate-rolebinding", o.imports.NamePrefix)}
+	crb := &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: crbKey.Name}}
+
+	if err := ensureDeleted(ctx, appClient, crbKey, crb); err != nil {
+		return err
+	}
+
+	crKey := client.ObjectKey{Name: fmt.Sprintf("%smanager-shootstate-role", o.imports.NamePrefix)}
+	cr := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: crKey.Name}}
+
+	return ensureDeleted(ctx, appClient, crKey, cr)
+}
+
+// deleteSecretRBAC deletes the optional secrets permission of the gardenlogin-controller-manager from the application cluster if not already deleted
+func (o *operation) deleteSecretRBAC(ctx context.Context) error {
+	appClient := o.applicationCluster().client
+
+	crbKey := client.ObjectKey{Name: fmt.Sprintf("%smanager-secret-rolebinding", o.imports.NamePrefix)}
+	crb := &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: crbKey.Name}}
+
+	if err := ensureDeleted(ctx, appClient, crbKey, crb); err != nil {
+		return err
+	}
+
+	crKey := client.ObjectKey{Name: fmt.Sprintf("%smanager-secret-role", o.imports.NamePrefix)}
+	cr := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: crKey.Name}}
+
+	return ensureDeleted(ctx, appClient, crKey, cr)
+}
+
 func ensureDeleted(ctx context.Context, c client.Client, objectKey client.ObjectKey, obj client.Object) error {
 	if err := c.Get(ctx, objectKey, obj); err != nil {
 		if apierrors.IsNotFound(err) {
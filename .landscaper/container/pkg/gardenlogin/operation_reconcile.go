@@ -14,6 +14,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"text/template"
 	"time"
@@ -34,6 +35,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 
+	configdecoder "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1/decoder"
+
 	"github.com/gardener/gardenlogin-controller-manager/.landscaper/container/internal/util"
 )
 
@@ -103,6 +106,19 @@ func (o *operation) Reconcile(ctx context.Context) error {
 		return err
 	}
 
+	if err := o.setShootStateRBAC(ctx, []string{
+		o.contents.VirtualGardenOverlayPath,
+		o.contents.SingleClusterPath,
+	}); err != nil {
+		return err
+	}
+	if err := o.setSecretRBAC(ctx, []string{
+		o.contents.VirtualGardenOverlayPath,
+		o.contents.SingleClusterPath,
+	}); err != nil {
+		return err
+	}
+
 	if !o.imports.MultiClusterDeploymentScenario {
 		// single cluster deployment
 		if err := o.singleCluster.buildAndApplyOverlay(ctx, o.contents.SingleClusterPath); err != nil {
@@ -321,9 +337,17 @@ func setNamePrefix(overlayPaths []string, namePrefix string) error {
 	return nil
 }
 
-// setManagerConfig writes the manger config from the imports to the given overlay paths
+// setManagerConfig writes the manger config from the imports to the given overlay paths.
+// The apiVersion and kind of the config.gardenlogin.gardener.cloud/v1alpha1 API are set in case they are not imported or the legacy apiVersion is imported.
 func (o *operation) setManagerConfig(overlayPaths []string) error {
-	config, err := yaml.Marshal(o.imports.ManagerConfig)
+	managerConfig := make(map[string]interface{}, len(o.imports.ManagerConfig)+2)
+	for key, value := range o.imports.ManagerConfig {
+		managerConfig[key] = value
+	}
+
+	configdecoder.ConvertLegacy(managerConfig)
+
+	config, err := yaml.Marshal(managerConfig)
 	if err != nil {
 		return fmt.Errorf("failed to marshal manager config: %w", err)
 	}
@@ -337,6 +361,72 @@ func (o *operation) setManagerConfig(overlayPaths []string) error {
 	return nil
 }
 
+// setShootStateRBAC uses kustomize cli to add the shootstates permission of the manager to the given overlay paths in case the ShootState ca source is configured.
+// Otherwise, a previously deployed shootstates permission is removed from the application cluster.
+func (o *operation) setShootStateRBAC(ctx context.Context, overlayPaths []string) error {
+	if !usesCASource(o.imports.ManagerConfig, caSourceShootState) {
+		return o.deleteShootStateRBAC(ctx)
+	}
+
+	if err := addResource(overlayPaths, o.contents.ShootStateRBACPath); err != nil {
+		return fmt.Errorf("failed to add shootstate rbac: %w", err)
+	}
+
+	return nil
+}
+
+// setSecretRBAC uses kustomize cli to add the secrets permission of the manager to the given overlay paths in case the Secret ca source is configured.
+// Otherwise, a previously deployed secrets permission is removed from the application cluster.
+func (o *operation) setSecretRBAC(ctx context.Context, overlayPaths []string) error {
+	if !usesCASource(o.imports.ManagerConfig, caSourceSecret) {
+		return o.deleteSecretRBAC(ctx)
+	}
+
+	if err := addResource(overlayPaths, o.contents.SecretRBACPath); err != nil {
+		return fmt.Errorf("failed to add secret rbac: %w", err)
+	}
+
+	return nil
+}
+
+// addResource uses kustomize cli to add the kustomization of the given resource path to the given overlay paths
+func addResource(overlayPaths []string, resourcePath string) error {
+	for _, overlayPath := range overlayPaths {
+		resource, err := filepath.Rel(overlayPath, resourcePath)
+		if err != nil {
+			return fmt.Errorf("failed to determine path %s relative to overlay path %s: %w", resourcePath, overlayPath, err)
+		}
+
+		cmd := exec.Command("kustomize", "edit", "add", "resource", resource)
+		cmd.Dir = overlayPath
+
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to add resource %s for overlay path %s, Output: %s: %w", resource, overlayPath, out, err)
+		}
+	}
+
+	return nil
+}
+
+// usesCASource returns true in case the given manager config reads the certificate authorities from the given ca source.
+// In case kubeconfig.caSources is not set, only the ShootState ca source is used.
+func usesCASource(managerConfig map[string]interface{}, source string) bool {
+	kubeconfig, _ := managerConfig["kubeconfig"].(map[string]interface{})
+
+	caSources, _ := kubeconfig["caSources"].([]interface{})
+	if len(caSources) == 0 {
+		return source == caSourceShootState
+	}
+
+	for _, caSource := range caSources {
+		if caSource == source {
+			return true
+		}
+	}
+
+	return false
+}
+
 // patchResourceRequirements uses kustomize cli to patch the resource requirements for the manager and kube-rbac-proxy container according to the import parameters
 func (o *operation) patchResourceRequirements(overlayPaths []string) error {
 	patch := bytes.NewBuffer(nil)
@@ -163,6 +163,10 @@ var _ = Describe("Operation Reconcile", func() {
 			cr := &rbacv1.ClusterRole{}
 			Expect(testClient.Get(ctx, crKey, cr)).To(Succeed())
 
+			By("verifying that the shootstates permission is deployed for the default ca source")
+			shootStateCRKey := client.ObjectKey{Name: fmt.Sprintf("%smanager-shootstate-role", imports.NamePrefix)}
+			Expect(testClient.Get(ctx, shootStateCRKey, &rbacv1.ClusterRole{})).To(Succeed())
+
 			By("running delete op")
 			Expect(op.Delete(ctx)).NotTo(HaveOccurred())
 
@@ -179,6 +183,51 @@ var _ = Describe("Operation Reconcile", func() {
 				err = testClient.Get(ctx, crKey, cr)
 				return errors.IsNotFound(err) || !cr.GetDeletionTimestamp().IsZero()
 			}).Should(BeTrue())
+			Eventually(func() bool {
+				err = testClient.Get(ctx, shootStateCRKey, cr)
+				return errors.IsNotFound(err) || !cr.GetDeletionTimestamp().IsZero()
+			}).Should(BeTrue())
+		})
+
+		It("should not deploy the shootstates permission in case the ShootState ca source is not configured", func() {
+			imports.ManagerConfig = map[string]interface{}{
+				"kubeconfig": map[string]interface{}{
+					"caSources": []interface{}{"ConfigMap"},
+				},
+			}
+
+			op, err = gardenlogin.NewOperation(f, log, imports, imageRefs, contents)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(op.Reconcile(ctx)).NotTo(HaveOccurred())
+
+			crKey := client.ObjectKey{Name: fmt.Sprintf("%smanager-shootstate-role", imports.NamePrefix)}
+			Expect(errors.IsNotFound(testClient.Get(ctx, crKey, &rbacv1.ClusterRole{}))).To(BeTrue())
+		})
+
+		It("should deploy the secrets permission only in case the Secret ca source is configured", func() {
+			op, err = gardenlogin.NewOperation(f, log, imports, imageRefs, contents)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(op.Reconcile(ctx)).NotTo(HaveOccurred())
+
+			crKey := client.ObjectKey{Name: fmt.Sprintf("%smanager-secret-role", imports.NamePrefix)}
+			Expect(errors.IsNotFound(testClient.Get(ctx, crKey, &rbacv1.ClusterRole{}))).To(BeTrue())
+
+			imports.ManagerConfig = map[string]interface{}{
+				"kubeconfig": map[string]interface{}{
+					"caSources": []interface{}{"ShootState", "Secret"},
+				},
+			}
+
+			op, err = gardenlogin.NewOperation(f, log, imports, imageRefs, contents)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(op.Reconcile(ctx)).NotTo(HaveOccurred())
+
+			cr := &rbacv1.ClusterRole{}
+			Expect(testClient.Get(ctx, crKey, cr)).To(Succeed())
+			Expect(cr.Rules).To(ConsistOf(rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}}))
 		})
 
 		It("should not error when running delete operation multiple times", func() {
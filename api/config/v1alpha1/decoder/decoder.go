@@ -0,0 +1,130 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package decoder decodes ControllerManagerConfigurations, including configurations written before the configuration was turned into an API group.
+package decoder
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/yaml"
+
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
+)
+
+// LegacyAPIVersion is the apiVersion that was used by configuration files before the configuration was turned into an API group
+const LegacyAPIVersion = "v1alpha1"
+
+var (
+	scheme = runtime.NewScheme()
+	codecs = serializer.NewCodecFactory(scheme, serializer.EnableStrict)
+)
+
+func init() {
+	utilruntime.Must(configv1alpha1.AddToScheme(scheme))
+}
+
+// Decode strictly decodes the given configuration into the config.gardenlogin.gardener.cloud/v1alpha1 version and applies the defaults.
+// Configurations without type information or with the legacy apiVersion are treated as config.gardenlogin.gardener.cloud/v1alpha1.
+// Unknown fields are rejected.
+func Decode(data []byte) (*configv1alpha1.ControllerManagerConfiguration, error) {
+	typeMeta := &metav1.TypeMeta{}
+	if err := yaml.Unmarshal(data, typeMeta); err != nil {
+		return nil, fmt.Errorf("failed to read type information of configuration: %w", err)
+	}
+
+	if IsLegacy(typeMeta) {
+		content := map[string]interface{}{}
+		if err := yaml.Unmarshal(data, &content); err != nil {
+			return nil, fmt.Errorf("failed to read configuration: %w", err)
+		}
+
+		if content == nil {
+			content = map[string]interface{}{}
+		}
+
+		ConvertLegacy(content)
+
+		var err error
+		if data, err = yaml.Marshal(content); err != nil {
+			return nil, fmt.Errorf("failed to convert configuration: %w", err)
+		}
+	}
+
+	obj, gvk, err := codecs.UniversalDecoder(configv1alpha1.SchemeGroupVersion).Decode(data, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode configuration: %w", err)
+	}
+
+	cfg, ok := obj.(*configv1alpha1.ControllerManagerConfiguration)
+	if !ok {
+		return nil, fmt.Errorf("unsupported configuration kind %s, expected ControllerManagerConfiguration", gvk)
+	}
+
+	return cfg, nil
+}
+
+// IsLegacy returns true in case the given type information is missing or uses the legacy apiVersion.
+func IsLegacy(typeMeta *metav1.TypeMeta) bool {
+	return typeMeta.APIVersion == "" || typeMeta.APIVersion == LegacyAPIVersion || typeMeta.Kind == ""
+}
+
+// ConvertLegacy converts the given configuration in case its type information is missing or uses the legacy apiVersion.
+// The type information is replaced by the one of config.gardenlogin.gardener.cloud/v1alpha1 and
+// the legacy integer form (nanoseconds) of controllers.shoot.quotaExceededRetryDelay is converted into a duration string.
+func ConvertLegacy(content map[string]interface{}) {
+	apiVersion, _ := content["apiVersion"].(string)
+	kind, _ := content["kind"].(string)
+
+	if !IsLegacy(&metav1.TypeMeta{APIVersion: apiVersion, Kind: kind}) {
+		return
+	}
+
+	if apiVersion == "" || apiVersion == LegacyAPIVersion {
+		content["apiVersion"] = configv1alpha1.SchemeGroupVersion.String()
+	}
+
+	if kind == "" {
+		content["kind"] = "ControllerManagerConfiguration"
+	}
+
+	convertLegacyDuration(content, "controllers", "shoot", "quotaExceededRetryDelay")
+}
+
+// convertLegacyDuration replaces the integer value (nanoseconds) of the given field by a duration string, e.g. 24h0m0s.
+// Values of other types are left untouched, so that they are rejected or accepted when decoding.
+func convertLegacyDuration(content map[string]interface{}, fields ...string) {
+	value, found, err := unstructured.NestedFieldNoCopy(content, fields...)
+	if err != nil || !found {
+		return
+	}
+
+	var nanoseconds int64
+
+	switch v := value.(type) {
+	case float64:
+		if v != math.Trunc(v) {
+			return
+		}
+
+		nanoseconds = int64(v)
+	case int64:
+		nanoseconds = v
+	case int:
+		nanoseconds = int64(v)
+	default:
+		return
+	}
+
+	_ = unstructured.SetNestedField(content, time.Duration(nanoseconds).String(), fields...)
+}
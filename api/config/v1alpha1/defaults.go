@@ -0,0 +1,165 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientauthenticationv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
+)
+
+// ClientAuthenticationV1APIVersion is the client.authentication.k8s.io/v1 API version.
+// The constant is used instead of the clientauthentication/v1 package, which is not available in the client-go version of the landscaper container deployer.
+const ClientAuthenticationV1APIVersion = "client.authentication.k8s.io/v1"
+
+// execAPIV1KubernetesVersion is the kubernetes version of the shoots from which on the kubeconfigs are rendered with the
+// client.authentication.k8s.io/v1 exec API by default. kubectl supports it from v1.22.0 onwards and, as kubectl is only supported
+// within one minor version of the kube-apiserver, the oldest kubectl supported for these shoots is v1.23.0.
+const execAPIV1KubernetesVersion = "v1.24.0"
+
+func addDefaultingFuncs(scheme *runtime.Scheme) error {
+	scheme.AddTypeDefaultingFunc(&ControllerManagerConfiguration{}, func(obj interface{}) {
+		SetDefaults_ControllerManagerConfiguration(obj.(*ControllerManagerConfiguration))
+	})
+
+	return nil
+}
+
+// SetDefaults_ControllerManagerConfiguration sets default values for ControllerManagerConfiguration objects.
+func SetDefaults_ControllerManagerConfiguration(obj *ControllerManagerConfiguration) { //nolint:golint,revive // the naming follows the convention of the defaulting functions
+	setDefaultsControllers(&obj.Controllers)
+
+	if obj.Webhooks.ConfigMapValidation.MaxObjectSize == 0 {
+		obj.Webhooks.ConfigMapValidation.MaxObjectSize = 100 * 1024
+	}
+
+	if obj.Webhooks.ConfigMapValidation.DeletionAllowedUsers == nil {
+		obj.Webhooks.ConfigMapValidation.DeletionAllowedUsers = []string{
+			"system:serviceaccount:kube-system:generic-garbage-collector",
+			"system:serviceaccount:kube-system:namespace-controller",
+		}
+	}
+
+	if obj.Webhooks.ConfigMapValidation.AccessReviewCache.AllowedTTL.Duration == 0 {
+		obj.Webhooks.ConfigMapValidation.AccessReviewCache.AllowedTTL = metav1.Duration{Duration: 5 * time.Minute}
+	}
+
+	if obj.Webhooks.ConfigMapValidation.AccessReviewCache.DeniedTTL.Duration == 0 {
+		obj.Webhooks.ConfigMapValidation.AccessReviewCache.DeniedTTL = metav1.Duration{Duration: 30 * time.Second}
+	}
+
+	setDefaultsKubeconfig(&obj.Kubeconfig)
+
+	for i := range obj.Gardens {
+		if obj.Gardens[i].LeaderElectionNamespace == "" {
+			obj.Gardens[i].LeaderElectionNamespace = metav1.NamespaceSystem
+		}
+	}
+}
+
+func setDefaultsControllers(obj *ControllerManagerControllerConfiguration) {
+	if obj.Shoot.MaxConcurrentReconciles == 0 {
+		obj.Shoot.MaxConcurrentReconciles = 50
+	}
+
+	if obj.Shoot.MaxConcurrentReconcilesPerNamespace == 0 {
+		obj.Shoot.MaxConcurrentReconcilesPerNamespace = 3
+	}
+
+	if obj.Shoot.QuotaExceededRetryDelay.Duration == 0 {
+		obj.Shoot.QuotaExceededRetryDelay = metav1.Duration{Duration: 24 * time.Hour}
+	}
+
+	if obj.Shoot.QuotaExhaustedPolicy == "" {
+		obj.Shoot.QuotaExhaustedPolicy = QuotaExhaustedPolicyWait
+	}
+
+	if obj.Project.MaxConcurrentReconciles == 0 {
+		obj.Project.MaxConcurrentReconciles = 5
+	}
+
+	if obj.Project.QuotaExceededRetryDelay.Duration == 0 {
+		obj.Project.QuotaExceededRetryDelay = metav1.Duration{Duration: 24 * time.Hour}
+	}
+
+	if obj.KubeconfigBundle.MaxConcurrentReconciles == 0 {
+		obj.KubeconfigBundle.MaxConcurrentReconciles = 5
+	}
+
+	if obj.KubeconfigBundle.AuthorizationRecheckInterval.Duration == 0 {
+		obj.KubeconfigBundle.AuthorizationRecheckInterval = metav1.Duration{Duration: 10 * time.Minute}
+	}
+
+	if obj.KubeconfigBundle.QuotaExceededRetryDelay.Duration == 0 {
+		obj.KubeconfigBundle.QuotaExceededRetryDelay = metav1.Duration{Duration: 24 * time.Hour}
+	}
+
+	if obj.Garden.MaxConcurrentReconciles == 0 {
+		obj.Garden.MaxConcurrentReconciles = 5
+	}
+
+	if obj.Garden.QuotaExceededRetryDelay.Duration == 0 {
+		obj.Garden.QuotaExceededRetryDelay = metav1.Duration{Duration: 24 * time.Hour}
+	}
+}
+
+func setDefaultsKubeconfig(obj *KubeconfigConfiguration) {
+	if len(obj.Formats) == 0 {
+		obj.Formats = []KubeconfigFormat{
+			{
+				KubernetesVersionConstraint: "< v1.20.0",
+				Legacy:                      true,
+			},
+			{
+				KubernetesVersionConstraint: ">= v1.20.0, < " + execAPIV1KubernetesVersion,
+				ExecAPIVersion:              clientauthenticationv1beta1.SchemeGroupVersion.String(),
+			},
+			{
+				KubernetesVersionConstraint: ">= " + execAPIV1KubernetesVersion,
+				ExecAPIVersion:              ClientAuthenticationV1APIVersion,
+			},
+		}
+	}
+
+	for i := range obj.Formats {
+		if obj.Formats[i].ExecAPIVersion == "" {
+			obj.Formats[i].ExecAPIVersion = clientauthenticationv1beta1.SchemeGroupVersion.String()
+		}
+	}
+
+	if len(obj.CASources) == 0 {
+		obj.CASources = []CASourceType{CASourceShootState}
+	}
+
+	if obj.Garden.Name == "" {
+		obj.Garden.Name = "garden"
+	}
+
+	if obj.Garden.Exec != nil && obj.Garden.Exec.APIVersion == "" {
+		obj.Garden.Exec.APIVersion = clientauthenticationv1beta1.SchemeGroupVersion.String()
+	}
+
+	exec := &obj.Exec
+
+	if exec.Command == "" {
+		if exec.Standalone {
+			exec.Command = "kubectl-gardenlogin"
+		} else {
+			exec.Command = "kubectl"
+		}
+	}
+
+	if len(exec.Args) == 0 {
+		if exec.Standalone {
+			exec.Args = []string{"get-client-certificate"}
+		} else {
+			exec.Args = []string{"gardenlogin", "get-client-certificate"}
+		}
+	}
+}
@@ -0,0 +1,10 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package v1alpha1 contains the configuration API of the gardenlogin-controller-manager.
+// +kubebuilder:object:generate=true
+// +groupName=config.gardenlogin.gardener.cloud
+package v1alpha1
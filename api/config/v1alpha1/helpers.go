@@ -0,0 +1,61 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver"
+	clientauthenticationv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
+)
+
+// FormatFor returns the kubeconfig format for the given kubernetes version.
+func (k *KubeconfigConfiguration) FormatFor(kubernetesVersion string) (KubeconfigFormat, error) {
+	version, err := semver.NewVersion(kubernetesVersion)
+	if err != nil {
+		return KubeconfigFormat{}, fmt.Errorf("could not parse kubernetes version %s: %w", kubernetesVersion, err)
+	}
+
+	for _, format := range k.Formats {
+		c, err := semver.NewConstraint(format.KubernetesVersionConstraint)
+		if err != nil {
+			return KubeconfigFormat{}, fmt.Errorf("failed to parse constraint: %w", err)
+		}
+
+		if c.Check(version) {
+			return format, nil
+		}
+	}
+
+	return KubeconfigFormat{
+		ExecAPIVersion: clientauthenticationv1beta1.SchemeGroupVersion.String(),
+	}, nil
+}
+
+// Args returns the arguments of the kubelogin (kubectl oidc-login) credential plugin.
+func (o *GardenOIDCConfiguration) Args() []string {
+	args := []string{
+		"oidc-login",
+		"get-token",
+		fmt.Sprintf("--oidc-issuer-url=%s", o.IssuerURL),
+		fmt.Sprintf("--oidc-client-id=%s", o.ClientID),
+	}
+
+	if o.ClientSecret != "" {
+		args = append(args, fmt.Sprintf("--oidc-client-secret=%s", o.ClientSecret))
+	}
+
+	for _, scope := range o.ExtraScopes {
+		args = append(args, fmt.Sprintf("--oidc-extra-scope=%s", scope))
+	}
+
+	if o.UsePKCE {
+		args = append(args, "--oidc-use-pkce")
+	}
+
+	return args
+}
@@ -0,0 +1,309 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+
+// ControllerManagerConfiguration defines the configuration for the gardenlogin controller manager.
+type ControllerManagerConfiguration struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Controllers defines the configuration of the controllers.
+	Controllers ControllerManagerControllerConfiguration `json:"controllers,omitempty"`
+	// Webhooks defines the configuration of the admission webhooks.
+	Webhooks ControllerManagerWebhookConfiguration `json:"webhooks,omitempty"`
+	// Kubeconfig defines how the kubeconfigs are rendered.
+	Kubeconfig KubeconfigConfiguration `json:"kubeconfig,omitempty"`
+	// Gardens are additional garden clusters served by this process. For each of them, a Shoot controller with its own caches
+	// and leader election is started, which maintains the kubeconfig configMaps of the shoots of that garden cluster.
+	// The garden cluster of the manager is always served.
+	Gardens []GardenClusterConfiguration `json:"gardens,omitempty"`
+}
+
+// GardenClusterConfiguration defines an additional garden cluster served by the gardenlogin-controller-manager.
+type GardenClusterConfiguration struct {
+	// Name identifies the garden cluster in the logs, the metrics, the paths of its webhooks and as name of its Shoot controller.
+	// It must be a unique DNS label other than main, which identifies the garden cluster the manager runs in.
+	Name string `json:"name"`
+	// Kubeconfig is the path to the kubeconfig file used to access the garden cluster.
+	Kubeconfig string `json:"kubeconfig"`
+	// ClusterIdentity is the cluster identity of the garden cluster that is rendered into the kubeconfigs.
+	// Defaults to the identity of the cluster-identity configMap in the kube-system namespace of the garden cluster.
+	ClusterIdentity string `json:"clusterIdentity,omitempty"`
+	// LeaderElectionNamespace is the namespace in the garden cluster in which the leader election lock is maintained. Defaults to kube-system.
+	LeaderElectionNamespace string `json:"leaderElectionNamespace,omitempty"`
+	// GateReadiness adds the readiness checks of the garden cluster to the readiness of the manager. As the webhooks are only served
+	// while the manager is ready, an unavailable garden cluster then takes the webhooks out of service. Defaults to false, in which case
+	// the readiness of the garden cluster is only exposed by the gardenlogin_garden_ready metric.
+	GateReadiness bool `json:"gateReadiness,omitempty"`
+}
+
+// ControllerManagerControllerConfiguration defines the configuration of the controllers.
+type ControllerManagerControllerConfiguration struct {
+	// Shoot defines the configuration of the Shoot controller.
+	Shoot ShootControllerConfiguration `json:"shoot,omitempty"`
+	// Project defines the configuration of the Project controller.
+	Project ProjectControllerConfiguration `json:"project,omitempty"`
+	// KubeconfigBundle defines the configuration of the KubeconfigBundle controller.
+	KubeconfigBundle KubeconfigBundleControllerConfiguration `json:"kubeconfigBundle,omitempty"`
+	// Garden defines the configuration of the Garden controller.
+	Garden GardenControllerConfiguration `json:"garden,omitempty"`
+}
+
+// ShootControllerConfiguration defines the configuration of the Shoot controller.
+type ShootControllerConfiguration struct {
+	// MaxConcurrentReconciles is the maximum number of concurrent Reconciles which can be run. Defaults to 50.
+	MaxConcurrentReconciles int `json:"maxConcurrentReconciles,omitempty"`
+
+	// MaxConcurrentReconcilesPerNamespace is the maximum number of concurrent Reconciles which can be run per Namespace (independent of the user who created the Shoot resource). Requests that exceed the limit wait and free reconcile slots are handed out round-robin across the namespaces with waiting requests. Defaults to 3.
+	MaxConcurrentReconcilesPerNamespace int `json:"maxConcurrentReconcilesPerNamespace,omitempty"`
+
+	// QuotaExceededRetryDelay is the duration, after which the reconciliation will be retried again in case the configMap quota is exceeded.
+	// Note that in case the resource quota for count/configmaps is increased or configMap quota was freed a reconciliation is requested for all shoots in the namespace that do not already have a corresponding <shootname>.kubeconfig configMap.
+	// Defaults to 24 hours.
+	QuotaExceededRetryDelay metav1.Duration `json:"quotaExceededRetryDelay,omitempty"`
+
+	// QuotaExhaustedPolicy defines how kubeconfig configMaps are created in case the configMap quota of the namespace is exhausted, either Wait or Prioritize.
+	// With Wait, the shoots wait for free quota in no particular order. With Prioritize, orphaned kubeconfig configMaps are garbage collected,
+	// free quota is handed to the waiting shoots in priority order, i.e. shoots with purpose production first and then by creation time,
+	// and the waiting shoots are reported in an event on the exhausted ResourceQuota. Defaults to Wait.
+	QuotaExhaustedPolicy QuotaExhaustedPolicy `json:"quotaExhaustedPolicy,omitempty"`
+}
+
+// QuotaExhaustedPolicy defines how kubeconfig configMaps are created in case the configMap quota of the namespace is exhausted
+type QuotaExhaustedPolicy string
+
+const (
+	// QuotaExhaustedPolicyWait retries the creation of kubeconfig configMaps in no particular order once quota is freed
+	QuotaExhaustedPolicyWait QuotaExhaustedPolicy = "Wait"
+	// QuotaExhaustedPolicyPrioritize garbage collects orphaned kubeconfig configMaps and hands free quota to the shoots in priority order
+	QuotaExhaustedPolicyPrioritize QuotaExhaustedPolicy = "Prioritize"
+)
+
+// ProjectControllerConfiguration defines the configuration of the Project controller, which maintains a project.kubeconfig configMap
+// in each project namespace containing the clusters, contexts and users of all shoots of the project.
+type ProjectControllerConfiguration struct {
+	// Enabled defines if the Project controller is started. Defaults to false.
+	Enabled bool `json:"enabled,omitempty"`
+
+	// MaxConcurrentReconciles is the maximum number of concurrent Reconciles which can be run. Defaults to 5.
+	MaxConcurrentReconciles int `json:"maxConcurrentReconciles,omitempty"`
+
+	// QuotaExceededRetryDelay is the duration, after which the reconciliation will be retried again in case the configMap quota is exceeded.
+	// Defaults to 24 hours.
+	QuotaExceededRetryDelay metav1.Duration `json:"quotaExceededRetryDelay,omitempty"`
+}
+
+// KubeconfigBundleControllerConfiguration defines the configuration of the KubeconfigBundle controller, which renders a kubeconfig
+// containing the clusters, contexts and users of the shoots selected by a KubeconfigBundle resource across several namespaces.
+type KubeconfigBundleControllerConfiguration struct {
+	// Enabled defines if the KubeconfigBundle controller is started. Defaults to false.
+	Enabled bool `json:"enabled,omitempty"`
+
+	// MaxConcurrentReconciles is the maximum number of concurrent Reconciles which can be run. Defaults to 5.
+	MaxConcurrentReconciles int `json:"maxConcurrentReconciles,omitempty"`
+
+	// AuthorizationRecheckInterval is the duration after which a KubeconfigBundle is reconciled again, to verify that the requester is still allowed to access the shoots of all source namespaces.
+	// Defaults to 10 minutes.
+	AuthorizationRecheckInterval metav1.Duration `json:"authorizationRecheckInterval,omitempty"`
+
+	// QuotaExceededRetryDelay is the duration, after which the reconciliation will be retried again in case the configMap quota is exceeded.
+	// Defaults to 24 hours.
+	QuotaExceededRetryDelay metav1.Duration `json:"quotaExceededRetryDelay,omitempty"`
+}
+
+// GardenControllerConfiguration defines the configuration of the Garden controller, which maintains a garden.kubeconfig configMap
+// in each project namespace containing a kubeconfig for the garden cluster as configured in kubeconfig.garden.
+type GardenControllerConfiguration struct {
+	// Enabled defines if the Garden controller is started. Defaults to false.
+	Enabled bool `json:"enabled,omitempty"`
+
+	// MaxConcurrentReconciles is the maximum number of concurrent Reconciles which can be run. Defaults to 5.
+	MaxConcurrentReconciles int `json:"maxConcurrentReconciles,omitempty"`
+
+	// QuotaExceededRetryDelay is the duration, after which the reconciliation will be retried again in case the configMap quota is exceeded.
+	// Defaults to 24 hours.
+	QuotaExceededRetryDelay metav1.Duration `json:"quotaExceededRetryDelay,omitempty"`
+}
+
+// ControllerManagerWebhookConfiguration defines the configuration of the admission webhooks.
+type ControllerManagerWebhookConfiguration struct {
+	// ConfigMapValidation defines the configuration of the validating webhook.
+	ConfigMapValidation ConfigMapValidatingWebhookConfiguration `json:"configMapValidation,omitempty"`
+}
+
+// ConfigMapValidatingWebhookConfiguration defines the configuration of the validating webhook.
+type ConfigMapValidatingWebhookConfiguration struct {
+	// MaxObjectSize is the maximum size of a configMap resource in bytes. Defaults to 102400.
+	MaxObjectSize int `json:"maxObjectSize,omitempty"`
+
+	// DeletionAllowedUsers are the names of the users that may delete kubeconfig configMaps without having the permission to manage configMaps,
+	// e.g. the service accounts of the garbage collector and of the namespace controller, which delete the configMaps of deleted shoots and namespaces.
+	// Defaults to system:serviceaccount:kube-system:generic-garbage-collector and system:serviceaccount:kube-system:namespace-controller.
+	// An empty list disables the allowlist.
+	DeletionAllowedUsers []string `json:"deletionAllowedUsers,omitempty"`
+
+	// Strict enables the verification of the clusters of kubeconfig configMaps against the Shoots they belong to, i.e. the Shoot named by a <shoot>.kubeconfig configMap
+	// and the Shoot referenced by each cluster of the project and bundle kubeconfigs. The servers of the kubeconfig must match the advertised addresses of the Shoot
+	// and the certificate authority must match the one read from the configured ca sources. The clusters of the garden kubeconfig must match the configured garden cluster.
+	// Defaults to false.
+	Strict bool `json:"strict,omitempty"`
+
+	// WarnOnly enables the audit mode of the validating webhook, in which kubeconfig configMaps are not denied because of their kubeconfig,
+	// i.e. the validation of the scope, the credentials, the shoot references and the Strict validation. Instead, the reason of the denial
+	// is returned as admission warning, e.g. to roll out a stricter validation safely. The permission to manage configMaps, the deletion
+	// protection and the MaxObjectSize are still enforced.
+	// Defaults to false.
+	WarnOnly bool `json:"warnOnly,omitempty"`
+
+	// AccessReviewCache defines the caching of the SubjectAccessReviews, which verify that a user is allowed to manage kubeconfig configMaps.
+	AccessReviewCache AccessReviewCacheConfiguration `json:"accessReviewCache,omitempty"`
+
+	// AccessReviewSkippedUsers are the names of the users that are allowed to manage kubeconfig configMaps without a SubjectAccessReview,
+	// e.g. the service account of the gardenlogin-controller-manager. The kubeconfig of the configMaps is still validated.
+	AccessReviewSkippedUsers []string `json:"accessReviewSkippedUsers,omitempty"`
+}
+
+// AccessReviewCacheConfiguration defines the in-memory cache of SubjectAccessReview results of the validating webhook.
+// The results are cached per user, groups, extra, namespace and name.
+type AccessReviewCacheConfiguration struct {
+	// Enabled defines if the results of SubjectAccessReviews are cached. Defaults to false.
+	Enabled bool `json:"enabled,omitempty"`
+
+	// AllowedTTL is the duration for which an allowed result is cached. Defaults to 5 minutes.
+	AllowedTTL metav1.Duration `json:"allowedTTL,omitempty"`
+
+	// DeniedTTL is the duration for which a denied result is cached. Defaults to 30 seconds.
+	DeniedTTL metav1.Duration `json:"deniedTTL,omitempty"`
+}
+
+// KubeconfigConfiguration defines how the kubeconfigs are rendered.
+type KubeconfigConfiguration struct {
+	// Exec defines the exec section of the users of the rendered kubeconfigs, which calls the gardenlogin credential plugin.
+	Exec ExecConfiguration `json:"exec,omitempty"`
+	// Formats maps the kubernetes version of the shoot to the format of the rendered kubeconfig. The first format with a matching constraint is used.
+	// In case no format matches, a kubeconfig with cluster extensions and exec API version client.authentication.k8s.io/v1beta1 is rendered.
+	// Defaults to legacy kubeconfigs for shoots with kubernetes version < v1.20.0 and kubeconfigs with cluster extensions for all other shoots,
+	// whose exec API version is client.authentication.k8s.io/v1 for shoots with kubernetes version >= v1.24.0 and client.authentication.k8s.io/v1beta1 otherwise.
+	Formats []KubeconfigFormat `json:"formats,omitempty"`
+	// CASources are the sources the cluster certificate authority of the shoots is read from. The sources are tried in the given order
+	// and the first source that holds the certificate authority of a shoot is used. Defaults to [ShootState].
+	// Note that project members can create ConfigMaps and Secrets in the project namespace, hence the ConfigMap and Secret sources
+	// should only be configured in case the ShootState is not available.
+	CASources []CASourceType `json:"caSources,omitempty"`
+	// Garden defines the kubeconfig for the garden cluster, which is rendered by the Garden controller.
+	Garden GardenKubeconfigConfiguration `json:"garden,omitempty"`
+}
+
+// GardenKubeconfigConfiguration defines the kubeconfig for the garden cluster. Exactly one of OIDC or Exec must be set.
+type GardenKubeconfigConfiguration struct {
+	// Name is the name of the cluster and user of the garden kubeconfig. The name of the context is <name>--<project namespace>. Defaults to "garden".
+	Name string `json:"name,omitempty"`
+	// Server is the URL of the kube-apiserver of the garden cluster, e.g. https://api.garden.example.com.
+	Server string `json:"server,omitempty"`
+	// CertificateAuthority is the PEM encoded CA bundle of the kube-apiserver of the garden cluster.
+	// Can be omitted in case the certificate of the kube-apiserver is signed by a publicly trusted CA.
+	CertificateAuthority string `json:"certificateAuthority,omitempty"`
+	// OIDC defines that the users authenticate with the kubelogin (kubectl oidc-login) credential plugin.
+	OIDC *GardenOIDCConfiguration `json:"oidc,omitempty"`
+	// Exec defines that the users authenticate with the given credential plugin.
+	Exec *GardenExecConfiguration `json:"exec,omitempty"`
+}
+
+// GardenOIDCConfiguration defines the arguments of the kubelogin (kubectl oidc-login) credential plugin.
+type GardenOIDCConfiguration struct {
+	// IssuerURL is the URL of the OpenID Connect provider.
+	IssuerURL string `json:"issuerURL,omitempty"`
+	// ClientID is the client ID of the OpenID Connect client.
+	ClientID string `json:"clientID,omitempty"`
+	// ClientSecret is the client secret of the OpenID Connect client. Must only be set for public clients, as it is readable by all project members.
+	ClientSecret string `json:"clientSecret,omitempty"`
+	// ExtraScopes are additional scopes to request, e.g. email or groups.
+	ExtraScopes []string `json:"extraScopes,omitempty"`
+	// UsePKCE defines if the authorization code flow is secured with PKCE.
+	UsePKCE bool `json:"usePKCE,omitempty"`
+}
+
+// GardenExecConfiguration defines the exec section of the user of the garden kubeconfig.
+type GardenExecConfiguration struct {
+	// APIVersion is the API version of the exec section, either client.authentication.k8s.io/v1beta1 or client.authentication.k8s.io/v1.
+	// Defaults to client.authentication.k8s.io/v1beta1.
+	APIVersion string `json:"apiVersion,omitempty"`
+	// Command is the command to execute.
+	Command string `json:"command,omitempty"`
+	// Args are the arguments passed to the command.
+	Args []string `json:"args,omitempty"`
+	// Env defines additional environment variables to expose to the process.
+	Env []ExecEnvVar `json:"env,omitempty"`
+	// InstallHint is printed by kubectl in case the command could not be found.
+	InstallHint string `json:"installHint,omitempty"`
+	// InteractiveMode determines the relationship of the plugin with standard input. Valid values are "Never", "IfAvailable" and "Always".
+	InteractiveMode string `json:"interactiveMode,omitempty"`
+}
+
+// KubeconfigFormat defines the format of a rendered kubeconfig.
+type KubeconfigFormat struct {
+	// KubernetesVersionConstraint is a semantic version constraint for the kubernetes version of the shoot, e.g. "< v1.20.0".
+	KubernetesVersionConstraint string `json:"kubernetesVersionConstraint,omitempty"`
+	// Legacy defines if the shoot reference and garden cluster identity are passed as command line flags to the plugin instead of via the cluster extensions.
+	// This is required for kubectl versions < v1.20.0.
+	Legacy bool `json:"legacy,omitempty"`
+	// ExecAPIVersion is the API version of the exec section, either client.authentication.k8s.io/v1beta1 or client.authentication.k8s.io/v1.
+	// Defaults to client.authentication.k8s.io/v1beta1.
+	ExecAPIVersion string `json:"execAPIVersion,omitempty"`
+}
+
+// ExecConfiguration defines the exec section of the users of the rendered kubeconfigs.
+type ExecConfiguration struct {
+	// Standalone defines if the kubectl-gardenlogin binary is called directly instead of going through kubectl. Defaults to false.
+	Standalone bool `json:"standalone,omitempty"`
+	// Command is the command to execute. Defaults to "kubectl", or "kubectl-gardenlogin" in case Standalone is true.
+	Command string `json:"command,omitempty"`
+	// Args are the arguments passed to the command.
+	// Defaults to ["gardenlogin", "get-client-certificate"], or ["get-client-certificate"] in case Standalone is true.
+	Args []string `json:"args,omitempty"`
+	// Env defines additional environment variables to expose to the process.
+	Env []ExecEnvVar `json:"env,omitempty"`
+	// InstallHint is printed by kubectl in case the command could not be found.
+	InstallHint string `json:"installHint,omitempty"`
+	// InteractiveMode determines the relationship of the plugin with standard input. Valid values are "Never", "IfAvailable" and "Always".
+	// If not set, kubectl defaults to "IfAvailable".
+	InteractiveMode string `json:"interactiveMode,omitempty"`
+}
+
+// ExecEnvVar is an environment variable that is exposed to the exec'd process.
+type ExecEnvVar struct {
+	// Name is the name of the environment variable.
+	Name string `json:"name,omitempty"`
+	// Value is the value of the environment variable.
+	Value string `json:"value,omitempty"`
+}
+
+// CASourceType is the type of source the cluster certificate authority of a shoot is read from
+type CASourceType string
+
+const (
+	// CASourceShootState reads the certificate authority from the gardener resource data of the ShootState of the shoot
+	CASourceShootState CASourceType = "ShootState"
+	// CASourceConfigMap reads the certificate authority from the <shoot>.ca-cluster ConfigMap in the project namespace
+	CASourceConfigMap CASourceType = "ConfigMap"
+	// CASourceSecret reads the certificate authority from the <shoot>.ca-cluster Secret in the project namespace
+	CASourceSecret CASourceType = "Secret"
+)
+
+const (
+	// ExecInteractiveModeNever means that the plugin never uses standard input.
+	ExecInteractiveModeNever = "Never"
+	// ExecInteractiveModeIfAvailable means that the plugin uses standard input if it is available.
+	ExecInteractiveModeIfAvailable = "IfAvailable"
+	// ExecInteractiveModeAlways means that the plugin requires standard input.
+	ExecInteractiveModeAlways = "Always"
+)
@@ -0,0 +1,378 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package validation
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+
+	"github.com/Masterminds/semver"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clientauthenticationv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
+
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
+)
+
+var supportedExecAPIVersions = []string{
+	clientauthenticationv1beta1.SchemeGroupVersion.String(),
+	configv1alpha1.ClientAuthenticationV1APIVersion,
+}
+
+var supportedCASources = []string{
+	string(configv1alpha1.CASourceShootState),
+	string(configv1alpha1.CASourceConfigMap),
+	string(configv1alpha1.CASourceSecret),
+}
+
+var supportedQuotaExhaustedPolicies = []string{
+	string(configv1alpha1.QuotaExhaustedPolicyWait),
+	string(configv1alpha1.QuotaExhaustedPolicyPrioritize),
+}
+
+var supportedInteractiveModes = []string{
+	configv1alpha1.ExecInteractiveModeNever,
+	configv1alpha1.ExecInteractiveModeIfAvailable,
+	configv1alpha1.ExecInteractiveModeAlways,
+}
+
+// ValidateControllerManagerConfiguration validates the given ControllerManagerConfiguration and returns all errors found.
+func ValidateControllerManagerConfiguration(cfg *configv1alpha1.ControllerManagerConfiguration) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, validateControllers(&cfg.Controllers, field.NewPath("controllers"))...)
+	allErrs = append(allErrs, validateWebhooks(&cfg.Webhooks, field.NewPath("webhooks"))...)
+	allErrs = append(allErrs, validateKubeconfig(&cfg.Kubeconfig, field.NewPath("kubeconfig"))...)
+	allErrs = append(allErrs, validateGardens(cfg.Gardens, field.NewPath("gardens"))...)
+
+	if cfg.Controllers.Garden.Enabled {
+		allErrs = append(allErrs, validateGardenKubeconfig(&cfg.Kubeconfig.Garden, field.NewPath("kubeconfig", "garden"))...)
+	}
+
+	return allErrs
+}
+
+// ValidateControllerManagerConfigurationUpdate returns an error for each field that differs between the new and the old configuration,
+// but cannot be changed at runtime.
+func ValidateControllerManagerConfigurationUpdate(newConfig, oldConfig *configv1alpha1.ControllerManagerConfiguration) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	immutable := func(newValue, oldValue interface{}, fldPath *field.Path) {
+		if !apiequality.Semantic.DeepEqual(newValue, oldValue) {
+			allErrs = append(allErrs, field.Forbidden(fldPath, fmt.Sprintf("cannot be changed at runtime from %v to %v, a restart is required", oldValue, newValue)))
+		}
+	}
+
+	controllersPath := field.NewPath("controllers")
+	newControllers, oldControllers := newConfig.Controllers, oldConfig.Controllers
+
+	immutable(newControllers.Shoot.MaxConcurrentReconciles, oldControllers.Shoot.MaxConcurrentReconciles, controllersPath.Child("shoot", "maxConcurrentReconciles"))
+	immutable(newControllers.Project.Enabled, oldControllers.Project.Enabled, controllersPath.Child("project", "enabled"))
+	immutable(newControllers.Project.MaxConcurrentReconciles, oldControllers.Project.MaxConcurrentReconciles, controllersPath.Child("project", "maxConcurrentReconciles"))
+	immutable(newControllers.KubeconfigBundle.Enabled, oldControllers.KubeconfigBundle.Enabled, controllersPath.Child("kubeconfigBundle", "enabled"))
+	immutable(newControllers.KubeconfigBundle.MaxConcurrentReconciles, oldControllers.KubeconfigBundle.MaxConcurrentReconciles, controllersPath.Child("kubeconfigBundle", "maxConcurrentReconciles"))
+	immutable(newControllers.Garden.Enabled, oldControllers.Garden.Enabled, controllersPath.Child("garden", "enabled"))
+	immutable(newControllers.Garden.MaxConcurrentReconciles, oldControllers.Garden.MaxConcurrentReconciles, controllersPath.Child("garden", "maxConcurrentReconciles"))
+
+	// the watches of the shoot controller depend on the ca sources
+	immutable(newConfig.Kubeconfig.CASources, oldConfig.Kubeconfig.CASources, field.NewPath("kubeconfig", "caSources"))
+	// a Shoot controller is started for each garden cluster
+	immutable(newConfig.Gardens, oldConfig.Gardens, field.NewPath("gardens"))
+
+	return allErrs
+}
+
+func validateControllers(controllers *configv1alpha1.ControllerManagerControllerConfiguration, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	shootPath := fldPath.Child("shoot")
+	shoot := controllers.Shoot
+
+	if shoot.MaxConcurrentReconciles < 1 {
+		allErrs = append(allErrs, field.Invalid(shootPath.Child("maxConcurrentReconciles"), shoot.MaxConcurrentReconciles, "must be 1 or greater"))
+	}
+
+	if shoot.MaxConcurrentReconcilesPerNamespace <= 0 {
+		allErrs = append(allErrs, field.Invalid(shootPath.Child("maxConcurrentReconcilesPerNamespace"), shoot.MaxConcurrentReconcilesPerNamespace, "must be greater than 0"))
+	} else if shoot.MaxConcurrentReconcilesPerNamespace > shoot.MaxConcurrentReconciles {
+		allErrs = append(allErrs, field.Invalid(shootPath.Child("maxConcurrentReconcilesPerNamespace"), shoot.MaxConcurrentReconcilesPerNamespace, "must not be greater than maxConcurrentReconciles"))
+	}
+
+	if shoot.QuotaExceededRetryDelay.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(shootPath.Child("quotaExceededRetryDelay"), shoot.QuotaExceededRetryDelay.Duration.String(), "must be greater than 0"))
+	}
+
+	switch shoot.QuotaExhaustedPolicy {
+	case configv1alpha1.QuotaExhaustedPolicyWait, configv1alpha1.QuotaExhaustedPolicyPrioritize:
+	default:
+		allErrs = append(allErrs, field.NotSupported(shootPath.Child("quotaExhaustedPolicy"), shoot.QuotaExhaustedPolicy, supportedQuotaExhaustedPolicies))
+	}
+
+	if project := controllers.Project; project.Enabled {
+		projectPath := fldPath.Child("project")
+
+		if project.MaxConcurrentReconciles < 1 {
+			allErrs = append(allErrs, field.Invalid(projectPath.Child("maxConcurrentReconciles"), project.MaxConcurrentReconciles, "must be 1 or greater"))
+		}
+
+		if project.QuotaExceededRetryDelay.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(projectPath.Child("quotaExceededRetryDelay"), project.QuotaExceededRetryDelay.Duration.String(), "must be greater than 0"))
+		}
+	}
+
+	if bundle := controllers.KubeconfigBundle; bundle.Enabled {
+		bundlePath := fldPath.Child("kubeconfigBundle")
+
+		if bundle.MaxConcurrentReconciles < 1 {
+			allErrs = append(allErrs, field.Invalid(bundlePath.Child("maxConcurrentReconciles"), bundle.MaxConcurrentReconciles, "must be 1 or greater"))
+		}
+
+		if bundle.AuthorizationRecheckInterval.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(bundlePath.Child("authorizationRecheckInterval"), bundle.AuthorizationRecheckInterval.Duration.String(), "must be greater than 0"))
+		}
+
+		if bundle.QuotaExceededRetryDelay.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(bundlePath.Child("quotaExceededRetryDelay"), bundle.QuotaExceededRetryDelay.Duration.String(), "must be greater than 0"))
+		}
+	}
+
+	if garden := controllers.Garden; garden.Enabled {
+		gardenPath := fldPath.Child("garden")
+
+		if garden.MaxConcurrentReconciles < 1 {
+			allErrs = append(allErrs, field.Invalid(gardenPath.Child("maxConcurrentReconciles"), garden.MaxConcurrentReconciles, "must be 1 or greater"))
+		}
+
+		if garden.QuotaExceededRetryDelay.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(gardenPath.Child("quotaExceededRetryDelay"), garden.QuotaExceededRetryDelay.Duration.String(), "must be greater than 0"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateWebhooks(webhooks *configv1alpha1.ControllerManagerWebhookConfiguration, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	configMapValidationPath := fldPath.Child("configMapValidation")
+
+	if webhooks.ConfigMapValidation.MaxObjectSize < 1 {
+		allErrs = append(allErrs, field.Invalid(configMapValidationPath.Child("maxObjectSize"), webhooks.ConfigMapValidation.MaxObjectSize, "must be 1 or greater"))
+	}
+
+	for i, user := range webhooks.ConfigMapValidation.DeletionAllowedUsers {
+		if user == "" {
+			allErrs = append(allErrs, field.Required(configMapValidationPath.Child("deletionAllowedUsers").Index(i), "name of user is required"))
+		}
+	}
+
+	for i, user := range webhooks.ConfigMapValidation.AccessReviewSkippedUsers {
+		if user == "" {
+			allErrs = append(allErrs, field.Required(configMapValidationPath.Child("accessReviewSkippedUsers").Index(i), "name of user is required"))
+		}
+	}
+
+	if cache := webhooks.ConfigMapValidation.AccessReviewCache; cache.Enabled {
+		cachePath := configMapValidationPath.Child("accessReviewCache")
+
+		if cache.AllowedTTL.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(cachePath.Child("allowedTTL"), cache.AllowedTTL.Duration.String(), "must be greater than 0"))
+		}
+
+		if cache.DeniedTTL.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(cachePath.Child("deniedTTL"), cache.DeniedTTL.Duration.String(), "must be greater than 0"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateKubeconfig(kubeconfig *configv1alpha1.KubeconfigConfiguration, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, format := range kubeconfig.Formats {
+		formatPath := fldPath.Child("formats").Index(i)
+
+		if _, err := semver.NewConstraint(format.KubernetesVersionConstraint); err != nil {
+			allErrs = append(allErrs, field.Invalid(formatPath.Child("kubernetesVersionConstraint"), format.KubernetesVersionConstraint, err.Error()))
+		}
+
+		allErrs = append(allErrs, validateExecAPIVersion(format.ExecAPIVersion, formatPath.Child("execAPIVersion"))...)
+	}
+
+	allErrs = append(allErrs, validateCASources(kubeconfig.CASources, fldPath.Child("caSources"))...)
+
+	execPath := fldPath.Child("exec")
+
+	allErrs = append(allErrs, validateInteractiveMode(kubeconfig.Exec.InteractiveMode, execPath.Child("interactiveMode"))...)
+	allErrs = append(allErrs, validateEnv(kubeconfig.Exec.Env, execPath.Child("env"))...)
+
+	return allErrs
+}
+
+func validateCASources(caSources []configv1alpha1.CASourceType, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if len(caSources) == 0 {
+		return append(allErrs, field.Required(fldPath, "at least one ca source is required"))
+	}
+
+	supported := make(map[string]bool)
+	for _, caSource := range supportedCASources {
+		supported[caSource] = true
+	}
+
+	seen := make(map[configv1alpha1.CASourceType]bool)
+
+	for i, caSource := range caSources {
+		switch {
+		case !supported[string(caSource)]:
+			allErrs = append(allErrs, field.NotSupported(fldPath.Index(i), caSource, supportedCASources))
+		case seen[caSource]:
+			allErrs = append(allErrs, field.Duplicate(fldPath.Index(i), caSource))
+		}
+
+		seen[caSource] = true
+	}
+
+	return allErrs
+}
+
+func validateGardens(gardens []configv1alpha1.GardenClusterConfiguration, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	seen := make(map[string]bool)
+
+	for i, garden := range gardens {
+		gardenPath := fldPath.Index(i)
+
+		switch {
+		case garden.Name == "":
+			allErrs = append(allErrs, field.Required(gardenPath.Child("name"), "name of garden cluster is required"))
+		case seen[garden.Name]:
+			allErrs = append(allErrs, field.Duplicate(gardenPath.Child("name"), garden.Name))
+		case garden.Name == "main":
+			// main identifies the garden cluster the manager runs in, e.g. in the controller name and the metrics
+			allErrs = append(allErrs, field.Invalid(gardenPath.Child("name"), garden.Name, "name is reserved for the garden cluster of the manager"))
+		default:
+			for _, msg := range validation.IsDNS1123Label(garden.Name) {
+				allErrs = append(allErrs, field.Invalid(gardenPath.Child("name"), garden.Name, msg))
+			}
+		}
+
+		seen[garden.Name] = true
+
+		if garden.Kubeconfig == "" {
+			allErrs = append(allErrs, field.Required(gardenPath.Child("kubeconfig"), "path to the kubeconfig of the garden cluster is required"))
+		}
+
+		for _, msg := range validation.IsDNS1123Label(garden.LeaderElectionNamespace) {
+			allErrs = append(allErrs, field.Invalid(gardenPath.Child("leaderElectionNamespace"), garden.LeaderElectionNamespace, msg))
+		}
+	}
+
+	return allErrs
+}
+
+func validateGardenKubeconfig(garden *configv1alpha1.GardenKubeconfigConfiguration, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if garden.Server == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("server"), "server is required"))
+	} else if u, err := url.Parse(garden.Server); err != nil || u.Scheme != "https" || u.Host == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("server"), garden.Server, "must be a https URL"))
+	}
+
+	if garden.CertificateAuthority != "" {
+		if err := validateCertificate([]byte(garden.CertificateAuthority)); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("certificateAuthority"), "<omitted>", err.Error()))
+		}
+	}
+
+	if (garden.OIDC == nil) == (garden.Exec == nil) {
+		allErrs = append(allErrs, field.Invalid(fldPath, "<omitted>", "exactly one of oidc or exec must be set"))
+	}
+
+	if oidc := garden.OIDC; oidc != nil {
+		oidcPath := fldPath.Child("oidc")
+
+		if oidc.IssuerURL == "" {
+			allErrs = append(allErrs, field.Required(oidcPath.Child("issuerURL"), "issuer URL is required"))
+		}
+
+		if oidc.ClientID == "" {
+			allErrs = append(allErrs, field.Required(oidcPath.Child("clientID"), "client ID is required"))
+		}
+	}
+
+	if exec := garden.Exec; exec != nil {
+		execPath := fldPath.Child("exec")
+
+		if exec.Command == "" {
+			allErrs = append(allErrs, field.Required(execPath.Child("command"), "command is required"))
+		}
+
+		allErrs = append(allErrs, validateExecAPIVersion(exec.APIVersion, execPath.Child("apiVersion"))...)
+		allErrs = append(allErrs, validateInteractiveMode(exec.InteractiveMode, execPath.Child("interactiveMode"))...)
+		allErrs = append(allErrs, validateEnv(exec.Env, execPath.Child("env"))...)
+	}
+
+	return allErrs
+}
+
+func validateExecAPIVersion(apiVersion string, fldPath *field.Path) field.ErrorList {
+	for _, supported := range supportedExecAPIVersions {
+		if apiVersion == supported {
+			return nil
+		}
+	}
+
+	return field.ErrorList{field.NotSupported(fldPath, apiVersion, supportedExecAPIVersions)}
+}
+
+func validateInteractiveMode(interactiveMode string, fldPath *field.Path) field.ErrorList {
+	if interactiveMode == "" {
+		return nil
+	}
+
+	for _, supported := range supportedInteractiveModes {
+		if interactiveMode == supported {
+			return nil
+		}
+	}
+
+	return field.ErrorList{field.NotSupported(fldPath, interactiveMode, supportedInteractiveModes)}
+}
+
+func validateEnv(env []configv1alpha1.ExecEnvVar, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, e := range env {
+		if e.Name == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Index(i).Child("name"), "name of environment variable is required"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateCertificate returns an error in case the given bytes do not contain a PEM encoded x509 certificate
+func validateCertificate(bytes []byte) error {
+	block, _ := pem.Decode(bytes)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return fmt.Errorf("PEM block type must be CERTIFICATE")
+	}
+
+	if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+		return fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package validation_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
+	. "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1/validation"
+)
+
+var _ = Describe("Validation", func() {
+	var cfg *configv1alpha1.ControllerManagerConfiguration
+
+	BeforeEach(func() {
+		cfg = &configv1alpha1.ControllerManagerConfiguration{}
+		configv1alpha1.SetDefaults_ControllerManagerConfiguration(cfg)
+	})
+
+	fields := func(errs field.ErrorList) []string {
+		var fields []string
+		for _, err := range errs {
+			fields = append(fields, err.Field)
+		}
+
+		return fields
+	}
+
+	Describe("#ValidateControllerManagerConfiguration", func() {
+		It("should accept the defaulted configuration", func() {
+			Expect(ValidateControllerManagerConfiguration(cfg)).To(BeEmpty())
+		})
+
+		It("should return all errors", func() {
+			cfg.Controllers.Shoot.MaxConcurrentReconciles = 0
+			cfg.Controllers.Shoot.QuotaExceededRetryDelay = metav1.Duration{Duration: -time.Second}
+			cfg.Controllers.Shoot.QuotaExhaustedPolicy = "Evict"
+			cfg.Kubeconfig.Exec.InteractiveMode = "Sometimes"
+			cfg.Kubeconfig.Exec.Env = []configv1alpha1.ExecEnvVar{{Value: "bar"}}
+
+			Expect(fields(ValidateControllerManagerConfiguration(cfg))).To(ConsistOf(
+				"controllers.shoot.maxConcurrentReconciles",
+				"controllers.shoot.maxConcurrentReconcilesPerNamespace",
+				"controllers.shoot.quotaExceededRetryDelay",
+				"controllers.shoot.quotaExhaustedPolicy",
+				"kubeconfig.exec.interactiveMode",
+				"kubeconfig.exec.env[0].name",
+			))
+		})
+
+		It("should reject a non-positive number of concurrent reconciles per namespace", func() {
+			cfg.Controllers.Shoot.MaxConcurrentReconcilesPerNamespace = -1
+
+			errs := ValidateControllerManagerConfiguration(cfg)
+			Expect(fields(errs)).To(ConsistOf("controllers.shoot.maxConcurrentReconcilesPerNamespace"))
+			Expect(errs[0].Detail).To(Equal("must be greater than 0"))
+		})
+
+		It("should validate the project controller only if it is enabled", func() {
+			cfg.Controllers.Project.QuotaExceededRetryDelay = metav1.Duration{Duration: -time.Second}
+
+			Expect(ValidateControllerManagerConfiguration(cfg)).To(BeEmpty())
+
+			cfg.Controllers.Project.Enabled = true
+
+			Expect(fields(ValidateControllerManagerConfiguration(cfg))).To(ConsistOf("controllers.project.quotaExceededRetryDelay"))
+		})
+
+		It("should validate the kubeconfig bundle controller only if it is enabled", func() {
+			cfg.Controllers.KubeconfigBundle.QuotaExceededRetryDelay = metav1.Duration{Duration: -time.Second}
+
+			Expect(ValidateControllerManagerConfiguration(cfg)).To(BeEmpty())
+
+			cfg.Controllers.KubeconfigBundle.Enabled = true
+
+			Expect(fields(ValidateControllerManagerConfiguration(cfg))).To(ConsistOf("controllers.kubeconfigBundle.quotaExceededRetryDelay"))
+		})
+
+		It("should validate the garden controller only if it is enabled", func() {
+			cfg.Controllers.Garden.QuotaExceededRetryDelay = metav1.Duration{Duration: -time.Second}
+			cfg.Kubeconfig.Garden.Server = "https://api.garden.example.com"
+			cfg.Kubeconfig.Garden.OIDC = &configv1alpha1.GardenOIDCConfiguration{IssuerURL: "https://issuer.example.com", ClientID: "gardener"}
+
+			Expect(ValidateControllerManagerConfiguration(cfg)).To(BeEmpty())
+
+			cfg.Controllers.Garden.Enabled = true
+
+			Expect(fields(ValidateControllerManagerConfiguration(cfg))).To(ConsistOf("controllers.garden.quotaExceededRetryDelay"))
+		})
+
+		It("should reject unsupported and duplicate ca sources", func() {
+			cfg.Kubeconfig.CASources = []configv1alpha1.CASourceType{
+				configv1alpha1.CASourceSecret,
+				"Vault",
+				configv1alpha1.CASourceSecret,
+			}
+
+			Expect(fields(ValidateControllerManagerConfiguration(cfg))).To(ConsistOf(
+				"kubeconfig.caSources[1]",
+				"kubeconfig.caSources[2]",
+			))
+		})
+
+		It("should reject invalid and duplicate garden clusters", func() {
+			cfg.Gardens = []configv1alpha1.GardenClusterConfiguration{
+				{Name: "dev", Kubeconfig: "/etc/gardens/dev/kubeconfig"},
+				{Name: "dev", Kubeconfig: "/etc/gardens/dev/kubeconfig"},
+				{Name: "Live"},
+				{Name: "main", Kubeconfig: "/etc/gardens/main/kubeconfig"},
+			}
+			configv1alpha1.SetDefaults_ControllerManagerConfiguration(cfg)
+
+			Expect(cfg.Gardens[0].LeaderElectionNamespace).To(Equal("kube-system"))
+			Expect(fields(ValidateControllerManagerConfiguration(cfg))).To(ConsistOf(
+				"gardens[1].name",
+				"gardens[2].name",
+				"gardens[2].kubeconfig",
+				"gardens[3].name",
+			))
+		})
+
+		It("should validate the garden kubeconfig only if the garden controller is enabled", func() {
+			Expect(ValidateControllerManagerConfiguration(cfg)).To(BeEmpty())
+
+			cfg.Controllers.Garden.Enabled = true
+
+			Expect(fields(ValidateControllerManagerConfiguration(cfg))).To(ConsistOf(
+				"kubeconfig.garden.server",
+				"kubeconfig.garden",
+			))
+		})
+	})
+
+	Describe("#ValidateControllerManagerConfigurationUpdate", func() {
+		It("should forbid changing the number of concurrent reconciles", func() {
+			newConfig := cfg.DeepCopy()
+			newConfig.Controllers.Project.MaxConcurrentReconciles = 10
+			newConfig.Controllers.Shoot.MaxConcurrentReconcilesPerNamespace = 5
+
+			Expect(fields(ValidateControllerManagerConfigurationUpdate(newConfig, cfg))).To(ConsistOf("controllers.project.maxConcurrentReconciles"))
+		})
+
+		It("should forbid changing the ca sources", func() {
+			newConfig := cfg.DeepCopy()
+			newConfig.Kubeconfig.CASources = append(newConfig.Kubeconfig.CASources, configv1alpha1.CASourceConfigMap)
+
+			Expect(fields(ValidateControllerManagerConfigurationUpdate(newConfig, cfg))).To(ConsistOf("kubeconfig.caSources"))
+		})
+
+		It("should forbid changing the garden clusters", func() {
+			newConfig := cfg.DeepCopy()
+			newConfig.Gardens = []configv1alpha1.GardenClusterConfiguration{{Name: "dev", Kubeconfig: "/etc/gardens/dev/kubeconfig"}}
+
+			Expect(fields(ValidateControllerManagerConfigurationUpdate(newConfig, cfg))).To(ConsistOf("gardens"))
+		})
+	})
+})
@@ -0,0 +1,88 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// KubeconfigBundleConditionReady is the condition type that indicates if the kubeconfig of the bundle is rendered
+	KubeconfigBundleConditionReady = "Ready"
+
+	// KubeconfigBundleReasonRendered is the reason of the Ready condition in case the kubeconfig was rendered successfully
+	KubeconfigBundleReasonRendered = "Rendered"
+	// KubeconfigBundleReasonForbidden is the reason of the Ready condition in case the requester is not allowed to access the shoots of a source namespace
+	KubeconfigBundleReasonForbidden = "Forbidden"
+	// KubeconfigBundleReasonRequesterUnknown is the reason of the Ready condition in case the requester of the bundle is not recorded
+	KubeconfigBundleReasonRequesterUnknown = "RequesterUnknown"
+	// KubeconfigBundleReasonReadersNotAuthorized is the reason of the Ready condition in case a reader of the configMaps of the bundle namespace is not allowed to read the configMaps of a source namespace
+	KubeconfigBundleReasonReadersNotAuthorized = "ReadersNotAuthorized"
+	// KubeconfigBundleReasonInvalidSelector is the reason of the Ready condition in case the shoot selector cannot be parsed
+	KubeconfigBundleReasonInvalidSelector = "InvalidSelector"
+	// KubeconfigBundleReasonQuotaExceeded is the reason of the Ready condition in case the configMap quota of the namespace is exceeded
+	KubeconfigBundleReasonQuotaExceeded = "QuotaExceeded"
+)
+
+// KubeconfigBundleSpec defines the shoots of which the kubeconfigs are bundled
+type KubeconfigBundleSpec struct {
+	// Namespaces are the project namespaces from which the shoots are selected
+	//+kubebuilder:validation:MinItems=1
+	Namespaces []string `json:"namespaces"`
+
+	// ShootSelector selects the shoots of the namespaces by label. All shoots are selected in case it is not set
+	// +optional
+	ShootSelector *metav1.LabelSelector `json:"shootSelector,omitempty"`
+}
+
+// KubeconfigBundleStatus defines the observed state of KubeconfigBundle
+type KubeconfigBundleStatus struct {
+	// ObservedGeneration is the most recent generation observed for this KubeconfigBundle
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ConfigMapName is the name of the configMap in the namespace of the KubeconfigBundle that holds the rendered kubeconfig
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// Shoots is the number of shoots that are contained in the rendered kubeconfig
+	// +optional
+	Shoots int `json:"shoots,omitempty"`
+
+	// Conditions represent the latest available observations of the KubeconfigBundle
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="ConfigMap",type=string,JSONPath=`.status.configMapName`
+//+kubebuilder:printcolumn:name="Shoots",type=integer,JSONPath=`.status.shoots`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// KubeconfigBundle bundles the kubeconfigs of the shoots of several project namespaces into one kubeconfig configMap
+type KubeconfigBundle struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KubeconfigBundleSpec   `json:"spec,omitempty"`
+	Status KubeconfigBundleStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// KubeconfigBundleList contains a list of KubeconfigBundle
+type KubeconfigBundleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KubeconfigBundle `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KubeconfigBundle{}, &KubeconfigBundleList{})
+}
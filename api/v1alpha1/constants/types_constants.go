@@ -14,4 +14,55 @@ const (
 
 	// DataKeyKubeconfig is the key in a configmap data holding the kubeconfig.
 	DataKeyKubeconfig = "kubeconfig"
+
+	// LabelKubeconfigScope is a label on kubeconfig configMaps that do not hold the kubeconfig of a single shoot, describing what the kubeconfig is for.
+	// The label is not set on the <shoot>.kubeconfig configMaps.
+	LabelKubeconfigScope = "gardenlogin.gardener.cloud/kubeconfig-scope"
+	// KubeconfigScopeProject is the value of the LabelKubeconfigScope key indicating a kubeconfig that contains all shoots of a project.
+	KubeconfigScopeProject = "project"
+	// KubeconfigScopeBundle is the value of the LabelKubeconfigScope key indicating a kubeconfig that contains the shoots selected by a KubeconfigBundle.
+	KubeconfigScopeBundle = "bundle"
+	// KubeconfigScopeGarden is the value of the LabelKubeconfigScope key indicating a kubeconfig for the garden cluster.
+	KubeconfigScopeGarden = "garden"
+
+	// KubeconfigConfigMapNameSuffix is the name suffix of the configMap holding the kubeconfig of a single shoot, which is named <shoot>.kubeconfig.
+	KubeconfigConfigMapNameSuffix = ".kubeconfig"
+	// ProjectKubeconfigConfigMapName is the name of the configMap holding the kubeconfig for all shoots of a project.
+	ProjectKubeconfigConfigMapName = "project.kubeconfig"
+	// GardenKubeconfigConfigMapName is the name of the configMap holding the kubeconfig for the garden cluster.
+	GardenKubeconfigConfigMapName = "garden.kubeconfig"
+	// BundleKubeconfigConfigMapNameSuffix is the name suffix of the configMap holding the kubeconfig of a KubeconfigBundle, which is named <bundle>.bundle.kubeconfig.
+	BundleKubeconfigConfigMapNameSuffix = ".bundle.kubeconfig"
+
+	// AnnotationLastReconcileOutcome is the annotation key on a Shoot holding the outcome (Succeeded, Skipped or Failed) of the last kubeconfig reconciliation.
+	AnnotationLastReconcileOutcome = "gardenlogin.gardener.cloud/last-reconcile-outcome"
+	// AnnotationLastReconcileReason is the annotation key on a Shoot holding the reason of the last kubeconfig reconciliation outcome.
+	AnnotationLastReconcileReason = "gardenlogin.gardener.cloud/last-reconcile-reason"
+
+	// AnnotationControllerVersion is the annotation key on a kubeconfig configMap holding the version of the gardenlogin-controller-manager that generated the kubeconfig.
+	// The provenance annotations are maintained by the configMap mutating webhook and cannot be set by users.
+	AnnotationControllerVersion = "gardenlogin.gardener.cloud/controller-version"
+	// AnnotationShootKubernetesVersion is the annotation key on a shoot kubeconfig configMap holding the kubernetes version of the shoot at the time the kubeconfig was generated.
+	AnnotationShootKubernetesVersion = "gardenlogin.gardener.cloud/shoot-kubernetes-version"
+	// AnnotationKubeconfigFormat is the annotation key on a shoot kubeconfig configMap holding the format (legacy or extension) of the kubeconfig.
+	AnnotationKubeconfigFormat = "gardenlogin.gardener.cloud/kubeconfig-format"
+	// AnnotationKubeconfigExecAPIVersion is the annotation key on a shoot kubeconfig configMap holding the API version of the exec section of the kubeconfig.
+	AnnotationKubeconfigExecAPIVersion = "gardenlogin.gardener.cloud/kubeconfig-exec-api-version"
+	// AnnotationCABundleSHA256 is the annotation key on a kubeconfig configMap holding the hex encoded SHA-256 of the certificate authorities of the kubeconfig.
+	AnnotationCABundleSHA256 = "gardenlogin.gardener.cloud/ca-bundle-sha256"
+	// AnnotationGeneratedAt is the annotation key on a kubeconfig configMap holding the RFC 3339 timestamp of the last change of the kubeconfig.
+	AnnotationGeneratedAt = "gardenlogin.gardener.cloud/generated-at"
+
+	// AnnotationCANotAfter is the annotation key on a shoot kubeconfig configMap holding the RFC 3339 timestamp at which the earliest certificate authority of the kubeconfig expires.
+	// It is maintained by the shoot controller.
+	AnnotationCANotAfter = "gardenlogin.gardener.cloud/ca-not-after"
+
+	// KubeconfigFormatLegacy is the value of the AnnotationKubeconfigFormat key for kubeconfigs passing the shoot reference as command line flags to the plugin.
+	KubeconfigFormatLegacy = "legacy"
+	// KubeconfigFormatExtension is the value of the AnnotationKubeconfigFormat key for kubeconfigs passing the shoot reference via the cluster extensions.
+	KubeconfigFormatExtension = "extension"
+
+	// AnnotationRequestedBy is the annotation key on a KubeconfigBundle holding the JSON encoded user info of the user that created or last changed the spec of the bundle.
+	// The annotation is maintained by the KubeconfigBundle mutating webhook and cannot be set by users.
+	AnnotationRequestedBy = "gardenlogin.gardener.cloud/requested-by"
 )
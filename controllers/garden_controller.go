@@ -0,0 +1,276 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientauthenticationv1 "k8s.io/client-go/pkg/apis/clientauthentication/v1"
+	clientauthenticationv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
+	clientcmdlatest "k8s.io/client-go/tools/clientcmd/api/latest"
+	clientcmdv1 "k8s.io/client-go/tools/clientcmd/api/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
+	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1/constants"
+	"github.com/gardener/gardenlogin-controller-manager/internal/util"
+)
+
+// GardenReconciler maintains a garden.kubeconfig configMap in each project namespace, which contains a kubeconfig for the garden cluster
+// with the project namespace as default namespace.
+// The request name is the name of the project namespace.
+type GardenReconciler struct {
+	Scheme *runtime.Scheme
+	client.Client
+	Log         logr.Logger
+	Config      *configv1alpha1.ControllerManagerConfiguration
+	configMutex sync.RWMutex
+	// resync reconciles all project namespaces once the kubeconfig section of the configuration changed
+	resync *util.Resync
+}
+
+// Reconcile renders the garden.kubeconfig configMap of the namespace
+func (r *GardenReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("namespace", req.Name)
+
+	gardenConfigMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: constants.GardenKubeconfigConfigMapName, Namespace: req.Name}}
+
+	namespace := &corev1.Namespace{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: req.Name}, namespace); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if namespace.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	if !isProjectNamespace(namespace) {
+		// the configMap watch enqueues all namespaces, garden kubeconfigs are only maintained in project namespaces
+		return ctrl.Result{}, nil
+	}
+
+	existing := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(gardenConfigMap), existing); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+
+		if sufficient, err := hasSufficientConfigMapQuota(ctx, r.Client, req.Name); errors.Is(err, util.ErrQuotaStatusOutdated) {
+			log.Info("configMap quota status is not up-to-date, will try again shortly", "reason", err.Error())
+			return ctrl.Result{Requeue: true}, nil
+		} else if err != nil {
+			return ctrl.Result{}, err
+		} else if !sufficient {
+			log.Info("configMap quota is not sufficient, will try again later")
+			return ctrl.Result{RequeueAfter: r.getConfig().Controllers.Garden.QuotaExceededRetryDelay.Duration}, nil
+		}
+	} else if existing.Labels[constants.LabelKubeconfigScope] != constants.KubeconfigScopeGarden {
+		log.Info("configMap is not managed by the garden controller - skipping", "configMap", constants.GardenKubeconfigConfigMapName)
+		return ctrl.Result{}, nil
+	}
+
+	kubeconfig, err := generateGardenKubeconfig(&r.getConfig().Kubeconfig.Garden, req.Name)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to generate garden kubeconfig: %w", err)
+	}
+
+	if _, err = ctrl.CreateOrUpdate(ctx, r.Client, gardenConfigMap, func() error {
+		if gardenConfigMap.Labels == nil {
+			gardenConfigMap.Labels = make(map[string]string)
+		}
+		gardenConfigMap.Labels[constants.GardenerOperationsRole] = constants.GardenerOperationsKubeconfig
+		gardenConfigMap.Labels[constants.LabelKubeconfigScope] = constants.KubeconfigScopeGarden
+
+		if gardenConfigMap.Data == nil {
+			gardenConfigMap.Data = make(map[string]string)
+		}
+		gardenConfigMap.Data[constants.DataKeyKubeconfig] = string(kubeconfig)
+		return nil
+	}); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to create or update garden kubeconfig configMap %s/%s: %w", gardenConfigMap.Namespace, gardenConfigMap.Name, err)
+	}
+
+	log.Info("reconciled successfully")
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GardenReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, config configv1alpha1.GardenControllerConfiguration) error {
+	r.setResync(util.NewResync())
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Namespace{}, builder.WithPredicates(projectNamespacePredicate())).
+		// the garden kubeconfig is rendered from the kubeconfig section of the configuration, hence all project namespaces are reconciled in case it changes
+		Watches(r.resync.Source(), handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
+			return r.allProjectNamespaceRequests(ctx)
+		})).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
+				return []reconcile.Request{
+					{
+						NamespacedName: types.NamespacedName{
+							Name: o.GetNamespace(),
+						},
+					},
+				}
+			}),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+				// restore the garden kubeconfig in case it is changed or deleted
+				return o.GetName() == constants.GardenKubeconfigConfigMapName
+			}))).
+		Named("garden").
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: config.MaxConcurrentReconciles,
+		}).
+		Complete(r)
+}
+
+// allProjectNamespaceRequests returns the reconcile requests of all project namespaces
+func (r *GardenReconciler) allProjectNamespaceRequests(ctx context.Context) []reconcile.Request {
+	namespaceList := &corev1.NamespaceList{}
+	if err := r.Client.List(ctx, namespaceList); err != nil {
+		r.Log.Info("failed to list project namespaces", "error", err.Error())
+		return []reconcile.Request{}
+	}
+
+	var reconcileRequests []reconcile.Request
+	for i := range namespaceList.Items {
+		namespace := &namespaceList.Items[i]
+		if !isProjectNamespace(namespace) {
+			continue
+		}
+
+		reconcileRequests = append(reconcileRequests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name: namespace.GetName(),
+			},
+		})
+	}
+
+	return reconcileRequests
+}
+
+// generateGardenKubeconfig renders the kubeconfig for the garden cluster with the given namespace as default namespace
+func generateGardenKubeconfig(garden *configv1alpha1.GardenKubeconfigConfiguration, namespace string) ([]byte, error) {
+	contextName := fmt.Sprintf("%s--%s", garden.Name, namespace)
+
+	var exec *clientcmdv1.ExecConfig
+
+	switch {
+	case garden.OIDC != nil:
+		exec = &clientcmdv1.ExecConfig{
+			APIVersion:  clientauthenticationv1beta1.SchemeGroupVersion.String(),
+			Command:     "kubectl",
+			Args:        garden.OIDC.Args(),
+			InstallHint: "The kubelogin plugin is required, see https://github.com/int128/kubelogin",
+		}
+	case garden.Exec != nil:
+		var env []clientcmdv1.ExecEnvVar
+		for _, e := range garden.Exec.Env {
+			env = append(env, clientcmdv1.ExecEnvVar{
+				Name:  e.Name,
+				Value: e.Value,
+			})
+		}
+
+		interactiveMode := garden.Exec.InteractiveMode
+		if interactiveMode == "" && garden.Exec.APIVersion == clientauthenticationv1.SchemeGroupVersion.String() {
+			// the interactive mode is required for the client.authentication.k8s.io/v1 API
+			interactiveMode = configv1alpha1.ExecInteractiveModeIfAvailable
+		}
+
+		exec = &clientcmdv1.ExecConfig{
+			APIVersion:      garden.Exec.APIVersion,
+			Command:         garden.Exec.Command,
+			Args:            garden.Exec.Args,
+			Env:             env,
+			InstallHint:     garden.Exec.InstallHint,
+			InteractiveMode: clientcmdv1.ExecInteractiveMode(interactiveMode),
+		}
+	default:
+		return nil, fmt.Errorf("neither oidc nor exec is configured for the garden kubeconfig")
+	}
+
+	config := &clientcmdv1.Config{
+		CurrentContext: contextName,
+		Clusters: []clientcmdv1.NamedCluster{
+			{
+				Name: garden.Name,
+				Cluster: clientcmdv1.Cluster{
+					Server:                   garden.Server,
+					CertificateAuthorityData: []byte(garden.CertificateAuthority),
+				},
+			},
+		},
+		Contexts: []clientcmdv1.NamedContext{
+			{
+				Name: contextName,
+				Context: clientcmdv1.Context{
+					Cluster:   garden.Name,
+					AuthInfo:  garden.Name,
+					Namespace: namespace,
+				},
+			},
+		},
+		AuthInfos: []clientcmdv1.NamedAuthInfo{
+			{
+				Name: garden.Name,
+				AuthInfo: clientcmdv1.AuthInfo{
+					Exec: exec,
+				},
+			},
+		},
+	}
+
+	return runtime.Encode(clientcmdlatest.Codec, config)
+}
+
+// getConfig returns the configv1alpha1.ControllerManagerConfiguration of the GardenReconciler
+func (r *GardenReconciler) getConfig() *configv1alpha1.ControllerManagerConfiguration {
+	r.configMutex.RLock()
+	defer r.configMutex.RUnlock()
+
+	return r.Config
+}
+
+// setResync sets the Resync that is triggered when the kubeconfig section of the configuration changed
+func (r *GardenReconciler) setResync(resync *util.Resync) {
+	r.configMutex.Lock()
+	defer r.configMutex.Unlock()
+
+	r.resync = resync
+}
+
+// InjectConfig replaces the configv1alpha1.ControllerManagerConfiguration of the GardenReconciler, e.g. when the configuration file changed.
+// All project namespaces are reconciled in case the kubeconfig section changed, so that the garden kubeconfigs are rendered with the new configuration.
+func (r *GardenReconciler) InjectConfig(config *configv1alpha1.ControllerManagerConfiguration) {
+	r.configMutex.Lock()
+	defer r.configMutex.Unlock()
+
+	if r.resync != nil && util.KubeconfigChanged(r.Config, config) {
+		r.resync.Trigger()
+	}
+
+	r.Config = config
+}
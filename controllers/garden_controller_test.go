@@ -0,0 +1,201 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+
+	corev1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
+	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1/constants"
+	"github.com/gardener/gardenlogin-controller-manager/internal/test"
+)
+
+var _ = Describe("GardenController", func() {
+	Describe("#generateGardenKubeconfig", func() {
+		var garden *configv1alpha1.GardenKubeconfigConfiguration
+
+		BeforeEach(func() {
+			garden = &configv1alpha1.GardenKubeconfigConfiguration{
+				Name:   "garden",
+				Server: "https://api.garden.example.com",
+			}
+		})
+
+		It("should render a kubeconfig with the kubelogin credential plugin", func() {
+			garden.OIDC = &configv1alpha1.GardenOIDCConfiguration{
+				IssuerURL:   "https://issuer.example.com",
+				ClientID:    "gardener",
+				ExtraScopes: []string{"email", "groups"},
+				UsePKCE:     true,
+			}
+
+			kubeconfig, err := generateGardenKubeconfig(garden, "garden-foo")
+			Expect(err).ToNot(HaveOccurred())
+
+			config, err := clientcmd.Load(kubeconfig)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(config.CurrentContext).To(Equal("garden--garden-foo"))
+			Expect(config.Contexts["garden--garden-foo"].Namespace).To(Equal("garden-foo"))
+			Expect(config.Clusters["garden"].Server).To(Equal("https://api.garden.example.com"))
+			Expect(config.AuthInfos["garden"].Exec.Command).To(Equal("kubectl"))
+			Expect(config.AuthInfos["garden"].Exec.Args).To(Equal([]string{
+				"oidc-login",
+				"get-token",
+				"--oidc-issuer-url=https://issuer.example.com",
+				"--oidc-client-id=gardener",
+				"--oidc-extra-scope=email",
+				"--oidc-extra-scope=groups",
+				"--oidc-use-pkce",
+			}))
+		})
+
+		It("should render a kubeconfig with the configured credential plugin", func() {
+			garden.Exec = &configv1alpha1.GardenExecConfiguration{
+				APIVersion: "client.authentication.k8s.io/v1",
+				Command:    "gardenctl",
+				Args:       []string{"token"},
+				Env:        []configv1alpha1.ExecEnvVar{{Name: "FOO", Value: "bar"}},
+			}
+
+			kubeconfig, err := generateGardenKubeconfig(garden, "garden-foo")
+			Expect(err).ToNot(HaveOccurred())
+
+			config, err := clientcmd.Load(kubeconfig)
+			Expect(err).ToNot(HaveOccurred())
+
+			exec := config.AuthInfos["garden"].Exec
+			Expect(exec.APIVersion).To(Equal("client.authentication.k8s.io/v1"))
+			Expect(exec.Command).To(Equal("gardenctl"))
+			Expect(exec.Args).To(Equal([]string{"token"}))
+			Expect(exec.Env).To(ConsistOf(clientcmdapi.ExecEnvVar{Name: "FOO", Value: "bar"}))
+			Expect(exec.InteractiveMode).To(Equal(clientcmdapi.IfAvailableExecInteractiveMode))
+		})
+
+		It("should fail in case no authentication is configured", func() {
+			_, err := generateGardenKubeconfig(garden, "garden-foo")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("#Reconcile", func() {
+		var (
+			ctx       context.Context
+			c         client.Client
+			namespace *corev1.Namespace
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "garden-foo"}}
+		})
+
+		reconcile := func() {
+			c = fakeclient.NewClientBuilder().WithObjects(namespace).Build()
+			reconciler := &GardenReconciler{Client: c, Log: logr.Discard(), Config: test.DefaultConfiguration()}
+
+			_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: namespace.Name}})
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		gardenKubeconfigKey := func() client.ObjectKey {
+			return client.ObjectKey{Namespace: namespace.Name, Name: constants.GardenKubeconfigConfigMapName}
+		}
+
+		It("should not render a garden kubeconfig in namespaces that do not belong to a project", func() {
+			reconcile()
+
+			err := c.Get(ctx, gardenKubeconfigKey(), &corev1.ConfigMap{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		DescribeTable("should render the garden kubeconfig in project namespaces",
+			func(labels map[string]string) {
+				namespace.Labels = labels
+
+				reconcile()
+
+				Expect(c.Get(ctx, gardenKubeconfigKey(), &corev1.ConfigMap{})).To(Succeed())
+			},
+			Entry("project role", map[string]string{"gardener.cloud/role": "project"}),
+			Entry("project name label", map[string]string{"project.gardener.cloud/name": "foo"}),
+		)
+	})
+
+	Describe("#GardenKubeconfigCleanup", func() {
+		It("should only delete the garden kubeconfig configMaps", func() {
+			configMap := func(namespace, name, scope string) *corev1.ConfigMap {
+				c := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+					Labels:    map[string]string{constants.GardenerOperationsRole: constants.GardenerOperationsKubeconfig},
+				}}
+
+				if scope != "" {
+					c.Labels[constants.LabelKubeconfigScope] = scope
+				}
+
+				return c
+			}
+
+			garden := configMap("garden-foo", constants.GardenKubeconfigConfigMapName, constants.KubeconfigScopeGarden)
+			otherGarden := configMap("garden-bar", constants.GardenKubeconfigConfigMapName, constants.KubeconfigScopeGarden)
+			shoot := configMap("garden-foo", "bar.kubeconfig", "")
+			project := configMap("garden-foo", constants.ProjectKubeconfigConfigMapName, constants.KubeconfigScopeProject)
+
+			c := fakeclient.NewClientBuilder().WithObjects(garden, otherGarden, shoot, project).Build()
+			cleanup := &GardenKubeconfigCleanup{Client: c, Log: logr.Discard()}
+
+			Expect(cleanup.Start(context.Background())).To(Succeed())
+
+			configMaps := &corev1.ConfigMapList{}
+			Expect(c.List(context.Background(), configMaps)).To(Succeed())
+			Expect(configMaps.Items).To(ConsistOf(
+				HaveField("Name", "bar.kubeconfig"),
+				HaveField("Name", constants.ProjectKubeconfigConfigMapName),
+			))
+		})
+	})
+
+	Describe("#allProjectNamespaceRequests", func() {
+		It("should return the requests of all project namespaces", func() {
+			namespace := func(name string, labels map[string]string) *corev1.Namespace {
+				return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+			}
+
+			r := &GardenReconciler{
+				Client: fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+					namespace("garden-foo", map[string]string{corev1beta1constants.GardenRole: corev1beta1constants.GardenRoleProject}),
+					namespace("garden-bar", map[string]string{corev1beta1constants.ProjectName: "bar"}),
+					namespace("kube-system", nil),
+				).Build(),
+				Log: logr.Discard(),
+			}
+
+			Expect(r.allProjectNamespaceRequests(context.Background())).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "garden-foo"}},
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "garden-bar"}},
+			))
+		})
+	})
+})
@@ -0,0 +1,90 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1/constants"
+)
+
+// kubeconfigCleanupRetryDelay is the duration after which a failed cleanup of the kubeconfig configMaps of a disabled controller is retried
+const kubeconfigCleanupRetryDelay = time.Minute
+
+// ProjectKubeconfigCleanup deletes the project.kubeconfig configMaps maintained by the ProjectReconciler. It is started instead of the
+// ProjectReconciler in case the Project controller is disabled, so that no stale project kubeconfigs are left behind.
+type ProjectKubeconfigCleanup struct {
+	client.Client
+	Log logr.Logger
+}
+
+// Start deletes the project kubeconfig configMaps of all namespaces. Failed attempts are retried until the context is done.
+func (c *ProjectKubeconfigCleanup) Start(ctx context.Context) error {
+	return cleanupKubeconfigs(ctx, c.Client, c.Log, constants.ProjectKubeconfigConfigMapName, constants.KubeconfigScopeProject)
+}
+
+// GardenKubeconfigCleanup deletes the garden.kubeconfig configMaps maintained by the GardenReconciler. It is started instead of the
+// GardenReconciler in case the Garden controller is disabled, so that no stale garden kubeconfigs are left behind.
+type GardenKubeconfigCleanup struct {
+	client.Client
+	Log logr.Logger
+}
+
+// Start deletes the garden kubeconfig configMaps of all namespaces. Failed attempts are retried until the context is done.
+func (c *GardenKubeconfigCleanup) Start(ctx context.Context) error {
+	return cleanupKubeconfigs(ctx, c.Client, c.Log, constants.GardenKubeconfigConfigMapName, constants.KubeconfigScopeGarden)
+}
+
+// cleanupKubeconfigs deletes the kubeconfig configMaps with the given name and scope of all namespaces. Failed attempts are retried until the context is done.
+func cleanupKubeconfigs(ctx context.Context, c client.Client, log logr.Logger, name string, scope string) error {
+	for {
+		err := deleteKubeconfigs(ctx, c, log, name, scope)
+		if err == nil {
+			return nil
+		}
+
+		log.Error(err, "failed to delete kubeconfig configMaps, will try again", "configMap", name, "retryDelay", kubeconfigCleanupRetryDelay)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(kubeconfigCleanupRetryDelay):
+		}
+	}
+}
+
+// deleteKubeconfigs deletes the kubeconfig configMaps with the given name and scope of all namespaces
+func deleteKubeconfigs(ctx context.Context, c client.Client, log logr.Logger, name string, scope string) error {
+	configMaps := &corev1.ConfigMapList{}
+	if err := c.List(ctx, configMaps, client.MatchingLabels{
+		constants.GardenerOperationsRole: constants.GardenerOperationsKubeconfig,
+		constants.LabelKubeconfigScope:   scope,
+	}); err != nil {
+		return fmt.Errorf("failed to list %s kubeconfig configMaps: %w", scope, err)
+	}
+
+	for i := range configMaps.Items {
+		configMap := &configMaps.Items[i]
+		if configMap.Name != name {
+			continue
+		}
+
+		if err := c.Delete(ctx, configMap); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete %s kubeconfig configMap %s/%s: %w", scope, configMap.Namespace, configMap.Name, err)
+		}
+
+		log.Info("deleted kubeconfig configMap of disabled controller", "namespace", configMap.Namespace, "configMap", configMap.Name)
+	}
+
+	return nil
+}
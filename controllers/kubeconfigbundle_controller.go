@@ -0,0 +1,315 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/go-logr/logr"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
+	gardenloginv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/gardenlogin/v1alpha1"
+	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1/constants"
+	"github.com/gardener/gardenlogin-controller-manager/internal/util"
+)
+
+// BundleKubeconfigConfigMapNameSuffix is the name suffix for the configMap that holds the kubeconfig of a KubeconfigBundle.
+// As shoot names must not contain dots, the name does not clash with the <shoot>.kubeconfig configMaps
+const BundleKubeconfigConfigMapNameSuffix = constants.BundleKubeconfigConfigMapNameSuffix
+
+// KubeconfigBundleReconciler reconciles a KubeconfigBundle object.
+// It merges the kubeconfigs of the shoots that are selected by the bundle, as rendered by the ShootReconciler, into the <bundle>.bundle.kubeconfig configMap in the namespace of the bundle.
+// The kubeconfig is only rendered in case the user that requested the bundle is allowed to list the shoots of all source namespaces
+// and all readers of the configMaps of the bundle namespace are allowed to read the configMaps of all source namespaces.
+type KubeconfigBundleReconciler struct {
+	Scheme *runtime.Scheme
+	client.Client
+	Log         logr.Logger
+	Config      *configv1alpha1.ControllerManagerConfiguration
+	configMutex sync.RWMutex
+}
+
+//+kubebuilder:rbac:groups=gardenlogin.gardener.cloud,resources=kubeconfigbundles,verbs=get;list;watch
+//+kubebuilder:rbac:groups=gardenlogin.gardener.cloud,resources=kubeconfigbundles/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=gardenlogin.gardener.cloud,resources=kubeconfigbundles/finalizers,verbs=update
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch
+
+// Reconcile renders the kubeconfig of the KubeconfigBundle
+func (r *KubeconfigBundleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("kubeconfigbundle", req.NamespacedName)
+
+	bundle := &gardenloginv1alpha1.KubeconfigBundle{}
+	if err := r.Client.Get(ctx, req.NamespacedName, bundle); err != nil {
+		// the configMap is cleaned up by the garbage collector as it is owned by the bundle
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if bundle.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	recheckInterval := r.getConfig().Controllers.KubeconfigBundle.AuthorizationRecheckInterval.Duration
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: bundle.Name + BundleKubeconfigConfigMapNameSuffix, Namespace: bundle.Namespace}}
+
+	userInfo, err := requesterOf(bundle)
+	if err != nil {
+		log.Info("requester of bundle is unknown", "reason", err.Error())
+
+		return ctrl.Result{}, r.revoke(ctx, bundle, configMap, gardenloginv1alpha1.KubeconfigBundleReasonRequesterUnknown, err.Error())
+	}
+
+	for _, namespace := range bundle.Spec.Namespaces {
+		allowed, err := util.CanListShoots(ctx, r.Client, *userInfo, namespace)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to review access of %s to namespace %s: %w", userInfo.Username, namespace, err)
+		}
+
+		if !allowed {
+			message := fmt.Sprintf("user %s is not allowed to list shoots in namespace %s", userInfo.Username, namespace)
+			log.Info("not rendering kubeconfig", "reason", message)
+
+			// the permissions could be granted later on
+			return ctrl.Result{RequeueAfter: recheckInterval}, r.revoke(ctx, bundle, configMap, gardenloginv1alpha1.KubeconfigBundleReasonForbidden, message)
+		}
+
+		reader, err := util.UnauthorizedConfigMapReader(ctx, r.Client, bundle.Namespace, namespace)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to review access of the readers of namespace %s to namespace %s: %w", bundle.Namespace, namespace, err)
+		}
+
+		if reader != "" {
+			message := fmt.Sprintf("%s can read configmaps in namespace %s but not in namespace %s", reader, bundle.Namespace, namespace)
+			log.Info("not rendering kubeconfig", "reason", message)
+
+			// the readers of the bundle namespace could change later on
+			return ctrl.Result{RequeueAfter: recheckInterval}, r.revoke(ctx, bundle, configMap, gardenloginv1alpha1.KubeconfigBundleReasonReadersNotAuthorized, message)
+		}
+	}
+
+	selector := labels.Everything()
+	if bundle.Spec.ShootSelector != nil {
+		if selector, err = metav1.LabelSelectorAsSelector(bundle.Spec.ShootSelector); err != nil {
+			return ctrl.Result{}, r.revoke(ctx, bundle, configMap, gardenloginv1alpha1.KubeconfigBundleReasonInvalidSelector, err.Error())
+		}
+	}
+
+	var kubeconfigs [][]byte
+
+	for _, namespace := range bundle.Spec.Namespaces {
+		shoots := util.NewShootList()
+		if err := r.Client.List(ctx, shoots, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		for _, shoot := range shoots.Items {
+			shootConfigMap := &corev1.ConfigMap{}
+			if err := r.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: shoot.GetName() + KubeconfigConfigMapNameSuffix}, shootConfigMap); err != nil {
+				if apierrors.IsNotFound(err) {
+					// not yet rendered by the shoot controller, the bundle is reconciled again once the configMap is created
+					continue
+				}
+
+				return ctrl.Result{}, err
+			}
+
+			if !isShootKubeconfigConfigMap(shootConfigMap) {
+				continue
+			}
+
+			kubeconfigs = append(kubeconfigs, []byte(shootConfigMap.Data[constants.DataKeyKubeconfig]))
+		}
+	}
+
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(configMap), &corev1.ConfigMap{}); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+
+		if sufficient, err := hasSufficientConfigMapQuota(ctx, r.Client, bundle.Namespace); errors.Is(err, util.ErrQuotaStatusOutdated) {
+			log.Info("configMap quota status is not up-to-date, will try again shortly", "reason", err.Error())
+			return ctrl.Result{Requeue: true}, nil
+		} else if err != nil {
+			return ctrl.Result{}, err
+		} else if !sufficient {
+			log.Info("configMap quota is not sufficient, will try again later")
+
+			retryDelay := r.getConfig().Controllers.KubeconfigBundle.QuotaExceededRetryDelay.Duration
+			if err := r.updateStatus(ctx, bundle, "", 0, metav1.ConditionFalse, gardenloginv1alpha1.KubeconfigBundleReasonQuotaExceeded, "configMap quota of the namespace is exceeded"); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			return ctrl.Result{RequeueAfter: retryDelay}, nil
+		}
+	}
+
+	kubeconfig, err := mergeKubeconfigs(kubeconfigs...)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to merge kubeconfigs: %w", err)
+	}
+
+	if _, err = ctrl.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		if configMap.Labels == nil {
+			configMap.Labels = make(map[string]string)
+		}
+		configMap.Labels[constants.GardenerOperationsRole] = constants.GardenerOperationsKubeconfig
+		configMap.Labels[constants.LabelKubeconfigScope] = constants.KubeconfigScopeBundle
+
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		configMap.Data[constants.DataKeyKubeconfig] = string(kubeconfig)
+
+		return controllerutil.SetControllerReference(bundle, configMap, r.Scheme)
+	}); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to create or update bundle kubeconfig configMap %s/%s: %w", configMap.Namespace, configMap.Name, err)
+	}
+
+	message := fmt.Sprintf("kubeconfig with %d shoots rendered", len(kubeconfigs))
+	if err := r.updateStatus(ctx, bundle, configMap.Name, len(kubeconfigs), metav1.ConditionTrue, gardenloginv1alpha1.KubeconfigBundleReasonRendered, message); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	log.Info("reconciled successfully", "shoots", len(kubeconfigs))
+
+	// verify the authorization of the requester again, as the permissions could be revoked in the meantime
+	return ctrl.Result{RequeueAfter: recheckInterval}, nil
+}
+
+// revoke deletes the kubeconfig configMap of the bundle and reports the reason in the status of the bundle
+func (r *KubeconfigBundleReconciler) revoke(ctx context.Context, bundle *gardenloginv1alpha1.KubeconfigBundle, configMap *corev1.ConfigMap, reason string, message string) error {
+	if err := r.Client.Delete(ctx, configMap); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete bundle kubeconfig configMap %s/%s: %w", configMap.Namespace, configMap.Name, err)
+	}
+
+	return r.updateStatus(ctx, bundle, "", 0, metav1.ConditionFalse, reason, message)
+}
+
+// updateStatus patches the status of the bundle in case it changed
+func (r *KubeconfigBundleReconciler) updateStatus(ctx context.Context, bundle *gardenloginv1alpha1.KubeconfigBundle, configMapName string, shoots int, status metav1.ConditionStatus, reason string, message string) error {
+	original := bundle.DeepCopy()
+
+	bundle.Status.ObservedGeneration = bundle.Generation
+	bundle.Status.ConfigMapName = configMapName
+	bundle.Status.Shoots = shoots
+	meta.SetStatusCondition(&bundle.Status.Conditions, metav1.Condition{
+		Type:               gardenloginv1alpha1.KubeconfigBundleConditionReady,
+		Status:             status,
+		ObservedGeneration: bundle.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+
+	if apiequality.Semantic.DeepEqual(original.Status, bundle.Status) {
+		return nil
+	}
+
+	return r.Client.Status().Patch(ctx, bundle, client.MergeFrom(original))
+}
+
+// requesterOf returns the user info of the user that requested the bundle, as recorded by the KubeconfigBundle mutating webhook
+func requesterOf(bundle *gardenloginv1alpha1.KubeconfigBundle) (*authenticationv1.UserInfo, error) {
+	requestedBy, ok := bundle.Annotations[constants.AnnotationRequestedBy]
+	if !ok {
+		return nil, fmt.Errorf("annotation %s is not set", constants.AnnotationRequestedBy)
+	}
+
+	userInfo := &authenticationv1.UserInfo{}
+	if err := json.Unmarshal([]byte(requestedBy), userInfo); err != nil {
+		return nil, fmt.Errorf("could not unmarshal annotation %s: %w", constants.AnnotationRequestedBy, err)
+	}
+
+	if userInfo.Username == "" {
+		return nil, fmt.Errorf("annotation %s does not contain a username", constants.AnnotationRequestedBy)
+	}
+
+	return userInfo, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *KubeconfigBundleReconciler) SetupWithManager(mgr ctrl.Manager, config configv1alpha1.KubeconfigBundleControllerConfiguration) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&gardenloginv1alpha1.KubeconfigBundle{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		Owns(&corev1.ConfigMap{}).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(r.bundlesForNamespaceOf),
+			builder.WithPredicates(kubeconfigConfigMapPredicate(), predicate.NewPredicateFuncs(func(o client.Object) bool {
+				// only the kubeconfigs of single shoots are bundled
+				_, ok := o.GetLabels()[constants.LabelKubeconfigScope]
+				return !ok
+			}))).
+		Watches(&source.Kind{Type: util.NewShoot()},
+			handler.EnqueueRequestsFromMapFunc(r.bundlesForNamespaceOf),
+			builder.WithPredicates(predicate.LabelChangedPredicate{})).
+		Named("kubeconfigbundle").
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: config.MaxConcurrentReconciles,
+		}).
+		Complete(r)
+}
+
+// bundlesForNamespaceOf returns a reconcile request for each KubeconfigBundle that has the namespace of the given object as source namespace
+func (r *KubeconfigBundleReconciler) bundlesForNamespaceOf(o client.Object) []reconcile.Request {
+	ctx := context.Background()
+
+	bundles := &gardenloginv1alpha1.KubeconfigBundleList{}
+	if err := r.Client.List(ctx, bundles); err != nil {
+		r.Log.Error(err, "failed to list kubeconfig bundles")
+		return nil
+	}
+
+	var requests []reconcile.Request
+
+	for _, bundle := range bundles.Items {
+		for _, namespace := range bundle.Spec.Namespaces {
+			if namespace == o.GetNamespace() {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&bundle)})
+				break
+			}
+		}
+	}
+
+	return requests
+}
+
+// getConfig returns the configv1alpha1.ControllerManagerConfiguration of the KubeconfigBundleReconciler
+func (r *KubeconfigBundleReconciler) getConfig() *configv1alpha1.ControllerManagerConfiguration {
+	r.configMutex.RLock()
+	defer r.configMutex.RUnlock()
+
+	return r.Config
+}
+
+// InjectConfig replaces the configv1alpha1.ControllerManagerConfiguration of the KubeconfigBundleReconciler, e.g. when the configuration file changed
+func (r *KubeconfigBundleReconciler) InjectConfig(config *configv1alpha1.ControllerManagerConfiguration) {
+	r.configMutex.Lock()
+	defer r.configMutex.Unlock()
+
+	r.Config = config
+}
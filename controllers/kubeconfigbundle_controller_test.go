@@ -0,0 +1,201 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
+	gardenloginv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/gardenlogin/v1alpha1"
+	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1/constants"
+)
+
+// accessReviewClient answers the creation of SubjectAccessReviews with the result of allowed
+type accessReviewClient struct {
+	client.Client
+	allowed func(spec authorizationv1.SubjectAccessReviewSpec) bool
+}
+
+func (c *accessReviewClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if review, ok := obj.(*authorizationv1.SubjectAccessReview); ok {
+		review.Status.Allowed = c.allowed(review.Spec)
+		return nil
+	}
+
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+var _ = Describe("KubeconfigBundleController", func() {
+	Describe("#requesterOf", func() {
+		var bundle *gardenloginv1alpha1.KubeconfigBundle
+
+		BeforeEach(func() {
+			bundle = &gardenloginv1alpha1.KubeconfigBundle{
+				ObjectMeta: metav1.ObjectMeta{Name: "bundle", Namespace: "garden-foo"},
+			}
+		})
+
+		It("should return the recorded user info", func() {
+			bundle.Annotations = map[string]string{
+				constants.AnnotationRequestedBy: `{"username":"foo","groups":["bar"]}`,
+			}
+
+			userInfo, err := requesterOf(bundle)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(userInfo.Username).To(Equal("foo"))
+			Expect(userInfo.Groups).To(ConsistOf("bar"))
+		})
+
+		It("should fail in case the annotation is not set", func() {
+			_, err := requesterOf(bundle)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fail in case the annotation cannot be unmarshalled", func() {
+			bundle.Annotations = map[string]string{
+				constants.AnnotationRequestedBy: "foo",
+			}
+
+			_, err := requesterOf(bundle)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fail in case the username is empty", func() {
+			bundle.Annotations = map[string]string{
+				constants.AnnotationRequestedBy: `{"groups":["bar"]}`,
+			}
+
+			_, err := requesterOf(bundle)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("#Reconcile", func() {
+		var (
+			ctx        context.Context
+			reconciler *KubeconfigBundleReconciler
+			c          client.Client
+			bundle     *gardenloginv1alpha1.KubeconfigBundle
+			configMap  *corev1.ConfigMap
+
+			// canListShoots is true in case the requester may list the shoots of the source namespace
+			canListShoots bool
+			// sreCanReadSource is true in case the sre group, which reads the configMaps of the bundle namespace, may read those of the source namespace
+			sreCanReadSource bool
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			canListShoots, sreCanReadSource = true, true
+
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(gardenloginv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			bundle = &gardenloginv1alpha1.KubeconfigBundle{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "bundle",
+					Namespace:   "garden-sre",
+					Annotations: map[string]string{constants.AnnotationRequestedBy: `{"username":"alice"}`},
+				},
+				Spec: gardenloginv1alpha1.KubeconfigBundleSpec{
+					Namespaces: []string{"garden-project-a"},
+				},
+			}
+
+			// the kubeconfig rendered while the requester was still allowed to access the source namespace
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "bundle" + BundleKubeconfigConfigMapNameSuffix, Namespace: "garden-sre"},
+				Data:       map[string]string{constants.DataKeyKubeconfig: "kubeconfig"},
+			}
+
+			roleBinding := &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "readers", Namespace: "garden-sre"},
+				Subjects:   []rbacv1.Subject{{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "sre"}},
+			}
+
+			c = &accessReviewClient{
+				Client: fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(bundle, configMap, roleBinding).Build(),
+				allowed: func(spec authorizationv1.SubjectAccessReviewSpec) bool {
+					attributes := spec.ResourceAttributes
+
+					switch {
+					case attributes.Resource == "shoots":
+						return spec.User == "alice" && canListShoots
+					case attributes.Resource == "configmaps" && attributes.Namespace == "garden-sre":
+						return true
+					case attributes.Resource == "configmaps":
+						return sreCanReadSource
+					default:
+						return false
+					}
+				},
+			}
+
+			config := &configv1alpha1.ControllerManagerConfiguration{}
+			configv1alpha1.SetDefaults_ControllerManagerConfiguration(config)
+
+			reconciler = &KubeconfigBundleReconciler{
+				Client: c,
+				Scheme: scheme,
+				Log:    logr.Discard(),
+				Config: config,
+			}
+		})
+
+		// expectRevoked verifies that the kubeconfig of the bundle is deleted and the given reason is reported in the status
+		expectRevoked := func(result ctrl.Result, reason string) {
+			Expect(result.RequeueAfter).To(Equal(10 * time.Minute))
+
+			err := c.Get(ctx, client.ObjectKeyFromObject(configMap), &corev1.ConfigMap{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(bundle), bundle)).To(Succeed())
+			condition := meta.FindStatusCondition(bundle.Status.Conditions, gardenloginv1alpha1.KubeconfigBundleConditionReady)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(reason))
+			Expect(bundle.Status.ConfigMapName).To(BeEmpty())
+		}
+
+		It("should revoke the kubeconfig once the requester may not list the shoots of a source namespace anymore", func() {
+			canListShoots = false
+
+			result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(bundle)})
+			Expect(err).ToNot(HaveOccurred())
+
+			expectRevoked(result, gardenloginv1alpha1.KubeconfigBundleReasonForbidden)
+		})
+
+		It("should revoke the kubeconfig once a reader of the bundle namespace may not read the configmaps of a source namespace", func() {
+			sreCanReadSource = false
+
+			result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(bundle)})
+			Expect(err).ToNot(HaveOccurred())
+
+			expectRevoked(result, gardenloginv1alpha1.KubeconfigBundleReasonReadersNotAuthorized)
+			Expect(meta.FindStatusCondition(bundle.Status.Conditions, gardenloginv1alpha1.KubeconfigBundleConditionReady).Message).
+				To(Equal("Group sre can read configmaps in namespace garden-sre but not in namespace garden-project-a"))
+		})
+	})
+})
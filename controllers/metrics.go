@@ -0,0 +1,84 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1/constants"
+)
+
+const (
+	// kubeconfigFormatLegacy is the format label value for kubeconfigs passing the shoot reference as command line flags to the plugin
+	kubeconfigFormatLegacy = constants.KubeconfigFormatLegacy
+	// kubeconfigFormatExtension is the format label value for kubeconfigs passing the shoot reference via the cluster extensions
+	kubeconfigFormatExtension = constants.KubeconfigFormatExtension
+)
+
+var (
+	// kubeconfigsRenderedTotal counts the kubeconfig configMaps that were created or changed, labelled by the garden cluster and the kubeconfig format.
+	// Reconciliations that leave the configMap unchanged are not counted.
+	kubeconfigsRenderedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gardenlogin_kubeconfigs_rendered_total",
+			Help: "Total number of rendered shoot kubeconfigs per format",
+		},
+		[]string{"garden", "format"},
+	)
+
+	// reconcileSkipsTotal counts the reconciliations that did not render a kubeconfig, labelled by the garden cluster and the reason,
+	// e.g. KubeconfigQuotaExceeded, CASourceMissing, AdvertisedAddressesMissing, CANotProvisioned, CAInvalid or KubeconfigReconcileFailed.
+	reconcileSkipsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gardenlogin_shoot_reconcile_skips_total",
+			Help: "Total number of shoot reconciliations that did not render a kubeconfig per reason",
+		},
+		[]string{"garden", "reason"},
+	)
+
+	// reconcilesInFlight reflects the number of running and reserved reconciles per namespace of the dispatcher of the ShootReconciler.
+	// The series of a namespace is deleted once no reconciliation is running, hence there are at most MaxConcurrentReconciles series per garden cluster.
+	// Like all shoot metrics, it is also labelled by the garden cluster, see ShootReconciler.gardenLabel.
+	reconcilesInFlight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gardenlogin_shoot_reconciles_in_flight",
+			Help: "Number of shoot reconciliations currently running per namespace",
+		},
+		[]string{"garden", "namespace"},
+	)
+
+	// shootCANotAfterSeconds holds the expiry of the cluster certificate authority published in the kubeconfig of each shoot.
+	// The series of a shoot is deleted together with its kubeconfig, i.e. when the shoot is deleted or its certificate authority source is gone.
+	shootCANotAfterSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gardenlogin_shoot_ca_not_after_seconds",
+			Help: "Expiry of the earliest certificate authority in the kubeconfig of the shoot in seconds since the epoch",
+		},
+		[]string{"garden", "namespace", "shoot"},
+	)
+
+	// namespaceConcurrencyLimitReachedTotal counts how often a request had to wait because MaxConcurrentReconciles or MaxConcurrentReconcilesPerNamespace was reached.
+	// It is not labelled by namespace, as a series per namespace would never be deleted.
+	namespaceConcurrencyLimitReachedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gardenlogin_shoot_reconciles_per_namespace_limit_reached_total",
+			Help: "Total number of shoot reconcile requests that had to wait because the maximum concurrent reconciles in total or per namespace was reached",
+		},
+		[]string{"garden"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		kubeconfigsRenderedTotal,
+		reconcileSkipsTotal,
+		reconcilesInFlight,
+		shootCANotAfterSeconds,
+		namespaceConcurrencyLimitReachedTotal,
+	)
+}
@@ -0,0 +1,304 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	corev1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientcmdlatest "k8s.io/client-go/tools/clientcmd/api/latest"
+	clientcmdv1 "k8s.io/client-go/tools/clientcmd/api/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sigs.k8s.io/yaml"
+
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
+	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1/constants"
+	"github.com/gardener/gardenlogin-controller-manager/internal/util"
+)
+
+// ProjectReconciler maintains a project.kubeconfig configMap in each project namespace, which contains the clusters, contexts and users of all shoot kubeconfigs of the namespace.
+// The request name is the name of the project namespace.
+type ProjectReconciler struct {
+	Scheme *runtime.Scheme
+	client.Client
+	Log         logr.Logger
+	Config      *configv1alpha1.ControllerManagerConfiguration
+	configMutex sync.RWMutex
+}
+
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;
+
+// Reconcile merges the shoot kubeconfigs of the namespace into the project.kubeconfig configMap
+func (r *ProjectReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("namespace", req.Name)
+
+	projectConfigMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: constants.ProjectKubeconfigConfigMapName, Namespace: req.Name}}
+
+	namespace := &corev1.Namespace{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: req.Name}, namespace); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if namespace.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	if !isProjectNamespace(namespace) {
+		// the configMap watch enqueues all namespaces, project kubeconfigs are only maintained in project namespaces
+		return ctrl.Result{}, nil
+	}
+
+	configMaps := &corev1.ConfigMapList{}
+	if err := r.Client.List(ctx, configMaps, client.InNamespace(req.Name), client.MatchingLabels{
+		constants.GardenerOperationsRole: constants.GardenerOperationsKubeconfig,
+	}); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var kubeconfigs [][]byte
+
+	for _, configMap := range configMaps.Items {
+		if !isShootKubeconfigConfigMap(&configMap) {
+			continue
+		}
+
+		kubeconfigs = append(kubeconfigs, []byte(configMap.Data[constants.DataKeyKubeconfig]))
+	}
+
+	existing := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(projectConfigMap), existing); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+
+		if len(kubeconfigs) == 0 {
+			return ctrl.Result{}, nil
+		}
+
+		if sufficient, err := hasSufficientConfigMapQuota(ctx, r.Client, req.Name); errors.Is(err, util.ErrQuotaStatusOutdated) {
+			log.Info("configMap quota status is not up-to-date, will try again shortly", "reason", err.Error())
+			return ctrl.Result{Requeue: true}, nil
+		} else if err != nil {
+			return ctrl.Result{}, err
+		} else if !sufficient {
+			log.Info("configMap quota is not sufficient, will try again later")
+			return ctrl.Result{RequeueAfter: r.getConfig().Controllers.Project.QuotaExceededRetryDelay.Duration}, nil
+		}
+	} else {
+		if existing.Labels[constants.LabelKubeconfigScope] != constants.KubeconfigScopeProject {
+			log.Info("configMap is not managed by the project controller, e.g. because it is the kubeconfig of a shoot with the same name - skipping", "configMap", constants.ProjectKubeconfigConfigMapName)
+			return ctrl.Result{}, nil
+		}
+
+		if len(kubeconfigs) == 0 {
+			// no shoot kubeconfigs left - cleanup project kubeconfig configMap
+			return ctrl.Result{}, client.IgnoreNotFound(r.Client.Delete(ctx, existing))
+		}
+	}
+
+	kubeconfig, err := mergeKubeconfigs(kubeconfigs...)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to merge kubeconfigs: %w", err)
+	}
+
+	if _, err = ctrl.CreateOrUpdate(ctx, r.Client, projectConfigMap, func() error {
+		if projectConfigMap.Labels == nil {
+			projectConfigMap.Labels = make(map[string]string)
+		}
+		projectConfigMap.Labels[constants.GardenerOperationsRole] = constants.GardenerOperationsKubeconfig
+		projectConfigMap.Labels[constants.LabelKubeconfigScope] = constants.KubeconfigScopeProject
+
+		if projectConfigMap.Data == nil {
+			projectConfigMap.Data = make(map[string]string)
+		}
+		projectConfigMap.Data[constants.DataKeyKubeconfig] = string(kubeconfig)
+		return nil
+	}); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to create or update project kubeconfig configMap %s/%s: %w", projectConfigMap.Namespace, projectConfigMap.Name, err)
+	}
+
+	log.Info("reconciled successfully", "kubeconfigs", len(kubeconfigs))
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ProjectReconciler) SetupWithManager(mgr ctrl.Manager, config configv1alpha1.ProjectControllerConfiguration) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Namespace{}, builder.WithPredicates(projectNamespacePredicate())).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
+				return []reconcile.Request{
+					{
+						NamespacedName: types.NamespacedName{
+							Name: o.GetNamespace(),
+						},
+					},
+				}
+			}),
+			builder.WithPredicates(kubeconfigConfigMapPredicate())).
+		Named("project").
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: config.MaxConcurrentReconciles,
+		}).
+		Complete(r)
+}
+
+// projectNamespacePredicate returns true for events of project namespaces, see isProjectNamespace
+func projectNamespacePredicate() predicate.Funcs {
+	return predicate.NewPredicateFuncs(isProjectNamespace)
+}
+
+// isProjectNamespace returns true in case the given namespace belongs to a project, i.e. it has the project role or the project name label.
+// It is the notion of project namespaces of all controllers that maintain a kubeconfig per project namespace.
+func isProjectNamespace(namespace client.Object) bool {
+	labels := namespace.GetLabels()
+	if labels[corev1beta1constants.GardenRole] == corev1beta1constants.GardenRoleProject {
+		return true
+	}
+
+	_, ok := labels[corev1beta1constants.ProjectName]
+
+	return ok
+}
+
+// kubeconfigConfigMapPredicate returns true for events of configMaps that have or had the kubeconfig role.
+// For update events it only returns true in case the kubeconfig data or the labels have changed
+func kubeconfigConfigMapPredicate() predicate.Funcs {
+	hasKubeconfigRole := func(o client.Object) bool {
+		return o.GetLabels()[constants.GardenerOperationsRole] == constants.GardenerOperationsKubeconfig
+	}
+
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return hasKubeconfigRole(e.Object)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return hasKubeconfigRole(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return hasKubeconfigRole(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			old, ok := e.ObjectOld.(*corev1.ConfigMap)
+			if !ok {
+				return false
+			}
+
+			new, ok := e.ObjectNew.(*corev1.ConfigMap)
+			if !ok {
+				return false
+			}
+
+			if !hasKubeconfigRole(old) && !hasKubeconfigRole(new) {
+				return false
+			}
+
+			return old.Data[constants.DataKeyKubeconfig] != new.Data[constants.DataKeyKubeconfig] ||
+				!apiequality.Semantic.DeepEqual(old.Labels, new.Labels)
+		},
+	}
+}
+
+// isShootKubeconfigConfigMap returns true in case the configMap holds the kubeconfig of a single shoot
+func isShootKubeconfigConfigMap(configMap *corev1.ConfigMap) bool {
+	if configMap.Labels[constants.GardenerOperationsRole] != constants.GardenerOperationsKubeconfig {
+		return false
+	}
+
+	if _, ok := configMap.Labels[constants.LabelKubeconfigScope]; ok {
+		return false
+	}
+
+	return strings.HasSuffix(configMap.Name, KubeconfigConfigMapNameSuffix) && configMap.Data[constants.DataKeyKubeconfig] != ""
+}
+
+// mergeKubeconfigs merges the clusters, contexts and users of the given kubeconfigs into one kubeconfig.
+// As the names are prefixed with the namespace and shoot name, they do not clash. In case of duplicate names, the first one wins.
+// The current context of the merged kubeconfig is not set.
+func mergeKubeconfigs(kubeconfigs ...[]byte) ([]byte, error) {
+	merged := &clientcmdv1.Config{
+		Clusters:  []clientcmdv1.NamedCluster{},
+		Contexts:  []clientcmdv1.NamedContext{},
+		AuthInfos: []clientcmdv1.NamedAuthInfo{},
+	}
+
+	clusterNames := make(map[string]bool)
+	contextNames := make(map[string]bool)
+	authInfoNames := make(map[string]bool)
+
+	for _, kubeconfig := range kubeconfigs {
+		config := &clientcmdv1.Config{}
+		if err := yaml.Unmarshal(kubeconfig, config); err != nil {
+			return nil, fmt.Errorf("could not unmarshal kubeconfig: %w", err)
+		}
+
+		for _, cluster := range config.Clusters {
+			if !clusterNames[cluster.Name] {
+				clusterNames[cluster.Name] = true
+				merged.Clusters = append(merged.Clusters, cluster)
+			}
+		}
+
+		for _, context := range config.Contexts {
+			if !contextNames[context.Name] {
+				contextNames[context.Name] = true
+				merged.Contexts = append(merged.Contexts, context)
+			}
+		}
+
+		for _, authInfo := range config.AuthInfos {
+			if !authInfoNames[authInfo.Name] {
+				authInfoNames[authInfo.Name] = true
+				merged.AuthInfos = append(merged.AuthInfos, authInfo)
+			}
+		}
+	}
+
+	// sort to render the same kubeconfig independent of the order of the input
+	sort.Slice(merged.Clusters, func(i, j int) bool { return merged.Clusters[i].Name < merged.Clusters[j].Name })
+	sort.Slice(merged.Contexts, func(i, j int) bool { return merged.Contexts[i].Name < merged.Contexts[j].Name })
+	sort.Slice(merged.AuthInfos, func(i, j int) bool { return merged.AuthInfos[i].Name < merged.AuthInfos[j].Name })
+
+	return runtime.Encode(clientcmdlatest.Codec, merged)
+}
+
+// getConfig returns the configv1alpha1.ControllerManagerConfiguration of the ProjectReconciler
+func (r *ProjectReconciler) getConfig() *configv1alpha1.ControllerManagerConfiguration {
+	r.configMutex.RLock()
+	defer r.configMutex.RUnlock()
+
+	return r.Config
+}
+
+// InjectConfig replaces the configv1alpha1.ControllerManagerConfiguration of the ProjectReconciler, e.g. when the configuration file changed
+func (r *ProjectReconciler) InjectConfig(config *configv1alpha1.ControllerManagerConfiguration) {
+	r.configMutex.Lock()
+	defer r.configMutex.Unlock()
+
+	r.Config = config
+}
@@ -0,0 +1,173 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
+	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1/constants"
+	"github.com/gardener/gardenlogin-controller-manager/internal/test"
+)
+
+var _ = Describe("ProjectController", func() {
+	Describe("#Reconcile", func() {
+		var (
+			ctx        context.Context
+			c          client.Client
+			reconciler *ProjectReconciler
+			namespace  *corev1.Namespace
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "garden-foo"}}
+		})
+
+		reconcile := func() {
+			shootKubeconfig := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "bar" + KubeconfigConfigMapNameSuffix,
+					Namespace: namespace.Name,
+					Labels:    map[string]string{constants.GardenerOperationsRole: constants.GardenerOperationsKubeconfig},
+				},
+				Data: map[string]string{constants.DataKeyKubeconfig: "apiVersion: v1\nkind: Config\n"},
+			}
+
+			c = fakeclient.NewClientBuilder().WithObjects(namespace, shootKubeconfig).Build()
+			reconciler = &ProjectReconciler{Client: c, Log: logr.Discard(), Config: test.DefaultConfiguration()}
+
+			_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: namespace.Name}})
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		projectKubeconfigKey := func() client.ObjectKey {
+			return client.ObjectKey{Namespace: namespace.Name, Name: constants.ProjectKubeconfigConfigMapName}
+		}
+
+		It("should not render a project kubeconfig in namespaces that do not belong to a project", func() {
+			reconcile()
+
+			err := c.Get(ctx, projectKubeconfigKey(), &corev1.ConfigMap{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		DescribeTable("should render the project kubeconfig in project namespaces",
+			func(labels map[string]string) {
+				namespace.Labels = labels
+
+				reconcile()
+
+				Expect(c.Get(ctx, projectKubeconfigKey(), &corev1.ConfigMap{})).To(Succeed())
+			},
+			Entry("project role", map[string]string{"gardener.cloud/role": "project"}),
+			Entry("project name label", map[string]string{"project.gardener.cloud/name": "foo"}),
+		)
+	})
+
+	Describe("#ProjectKubeconfigCleanup", func() {
+		It("should only delete the project kubeconfig configMaps", func() {
+			configMap := func(namespace, name, scope string) *corev1.ConfigMap {
+				c := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+					Labels:    map[string]string{constants.GardenerOperationsRole: constants.GardenerOperationsKubeconfig},
+				}}
+
+				if scope != "" {
+					c.Labels[constants.LabelKubeconfigScope] = scope
+				}
+
+				return c
+			}
+
+			project := configMap("garden-foo", constants.ProjectKubeconfigConfigMapName, constants.KubeconfigScopeProject)
+			otherProject := configMap("garden-bar", constants.ProjectKubeconfigConfigMapName, constants.KubeconfigScopeProject)
+			shoot := configMap("garden-foo", "bar.kubeconfig", "")
+			garden := configMap("garden-foo", constants.GardenKubeconfigConfigMapName, constants.KubeconfigScopeGarden)
+
+			c := fakeclient.NewClientBuilder().WithObjects(project, otherProject, shoot, garden).Build()
+			cleanup := &ProjectKubeconfigCleanup{Client: c, Log: logr.Discard()}
+
+			Expect(cleanup.Start(context.Background())).To(Succeed())
+
+			configMaps := &corev1.ConfigMapList{}
+			Expect(c.List(context.Background(), configMaps)).To(Succeed())
+			Expect(configMaps.Items).To(ConsistOf(
+				HaveField("Name", "bar.kubeconfig"),
+				HaveField("Name", constants.GardenKubeconfigConfigMapName),
+			))
+		})
+	})
+
+	Describe("#mergeKubeconfigs", func() {
+		generate := func(namespace, shootName string) []byte {
+			k := kubeconfigRequest{
+				namespace:             namespace,
+				shootName:             shootName,
+				gardenClusterIdentity: "envtest",
+				clusters: []cluster{
+					{name: "external", apiServerHost: "api." + shootName + ".example.com"},
+					{name: "internal", apiServerHost: "api." + shootName + ".internal.example.com"},
+				},
+				exec: configv1alpha1.ExecConfiguration{
+					Command: "kubectl",
+					Args:    []string{"gardenlogin", "get-client-certificate"},
+				},
+			}
+
+			kubeconfig, err := k.generate(configv1alpha1.KubeconfigFormat{})
+			Expect(err).ToNot(HaveOccurred())
+
+			return kubeconfig
+		}
+
+		It("should merge the clusters, contexts and users of all kubeconfigs", func() {
+			merged, err := mergeKubeconfigs(generate("garden-foo", "bar"), generate("garden-foo", "baz"))
+			Expect(err).ToNot(HaveOccurred())
+
+			config, err := clientcmd.Load(merged)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(config.CurrentContext).To(BeEmpty())
+			Expect(config.Clusters).To(HaveLen(4))
+			Expect(config.Clusters).To(HaveKey("garden-foo--bar-external"))
+			Expect(config.Clusters).To(HaveKey("garden-foo--baz-internal"))
+			Expect(config.Clusters["garden-foo--baz-internal"].Extensions).To(HaveKey("client.authentication.k8s.io/exec"))
+			Expect(config.Contexts).To(HaveLen(4))
+			Expect(config.Contexts["garden-foo--bar-external"].AuthInfo).To(Equal("garden-foo--bar"))
+			Expect(config.AuthInfos).To(HaveLen(2))
+		})
+
+		It("should render the same kubeconfig independent of the order", func() {
+			a, err := mergeKubeconfigs(generate("garden-foo", "bar"), generate("garden-foo", "baz"))
+			Expect(err).ToNot(HaveOccurred())
+
+			b, err := mergeKubeconfigs(generate("garden-foo", "baz"), generate("garden-foo", "bar"))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(a).To(Equal(b))
+		})
+
+		It("should fail for an invalid kubeconfig", func() {
+			_, err := mergeKubeconfigs([]byte("{foo"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,285 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1/constants"
+	"github.com/gardener/gardenlogin-controller-manager/internal/util"
+)
+
+const (
+	// maxReportedWaitingShoots is the maximum number of shoot names listed in the event reporting the shoots waiting for configMap quota
+	maxReportedWaitingShoots = 10
+	// waitingForQuotaReportInterval is the minimum duration between two events reporting the shoots of a namespace waiting for configMap quota
+	waitingForQuotaReportInterval = time.Hour
+)
+
+// quotaState holds the state of the Prioritize quota exhausted policy. The zero value is ready to use.
+type quotaState struct {
+	mutex sync.Mutex
+	// unrenderable holds the shoots whose last reconciliation after being admitted failed to render the kubeconfig,
+	// e.g. because the certificate authority is missing. They are not ranked for the free configMap quota of the namespace.
+	unrenderable map[types.NamespacedName]bool
+	// reportedAt holds the time of the last event reporting the waiting shoots per namespace
+	reportedAt map[string]time.Time
+}
+
+// setUnrenderable records whether the kubeconfig of the shoot with the given key could not be rendered
+func (q *quotaState) setUnrenderable(key types.NamespacedName, unrenderable bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if !unrenderable {
+		delete(q.unrenderable, key)
+		return
+	}
+
+	if q.unrenderable == nil {
+		q.unrenderable = make(map[types.NamespacedName]bool)
+	}
+
+	q.unrenderable[key] = true
+}
+
+// isUnrenderable returns true in case the kubeconfig of the shoot with the given key could not be rendered in its last reconciliation
+func (q *quotaState) isUnrenderable(key types.NamespacedName) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return q.unrenderable[key]
+}
+
+// shouldReport returns true in case the waiting shoots of the given namespace were not reported within the waitingForQuotaReportInterval.
+// In this case, the given time is recorded as time of the report.
+func (q *quotaState) shouldReport(namespace string, now time.Time) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if reportedAt, ok := q.reportedAt[namespace]; ok && now.Sub(reportedAt) < waitingForQuotaReportInterval {
+		return false
+	}
+
+	if q.reportedAt == nil {
+		q.reportedAt = make(map[string]time.Time)
+	}
+
+	q.reportedAt[namespace] = now
+
+	return true
+}
+
+// hasSufficientConfigMapQuota returns true in case the resource quotas of the namespace allow to create one more configMap.
+// An error wrapping util.ErrQuotaStatusOutdated is returned in case the status of a resource quota is not up-to-date.
+func hasSufficientConfigMapQuota(ctx context.Context, c client.Client, namespace string) (bool, error) {
+	list := &corev1.ResourceQuotaList{}
+
+	if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		return false, err
+	}
+
+	return util.HasSufficientQuota(list.Items, util.ConfigMapQuotaUsage())
+}
+
+// admitByQuotaPriority returns true in case the kubeconfig configMap of the given shoot may be created with the Prioritize quota exhausted policy.
+// The free configMap quota of the namespace is handed to the shoots without kubeconfig configMap in priority order. Only shoots whose kubeconfig can be rendered
+// are ranked, so that shoots without advertised addresses or with a missing certificate authority do not hold quota. In case the quota does not suffice
+// for all of them, orphaned kubeconfig configMaps are garbage collected and the waiting shoots are reported in an event on the exhausted ResourceQuota,
+// at most once per namespace within the waitingForQuotaReportInterval.
+func (r *ShootReconciler) admitByQuotaPriority(ctx context.Context, log logr.Logger, shoot *gardencorev1beta1.Shoot) (bool, error) {
+	resourceQuotas := &corev1.ResourceQuotaList{}
+	if err := r.Client.List(ctx, resourceQuotas, client.InNamespace(shoot.Namespace)); err != nil {
+		return false, err
+	}
+
+	available, exhausted, err := util.AvailableQuota(resourceQuotas.Items, util.ConfigMapQuotaUsage())
+	if err != nil {
+		return false, err
+	}
+
+	if available < 0 {
+		// configMaps are not limited
+		return true, nil
+	}
+
+	shoots, err := r.shootsWithoutKubeconfigConfigMap(ctx, shoot.Namespace)
+	if err != nil {
+		return false, err
+	}
+
+	var waiting []gardencorev1beta1.Shoot
+
+	for _, s := range shoots {
+		// the given shoot is always ranked, so that it gets quota once it can be rendered again
+		if s.Name == shoot.Name || r.canRender(&s) {
+			waiting = append(waiting, s)
+		}
+	}
+
+	sortShootsByPriority(waiting)
+
+	// the shoot is ranked last in case it is not yet part of the cached shoots
+	rank := len(waiting)
+
+	for i := range waiting {
+		if waiting[i].Name == shoot.Name {
+			rank = i
+			break
+		}
+	}
+
+	if int64(rank) < available {
+		return true, nil
+	}
+
+	if err := r.deleteOrphanedKubeconfigConfigMaps(ctx, log, shoot.Namespace); err != nil {
+		return false, fmt.Errorf("failed to garbage collect orphaned kubeconfig configMaps: %w", err)
+	}
+
+	if exhausted != nil && r.Recorder != nil && int64(len(waiting)) > available && r.quota.shouldReport(shoot.Namespace, time.Now()) {
+		var names []string
+		for _, s := range waiting[available:] {
+			names = append(names, s.Name)
+		}
+
+		missing := len(names)
+		if len(names) > maxReportedWaitingShoots {
+			names = append(names[:maxReportedWaitingShoots], "...")
+		}
+
+		r.Recorder.Eventf(exhausted, corev1.EventTypeWarning, EventReasonKubeconfigsWaitingForQuota,
+			"%d shoots wait for a kubeconfig configMap, the configMap quota must be increased by %d: %s", missing, missing, strings.Join(names, ", "))
+	}
+
+	return false, nil
+}
+
+// canRender returns true in case the kubeconfig of the given shoot can be rendered as far as known, i.e. the shoot advertises addresses
+// and the last reconciliation did not fail to render its kubeconfig
+func (r *ShootReconciler) canRender(shoot *gardencorev1beta1.Shoot) bool {
+	return len(shoot.Status.AdvertisedAddresses) > 0 && !r.quota.isUnrenderable(client.ObjectKeyFromObject(shoot))
+}
+
+// shootsWithoutKubeconfigConfigMap returns the shoots of the namespace that do not have a corresponding <shootname>.kubeconfig configMap
+func (r *ShootReconciler) shootsWithoutKubeconfigConfigMap(ctx context.Context, namespace string) ([]gardencorev1beta1.Shoot, error) {
+	shoots, err := util.ListShoots(ctx, r.Client, client.InNamespace(namespace))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shoots: %w", err)
+	}
+
+	configMaps, err := r.listKubeconfigConfigMaps(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool)
+	for _, configMap := range configMaps.Items {
+		existing[configMap.Name] = true
+	}
+
+	var result []gardencorev1beta1.Shoot
+
+	for _, shoot := range shoots {
+		if !existing[shoot.Name+KubeconfigConfigMapNameSuffix] {
+			result = append(result, shoot)
+		}
+	}
+
+	return result, nil
+}
+
+// deleteOrphanedKubeconfigConfigMaps deletes the <shootname>.kubeconfig configMaps of the namespace that are controlled by a shoot, which does not exist anymore.
+// Usually these configMaps are removed by the controller or the garbage collector, but they block configMap quota until then.
+func (r *ShootReconciler) deleteOrphanedKubeconfigConfigMaps(ctx context.Context, log logr.Logger, namespace string) error {
+	shoots := util.NewShootList()
+	if err := r.Client.List(ctx, shoots, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list shoots: %w", err)
+	}
+
+	shootNames := make(map[string]bool)
+	for _, shoot := range shoots.Items {
+		shootNames[shoot.GetName()] = true
+	}
+
+	configMaps, err := r.listKubeconfigConfigMaps(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	for i := range configMaps.Items {
+		configMap := &configMaps.Items[i]
+
+		if _, ok := configMap.Labels[constants.LabelKubeconfigScope]; ok || !strings.HasSuffix(configMap.Name, KubeconfigConfigMapNameSuffix) {
+			continue
+		}
+
+		if shootNames[strings.TrimSuffix(configMap.Name, KubeconfigConfigMapNameSuffix)] {
+			continue
+		}
+
+		// only configMaps rendered by the shoot controller are garbage collected
+		owner := metav1.GetControllerOfNoCopy(configMap)
+		if owner == nil || owner.APIVersion != gardencorev1beta1.SchemeGroupVersion.String() || owner.Kind != "Shoot" {
+			continue
+		}
+
+		log.Info("deleting orphaned kubeconfig configMap", "configMap", configMap.Name)
+
+		if err := r.Client.Delete(ctx, configMap, client.Preconditions{UID: &configMap.UID}); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// listKubeconfigConfigMaps returns the metadata of the configMaps of the namespace that have the kubeconfig role
+func (r *ShootReconciler) listKubeconfigConfigMaps(ctx context.Context, namespace string) (*metav1.PartialObjectMetadataList, error) {
+	configMaps := &metav1.PartialObjectMetadataList{}
+	configMaps.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMapList"))
+
+	if err := r.Client.List(ctx, configMaps, client.InNamespace(namespace), client.MatchingLabels{
+		constants.GardenerOperationsRole: constants.GardenerOperationsKubeconfig,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list configMaps: %w", err)
+	}
+
+	return configMaps, nil
+}
+
+// sortShootsByPriority sorts the given shoots in the order in which they receive free configMap quota:
+// shoots with purpose production first, then the oldest shoots first
+func sortShootsByPriority(shoots []gardencorev1beta1.Shoot) {
+	isProduction := func(shoot *gardencorev1beta1.Shoot) bool {
+		return shoot.Spec.Purpose != nil && *shoot.Spec.Purpose == gardencorev1beta1.ShootPurposeProduction
+	}
+
+	sort.SliceStable(shoots, func(i, j int) bool {
+		if iProduction, jProduction := isProduction(&shoots[i]), isProduction(&shoots[j]); iProduction != jProduction {
+			return iProduction
+		}
+
+		if !shoots[i].CreationTimestamp.Equal(&shoots[j].CreationTimestamp) {
+			return shoots[i].CreationTimestamp.Before(&shoots[j].CreationTimestamp)
+		}
+
+		return shoots[i].Name < shoots[j].Name
+	})
+}
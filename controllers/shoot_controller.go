@@ -10,13 +10,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/Masterminds/semver"
 	gardencorev1alpha1 "github.com/gardener/gardener/pkg/apis/core/v1alpha1"
-	corev1alpha1helper "github.com/gardener/gardener/pkg/apis/core/v1alpha1/helper"
 	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
 	corev1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
 	"github.com/gardener/gardener/pkg/utils/secrets"
@@ -24,143 +24,309 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/json"
-	"k8s.io/apimachinery/pkg/util/wait"
 	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	clientauthenticationv1 "k8s.io/client-go/pkg/apis/clientauthentication/v1"
 	clientauthenticationv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
 	clientcmdlatest "k8s.io/client-go/tools/clientcmd/api/latest"
 	clientcmdv1 "k8s.io/client-go/tools/clientcmd/api/v1"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
 	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1"
 	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1/constants"
 	"github.com/gardener/gardenlogin-controller-manager/internal/util"
 )
 
 // KubeconfigConfigMapNameSuffix is the name suffix for the configMap that holds the kubeconfig for the corresponding shoot cluster
-const KubeconfigConfigMapNameSuffix = ".kubeconfig"
+const KubeconfigConfigMapNameSuffix = constants.KubeconfigConfigMapNameSuffix
+
+const (
+	// EventReasonKubeconfigRendered is the event reason used when the kubeconfig configMap was created or updated
+	EventReasonKubeconfigRendered = "KubeconfigRendered"
+	// EventReasonKubeconfigQuotaExceeded is the event reason used when the configMap quota of the namespace does not allow to create the kubeconfig configMap
+	EventReasonKubeconfigQuotaExceeded = "KubeconfigQuotaExceeded"
+	// EventReasonKubeconfigsWaitingForQuota is the event reason used on an exhausted ResourceQuota for the summary of the shoots waiting for configMap quota
+	EventReasonKubeconfigsWaitingForQuota = "KubeconfigsWaitingForQuota"
+	// EventReasonCASourceMissing is the event reason used when none of the configured ca sources, e.g. the ShootState, exists for the shoot
+	EventReasonCASourceMissing = "CASourceMissing"
+	// EventReasonAdvertisedAddressesMissing is the event reason used when the shoot does not yet advertise any addresses
+	EventReasonAdvertisedAddressesMissing = "AdvertisedAddressesMissing"
+	// EventReasonCANotProvisioned is the event reason used when the cluster certificate authority is not yet provisioned
+	EventReasonCANotProvisioned = "CANotProvisioned"
+	// EventReasonCAInvalid is the event reason used when the cluster certificate authority could not be read or validated
+	EventReasonCAInvalid = "CAInvalid"
+	// EventReasonReconcileFailed is the event reason used when the reconciliation failed for any other reason
+	EventReasonReconcileFailed = "KubeconfigReconcileFailed"
+)
+
+const (
+	// caExpiryRequeueLeadTime is the time before the expiry of the cluster certificate authority at which the shoot is reconciled again,
+	// so that a refreshed certificate authority is picked up proactively
+	caExpiryRequeueLeadTime = 24 * time.Hour
+	// caExpiryMinRequeueInterval is the minimum interval in which shoots with an (almost) expired certificate authority are reconciled again
+	caExpiryMinRequeueInterval = time.Hour
+)
+
+const (
+	// OutcomeSucceeded indicates that the kubeconfig configMap is up-to-date
+	OutcomeSucceeded = "Succeeded"
+	// OutcomeSkipped indicates that the kubeconfig configMap could not be rendered (yet) and the reconciliation was skipped
+	OutcomeSkipped = "Skipped"
+	// OutcomeFailed indicates that an error occurred during the reconciliation
+	OutcomeFailed = "Failed"
+)
 
 // ShootReconciler reconciles a Shoot object
 type ShootReconciler struct {
 	Scheme *runtime.Scheme
 	client.Client
-	Log                         logr.Logger
-	Config                      *util.ControllerManagerConfiguration
-	ReconcilerCountPerNamespace map[string]int
-	mutex                       sync.RWMutex
-	configMutex                 sync.RWMutex
+	// APIReader reads objects directly from the API server, it is used to read the <shoot>.ca-cluster Secrets without caching all Secrets
+	APIReader client.Reader
+	Log       logr.Logger
+	Recorder  record.EventRecorder
+	Config    *configv1alpha1.ControllerManagerConfiguration
+	// GardenName is the name of the additional garden cluster that is served by the reconciler, it is empty for the garden cluster of the manager
+	GardenName string
+	// GardenClusterIdentity is the cluster identity of the garden cluster that is rendered into the kubeconfigs.
+	// If empty, it is read from the cluster-identity configMap in the kube-system namespace of the garden cluster.
+	GardenClusterIdentity string
+	configMutex           sync.RWMutex
+
+	// dispatcher limits the concurrent reconciles in total and per namespace and hands out free reconcile slots round-robin across the namespaces
+	dispatcher *util.FairDispatcher
+	// pendingRequests is used to enqueue waiting requests again once the dispatcher reserved a reconcile slot for them
+	pendingRequests chan event.GenericEvent
+	// resync reconciles all shoots once the kubeconfig section of the configuration changed
+	resync *util.Resync
+	// caSource reads the cluster certificate authority of the shoots from the configured ca sources
+	caSource util.CASource
+	// clusterIdentityCache is a dedicated cache holding only the cluster-identity configMap in the kube-system namespace of the garden cluster.
+	// It is nil in case GardenClusterIdentity is set.
+	clusterIdentityCache cache.Cache
+	// quota holds the state of the Prioritize quota exhausted policy
+	quota quotaState
 }
 
+// mainGardenLabel is the value of the garden label of the metrics of the garden cluster of the manager
+const mainGardenLabel = "main"
+
+// errGardenClusterIdentityMissing is returned in case the cluster-identity configMap of the garden cluster does not exist or holds no identity
+var errGardenClusterIdentityMissing = errors.New("garden cluster identity is missing")
+
 //+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete;manage;
 //+kubebuilder:rbac:groups="",resources=configmaps/finalizers,verbs=update;
 //+kubebuilder:rbac:groups="",resources=resourcequotas,verbs=get;list;watch;
 //+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
-//+kubebuilder:rbac:groups="core.gardener.cloud",resources=shootstates,verbs=get;list;watch;
-//+kubebuilder:rbac:groups="core.gardener.cloud",resources=shoots,verbs=get;list;watch;
+// The shootstates permission is only required in case the ShootState ca source is configured, it is not generated into the manager-role
+// but deployed with the optional .landscaper/blueprint/config/rbac-shootstate kustomization.
+// Likewise, the get permission on secrets is only required for the Secret ca source, which reads the <shoot>.ca-cluster Secrets directly
+// from the API server, and is deployed with the optional .landscaper/blueprint/config/rbac-secret kustomization.
+// The shoots are only patched to record the last reconcile outcome in their annotations, which happens only in case the outcome changes.
+//+kubebuilder:rbac:groups="core.gardener.cloud",resources=shoots,verbs=get;list;watch;patch;
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch;
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 func (r *ShootReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := r.Log.WithValues("shoot", req.NamespacedName)
 
-	if err := r.increaseCounterForNamespace(req.Namespace); err != nil {
-		log.Info("maximum parallel reconciles reached for namespace - requeuing the req")
+	if !r.dispatcher.Admit(req.NamespacedName) {
+		// The request waits in the dispatcher and is enqueued again as soon as a reconcile slot is reserved for it.
+		// This way requests of a saturated namespace do not spin in the queue and do not block worker slots of other namespaces.
+		namespaceConcurrencyLimitReachedTotal.WithLabelValues(r.gardenLabel()).Inc()
+		log.Info("maximum parallel reconciles reached - request waits for a free reconcile slot")
 
-		return ctrl.Result{
-			RequeueAfter: wait.Jitter(time.Duration(int64(100*time.Millisecond)), 50), // requeue after 100ms - 5s
-		}, nil
+		return ctrl.Result{}, nil
 	}
 
 	res, err := r.handleRequest(ctx, req)
 
-	r.decreaseCounterForNamespace(req.Namespace)
+	if doneErr := r.dispatcher.Done(req.Namespace); doneErr != nil {
+		log.Error(doneErr, "failed to release reconcile slot")
+	}
 
 	return res, err
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *ShootReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, config util.ShootControllerConfiguration) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&gardencorev1beta1.Shoot{}, builder.WithPredicates(r.shootPredicate())).
+func (r *ShootReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, config configv1alpha1.ShootControllerConfiguration) error {
+	r.pendingRequests = make(chan event.GenericEvent)
+	r.setResync(util.NewResync())
+	r.dispatcher = util.NewFairDispatcher(func() (int, int) {
+		shootConfig := r.getConfig().Controllers.Shoot
+		return shootConfig.MaxConcurrentReconciles, shootConfig.MaxConcurrentReconcilesPerNamespace
+	}, r.enqueuePendingRequest)
+	r.dispatcher.InFlightChanged = func(namespace string, inFlight int) {
+		if inFlight == 0 {
+			reconcilesInFlight.DeleteLabelValues(r.gardenLabel(), namespace)
+		} else {
+			reconcilesInFlight.WithLabelValues(r.gardenLabel(), namespace).Set(float64(inFlight))
+		}
+	}
+
+	if err := mgr.Add(r.dispatcher); err != nil {
+		return fmt.Errorf("failed to add dispatcher to manager: %w", err)
+	}
+
+	caSourceTypes := r.getConfig().Kubeconfig.CASources
+
+	caSource, err := util.NewCASource(caSourceTypes, r.Client, r.APIReader)
+	if err != nil {
+		return err
+	}
+
+	r.caSource = caSource
+
+	bldr := ctrl.NewControllerManagedBy(mgr).
+		For(util.NewShoot(), builder.WithPredicates(r.shootPredicate())).
 		Owns(&corev1.ConfigMap{}, builder.WithPredicates(r.configMapPredicate())).
-		Watches(&source.Kind{Type: &gardencorev1alpha1.ShootState{}},
-			handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
-				return []reconcile.Request{
-					{
-						NamespacedName: types.NamespacedName{
-							Name:      o.GetName(),
-							Namespace: o.GetNamespace(),
+		Watches(&source.Channel{Source: r.pendingRequests}, &handler.EnqueueRequestForObject{}).
+		// the kubeconfig section of the configuration is part of all kubeconfigs, hence all shoots are reconciled in case it changes
+		Watches(r.resync.Source(), handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
+			return r.allShootRequests(ctx)
+		}))
+
+	// the <shoot>.ca-cluster Secrets are not watched, so that the Secrets of the garden cluster are not cached.
+	// Changes of these Secrets are picked up with the next reconciliation of the shoot.
+	for _, caSourceType := range caSourceTypes {
+		switch caSourceType {
+		case configv1alpha1.CASourceShootState:
+			bldr = bldr.Watches(&source.Kind{Type: &gardencorev1alpha1.ShootState{}},
+				handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
+					return []reconcile.Request{
+						{
+							NamespacedName: types.NamespacedName{
+								Name:      o.GetName(),
+								Namespace: o.GetNamespace(),
+							},
 						},
-					},
-				}
+					}
+				}),
+				builder.WithPredicates(r.shootStatePredicate()))
+		case configv1alpha1.CASourceConfigMap:
+			bldr = bldr.Watches(&source.Kind{Type: &corev1.ConfigMap{}},
+				handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
+					return []reconcile.Request{
+						{
+							NamespacedName: types.NamespacedName{
+								Name:      strings.TrimSuffix(o.GetName(), util.CAClusterNameSuffix),
+								Namespace: o.GetNamespace(),
+							},
+						},
+					}
+				}),
+				builder.WithPredicates(caClusterConfigMapPredicate()))
+		}
+	}
+
+	if r.GardenClusterIdentity == "" {
+		// the cluster identity is read from a dedicated cache, so that only the cluster-identity configMap of the kube-system namespace is watched
+		clusterIdentityCache, err := cache.New(mgr.GetConfig(), cache.Options{
+			Scheme:    mgr.GetScheme(),
+			Mapper:    mgr.GetRESTMapper(),
+			Namespace: metav1.NamespaceSystem,
+			SelectorsByObject: cache.SelectorsByObject{
+				&corev1.ConfigMap{}: {Field: fields.OneTermEqualSelector("metadata.name", corev1beta1constants.ClusterIdentity)},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create cache for garden cluster identity: %w", err)
+		}
+
+		if err := mgr.Add(clusterIdentityCache); err != nil {
+			return fmt.Errorf("failed to add cache for garden cluster identity to manager: %w", err)
+		}
+
+		r.clusterIdentityCache = clusterIdentityCache
+
+		// the garden cluster identity is part of all kubeconfigs, hence all shoots are reconciled in case it changes
+		bldr = bldr.Watches(source.NewKindWithCache(&corev1.ConfigMap{}, clusterIdentityCache),
+			handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
+				return r.allShootRequests(ctx)
 			}),
-			builder.WithPredicates(r.shootStatePredicate())).
+			builder.WithPredicates(clusterIdentityPredicate()))
+	}
+
+	return bldr.
 		Watches(&source.Kind{Type: &corev1.ResourceQuota{}},
 			handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
 				// request reconciliation for all shoots in the namespace that do not already have a corresponding <shootname>.kubeconfig configMap.
-
-				shoots := &metav1.PartialObjectMetadataList{}
-				shoots.SetGroupVersionKind(gardencorev1beta1.SchemeGroupVersion.WithKind("ShootList"))
-				if err := r.Client.List(ctx, shoots, client.InNamespace(o.GetNamespace())); err != nil {
-					r.Log.Info("failed to list shoots", "shoots", o.GetNamespace())
-					return []reconcile.Request{}
-				}
-
-				configMaps := &metav1.PartialObjectMetadataList{}
-				configMaps.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMapList"))
-				listOption := client.MatchingLabels{
-					constants.GardenerOperationsRole: constants.GardenerOperationsKubeconfig,
-				}
-
-				if err := r.Client.List(ctx, configMaps, client.InNamespace(o.GetNamespace()), listOption); err != nil {
-					r.Log.Info("failed to list configMaps", "configMaps", o.GetNamespace())
+				shoots, err := r.shootsWithoutKubeconfigConfigMap(ctx, o.GetNamespace())
+				if err != nil {
+					r.Log.Info("failed to list shoots without kubeconfig configMap", "namespace", o.GetNamespace(), "error", err.Error())
 					return []reconcile.Request{}
 				}
 
 				var reconcileRequests []reconcile.Request
-				for _, shoot := range shoots.Items {
-					needsReconcile := true
-					for _, configMap := range configMaps.Items {
-						kubeconfigConfigMapName := fmt.Sprintf("%s%s", shoot.Name, KubeconfigConfigMapNameSuffix)
-						if configMap.Name == kubeconfigConfigMapName {
-							// there is already a matching kubeconfig configMap for this shoot, no need to reconcile
-							needsReconcile = false
-							break
-						}
-					}
-					if needsReconcile {
-						reconcileRequests = append(reconcileRequests, reconcile.Request{
-							NamespacedName: types.NamespacedName{
-								Name:      shoot.Name,
-								Namespace: shoot.Namespace,
-							},
-						})
-					}
+				for _, shoot := range shoots {
+					reconcileRequests = append(reconcileRequests, reconcile.Request{
+						NamespacedName: types.NamespacedName{
+							Name:      shoot.Name,
+							Namespace: shoot.Namespace,
+						},
+					})
 				}
 				return reconcileRequests
 			}),
 			builder.WithPredicates(r.resourceQuotaPredicate())).
-		Named("main").
+		Named(r.controllerName()).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: config.MaxConcurrentReconciles,
 		}).
 		Complete(r)
 }
 
-// shootPredicate returns true for all create and delete events. It returns true for update events in case the advertised addresses have changed
+// allShootRequests returns the reconcile requests of all shoots
+func (r *ShootReconciler) allShootRequests(ctx context.Context) []reconcile.Request {
+	shootList := util.NewShootList()
+	if err := r.Client.List(ctx, shootList); err != nil {
+		r.Log.Info("failed to list shoots", "error", err.Error())
+		return []reconcile.Request{}
+	}
+
+	var reconcileRequests []reconcile.Request
+	for _, shoot := range shootList.Items {
+		reconcileRequests = append(reconcileRequests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      shoot.GetName(),
+				Namespace: shoot.GetNamespace(),
+			},
+		})
+	}
+
+	return reconcileRequests
+}
+
+// controllerName returns the name of the controller, which is the name of the garden cluster for additional garden clusters
+// so that the controller metrics can be told apart
+func (r *ShootReconciler) controllerName() string {
+	if r.GardenName == "" {
+		return "main"
+	}
+
+	return r.GardenName
+}
+
+// shootPredicate returns true for all create and delete events. It returns true for update events in case the advertised addresses
+// or the phase of the certificate authorities rotation have changed
 func (r *ShootReconciler) shootPredicate() predicate.Funcs {
 	return predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
@@ -176,15 +342,32 @@ func (r *ShootReconciler) shootPredicate() predicate.Funcs {
 				return false
 			}
 
-			old, ok := e.ObjectOld.(*gardencorev1beta1.Shoot)
+			oldObj, ok := e.ObjectOld.(*unstructured.Unstructured)
 			if !ok {
-				log.Error(nil, "Update event old runtime object cannot be converted to Shoot")
+				log.Error(nil, "Update event old runtime object is not an unstructured Shoot")
 				return false
 			}
 
-			new, ok := e.ObjectNew.(*gardencorev1beta1.Shoot)
+			newObj, ok := e.ObjectNew.(*unstructured.Unstructured)
 			if !ok {
-				log.Error(nil, "Update event new runtime object cannot be converted to Shoot")
+				log.Error(nil, "Update event new runtime object is not an unstructured Shoot")
+				return false
+			}
+
+			// the kubeconfig contains the ca bundle while the certificate authorities are rotated
+			if util.CARotationPhase(oldObj) != util.CARotationPhase(newObj) {
+				return true
+			}
+
+			old, err := util.ShootFromUnstructured(oldObj)
+			if err != nil {
+				log.Error(err, "Update event old runtime object cannot be converted to Shoot")
+				return false
+			}
+
+			new, err := util.ShootFromUnstructured(newObj)
+			if err != nil {
+				log.Error(err, "Update event new runtime object cannot be converted to Shoot")
 				return false
 			}
 
@@ -261,7 +444,7 @@ func (r *ShootReconciler) configMapPredicate() predicate.Funcs {
 	}
 }
 
-// shootStatePredicate returns true for all create and delete events. It returns true for update events in case the cluster ca changes
+// shootStatePredicate returns true for all create and delete events. It returns true for update events in case the cluster ca or the ca bundle changes
 func (r *ShootReconciler) shootStatePredicate() predicate.Funcs {
 	return predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
@@ -292,29 +475,107 @@ func (r *ShootReconciler) shootStatePredicate() predicate.Funcs {
 				return false
 			}
 
-			oldCaCert, err := clusterCaCert(old)
-			if err != nil && !errors.Is(err, errCaNotProvisioned) {
+			oldCaCert, err := util.ClusterCACert(old)
+			if err != nil && !errors.Is(err, util.ErrCANotProvisioned) {
 				log.Error(nil, "Update event failed to read cluster ca from old ShootState", "error", err)
 				return false
 			}
 
-			newCaCert, err := clusterCaCert(new)
+			newCaCert, err := util.ClusterCACert(new)
 			if err != nil {
-				// The errCaNotProvisioned is usually returned for newly created clusters, in this case we do not want to log it as error as it is expected.
+				// The util.ErrCANotProvisioned is usually returned for newly created clusters, in this case we do not want to log it as error as it is expected.
 				// However in case the new ca cert is nil, it does not make sense to handle the event and that's why we skip it
-				if !errors.Is(err, errCaNotProvisioned) {
+				if !errors.Is(err, util.ErrCANotProvisioned) {
 					log.Error(nil, "Update event failed to read cluster ca from new ShootState", "error", err)
 				}
 				return false
 			}
 
 			// if the ca cert has changed, we want to handle the event
-			return !apiequality.Semantic.DeepEqual(oldCaCert, newCaCert)
+			if !apiequality.Semantic.DeepEqual(oldCaCert, newCaCert) {
+				return true
+			}
+
+			oldCaBundle, err := util.ClusterCABundle(old)
+			if err != nil {
+				log.Error(nil, "Update event failed to read cluster ca bundle from old ShootState", "error", err)
+				return false
+			}
+
+			newCaBundle, err := util.ClusterCABundle(new)
+			if err != nil {
+				log.Error(nil, "Update event failed to read cluster ca bundle from new ShootState", "error", err)
+				return false
+			}
+
+			// if the ca bundle has changed, e.g. during a rotation of the certificate authorities, we want to handle the event
+			return !apiequality.Semantic.DeepEqual(oldCaBundle, newCaBundle)
+		},
+	}
+}
+
+// caClusterConfigMapPredicate returns true for events of <shoot>.ca-cluster configMaps. It returns true for update events in case the certificate authority has changed
+func caClusterConfigMapPredicate() predicate.Funcs {
+	isCAClusterConfigMap := func(o client.Object) bool {
+		return strings.HasSuffix(o.GetName(), util.CAClusterNameSuffix)
+	}
+
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return isCAClusterConfigMap(e.Object)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return isCAClusterConfigMap(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return isCAClusterConfigMap(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			old, ok := e.ObjectOld.(*corev1.ConfigMap)
+			if !ok {
+				return false
+			}
+
+			new, ok := e.ObjectNew.(*corev1.ConfigMap)
+			if !ok {
+				return false
+			}
+
+			return isCAClusterConfigMap(new) && old.Data[secrets.DataKeyCertificateCA] != new.Data[secrets.DataKeyCertificateCA]
+		},
+	}
+}
+
+// clusterIdentityPredicate returns true for create events and for update events in case the garden cluster identity has changed.
+// Deleting the cluster-identity configMap does not require a reconciliation, as the kubeconfigs are kept until the identity is known again.
+func clusterIdentityPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return true
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			old, ok := e.ObjectOld.(*corev1.ConfigMap)
+			if !ok {
+				return false
+			}
+
+			new, ok := e.ObjectNew.(*corev1.ConfigMap)
+			if !ok {
+				return false
+			}
+
+			return old.Data[corev1beta1constants.ClusterIdentity] != new.Data[corev1beta1constants.ClusterIdentity]
 		},
 	}
 }
 
-// resourceQuotaPredicate returns true for all create and delete events. It returns true for update events in case the resource quota for count/configmaps is increased or configMap quota was freed
+// resourceQuotaPredicate returns true for all create and delete events. It returns true for update events in case the resource quota for configMaps is increased or configMap quota was freed
 func (r *ShootReconciler) resourceQuotaPredicate() predicate.Funcs {
 	return predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
@@ -345,7 +606,7 @@ func (r *ShootReconciler) resourceQuotaPredicate() predicate.Funcs {
 				return false
 			}
 
-			resourceName := []corev1.ResourceName{"count/configmaps"}
+			resourceName := quotav1.ResourceNames(util.ConfigMapQuotaUsage())
 
 			// if the hard quota or used quota for configMaps has increased, we want to handle the event
 
@@ -385,83 +646,174 @@ func (r *ShootReconciler) handleRequest(ctx context.Context, req ctrl.Request) (
 	name := fmt.Sprintf("%s%s", req.Name, KubeconfigConfigMapNameSuffix)
 	kubeconfigConfigMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: req.Namespace}}
 
-	// fetch Shoot
-	shoot := &gardencorev1beta1.Shoot{}
-
-	if err := r.Client.Get(ctx, req.NamespacedName, shoot); err != nil {
+	// fetch Shoot, the phase of the certificate authorities rotation is read from the same object
+	shoot, rotationPhase, err := util.GetShoot(ctx, r.Client, req.NamespacedName)
+	if err != nil {
 		if apierrors.IsNotFound(err) {
 			// shoot does not exist anymore - cleanup kubeconfig configMap
+			shootCANotAfterSeconds.DeleteLabelValues(r.gardenLabel(), req.Namespace, req.Name)
+			r.quota.setUnrenderable(req.NamespacedName, false)
+
 			return ctrl.Result{}, client.IgnoreNotFound(r.Client.Delete(ctx, kubeconfigConfigMap))
 		}
 		// Error reading the object - requeue the request
 		return ctrl.Result{}, err
 	}
 
+	res, outcome, err := r.reconcileKubeconfig(ctx, log, shoot, rotationPhase, kubeconfigConfigMap)
+	if outcome == nil && err != nil {
+		outcome = &reconcileOutcome{
+			outcome:   OutcomeFailed,
+			eventType: corev1.EventTypeWarning,
+			reason:    EventReasonReconcileFailed,
+			message:   err.Error(),
+		}
+	}
+
+	if outcome != nil {
+		r.countOutcome(outcome)
+
+		// a shoot that was admitted to the configMap quota but could not be rendered does not hold quota until it is rendered
+		r.quota.setUnrenderable(req.NamespacedName, outcome.outcome != OutcomeSucceeded && outcome.reason != EventReasonKubeconfigQuotaExceeded)
+
+		r.recordOutcome(ctx, log, shoot, outcome)
+	}
+
+	return res, err
+}
+
+// countOutcome counts the given outcome in the reconcile metrics. Every reconciliation that did not render a kubeconfig is counted as skip
+// labelled by its reason, including the reconciliations that failed, e.g. with reason CAInvalid or KubeconfigReconcileFailed.
+func (r *ShootReconciler) countOutcome(outcome *reconcileOutcome) {
+	if outcome.outcome != OutcomeSucceeded {
+		reconcileSkipsTotal.WithLabelValues(r.gardenLabel(), outcome.reason).Inc()
+	}
+}
+
+// reconcileOutcome describes the result of a kubeconfig reconciliation. It is published as event and annotation on the Shoot
+type reconcileOutcome struct {
+	// outcome is one of OutcomeSucceeded, OutcomeSkipped or OutcomeFailed
+	outcome string
+	// eventType is the type of the event, either corev1.EventTypeNormal or corev1.EventTypeWarning
+	eventType string
+	// reason is the event reason
+	reason string
+	// message is the human readable event message
+	message string
+	// silent is true in case no event should be emitted, e.g. because nothing has changed
+	silent bool
+}
+
+// reconcileKubeconfig renders the kubeconfig for the given shoot, whose certificate authorities rotation is in the given phase, and stores it in the kubeconfigConfigMap.
+// In addition to the result and error, it returns the outcome of the reconciliation, which is nil in case it should not be recorded.
+func (r *ShootReconciler) reconcileKubeconfig(ctx context.Context, log logr.Logger, shoot *gardencorev1beta1.Shoot, rotationPhase string, kubeconfigConfigMap *corev1.ConfigMap) (ctrl.Result, *reconcileOutcome, error) {
 	// We confirmed that the shoot still exists.
 	// Now we verify that we have sufficient quota in case the kubeconfig configMap does not exist yet
 	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(kubeconfigConfigMap), kubeconfigConfigMap); err != nil {
 		if apierrors.IsNotFound(err) {
-			if sufficient, err := r.hasSufficientQuota(ctx, req, "count/configmaps"); err != nil {
-				return ctrl.Result{}, err
+			var sufficient bool
+			if r.getConfig().Controllers.Shoot.QuotaExhaustedPolicy == configv1alpha1.QuotaExhaustedPolicyPrioritize {
+				sufficient, err = r.admitByQuotaPriority(ctx, log, shoot)
+			} else {
+				sufficient, err = hasSufficientConfigMapQuota(ctx, r.Client, shoot.Namespace)
+			}
+
+			if errors.Is(err, util.ErrQuotaStatusOutdated) {
+				log.Info("configMap quota status is not up-to-date, will try again shortly", "reason", err.Error())
+				return ctrl.Result{Requeue: true}, nil, nil
+			} else if err != nil {
+				return ctrl.Result{}, nil, err
 			} else if !sufficient {
 				log.Info("configMap quota is not sufficient, will try again later")
-				return ctrl.Result{RequeueAfter: r.Config.Controllers.Shoot.QuotaExceededRetryDelay}, nil
+
+				return ctrl.Result{RequeueAfter: r.getConfig().Controllers.Shoot.QuotaExceededRetryDelay.Duration}, &reconcileOutcome{
+					outcome:   OutcomeSkipped,
+					eventType: corev1.EventTypeWarning,
+					reason:    EventReasonKubeconfigQuotaExceeded,
+					message:   fmt.Sprintf("configMap quota of namespace %s is exhausted, cannot create configMap %s", shoot.Namespace, kubeconfigConfigMap.Name),
+				}, nil
 			} // else: we got enough configMap quota and can continue
 		} else {
-			return ctrl.Result{}, err
+			return ctrl.Result{}, nil, err
 		}
 	}
 
-	// fetch ShootState
-	shootState := &gardencorev1alpha1.ShootState{}
-
-	if err := r.Get(ctx, req.NamespacedName, shootState); err != nil {
-		if apierrors.IsNotFound(err) {
-			// shootstate does not exist anymore - cleanup kubeconfig configMap
-			return ctrl.Result{}, client.IgnoreNotFound(r.Client.Delete(ctx, kubeconfigConfigMap))
-		}
-		// Error reading the object - requeue the request
-		return ctrl.Result{}, err
+	// read the certificate authority from the configured ca sources.
+	// During a rotation of the certificate authorities the kubeconfig contains the ca bundle with the old and the new certificate authority
+	caCert, err := r.caSource.ClusterCA(ctx, client.ObjectKeyFromObject(shoot), rotationPhase)
+	if errors.Is(err, util.ErrCASourceMissing) {
+		// e.g. the shootstate does not exist anymore - cleanup kubeconfig configMap
+		shootCANotAfterSeconds.DeleteLabelValues(r.gardenLabel(), shoot.Namespace, shoot.Name)
+
+		return ctrl.Result{}, &reconcileOutcome{
+			outcome:   OutcomeSkipped,
+			eventType: corev1.EventTypeWarning,
+			reason:    EventReasonCASourceMissing,
+			message:   fmt.Sprintf("%s, kubeconfig configMap is removed", err),
+		}, client.IgnoreNotFound(r.Client.Delete(ctx, kubeconfigConfigMap))
 	}
 
 	if len(shoot.Status.AdvertisedAddresses) == 0 {
 		// we have a watch on the shoot and changes to the advertised addresses should trigger a new reconcile anyhow so there is no need to requeue it immediately
-		return ctrl.Result{RequeueAfter: 60 * time.Minute}, nil
+		return ctrl.Result{RequeueAfter: 60 * time.Minute}, &reconcileOutcome{
+			outcome:   OutcomeSkipped,
+			eventType: corev1.EventTypeNormal,
+			reason:    EventReasonAdvertisedAddressesMissing,
+			message:   "shoot does not advertise any addresses yet",
+		}, nil
 	}
 
-	caCert, err := clusterCaCert(shootState)
 	if err != nil {
-		return ctrl.Result{}, err
-	}
+		reason := EventReasonCAInvalid
+		if errors.Is(err, util.ErrCANotProvisioned) {
+			reason = EventReasonCANotProvisioned
+		}
 
-	if err = util.ValidateCertificate(caCert); err != nil {
-		return ctrl.Result{}, fmt.Errorf("an error occured validating the ca certificate: %w", err)
+		return ctrl.Result{}, &reconcileOutcome{
+			outcome:   OutcomeFailed,
+			eventType: corev1.EventTypeWarning,
+			reason:    reason,
+			message:   err.Error(),
+		}, err
 	}
 
-	clusterIdentityConfigMap := &corev1.ConfigMap{}
-	key := types.NamespacedName{
-		Name:      corev1beta1constants.ClusterIdentity,
-		Namespace: "kube-system",
+	caCertificates, err := util.ParseCertificates(caCert)
+	if err != nil {
+		err = fmt.Errorf("an error occured validating the ca certificate: %w", err)
+
+		return ctrl.Result{}, &reconcileOutcome{
+			outcome:   OutcomeFailed,
+			eventType: corev1.EventTypeWarning,
+			reason:    EventReasonCAInvalid,
+			message:   err.Error(),
+		}, err
 	}
 
-	if err = r.Client.Get(ctx, key, clusterIdentityConfigMap); err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to fetch garden cluster identity: %w", err)
-	}
+	// the earliest expiry of the certificate authorities, the shoot is reconciled again shortly before it to pick up a refreshed certificate authority
+	caNotAfter := util.EarliestNotAfter(caCertificates)
 
-	if clusterIdentityConfigMap.Data == nil {
-		return ctrl.Result{}, errors.New("cluster identity configMap data not set")
+	gardenClusterIdentity, err := r.gardenClusterIdentity(ctx)
+	if err != nil {
+		if errors.Is(err, errGardenClusterIdentityMissing) {
+			// reported by the readiness check, all shoots are reconciled again once the identity is known
+			log.Info("skipping reconciliation until the garden cluster identity is known", "error", err.Error())
+			return ctrl.Result{}, nil, nil
+		}
+
+		return ctrl.Result{}, nil, err
 	}
 
 	kubeconfigRequest := kubeconfigRequest{
 		namespace:             shoot.Namespace,
 		shootName:             shoot.Name,
-		gardenClusterIdentity: clusterIdentityConfigMap.Data[corev1beta1constants.ClusterIdentity],
+		gardenClusterIdentity: gardenClusterIdentity,
+		exec:                  r.getConfig().Kubeconfig.Exec,
 	}
 
 	for _, address := range shoot.Status.AdvertisedAddresses {
 		u, err := url.Parse(address.URL)
 		if err != nil {
-			return ctrl.Result{}, fmt.Errorf("could not parse shoot server url: %w", err)
+			return ctrl.Result{}, nil, fmt.Errorf("could not parse shoot server url: %w", err)
 		}
 
 		kubeconfigRequest.clusters = append(kubeconfigRequest.clusters, cluster{
@@ -472,108 +824,146 @@ func (r *ShootReconciler) handleRequest(ctx context.Context, req ctrl.Request) (
 	}
 
 	if err = kubeconfigRequest.validate(); err != nil {
-		return ctrl.Result{}, fmt.Errorf("validation failed for kubeconfig request: %w", err)
+		return ctrl.Result{}, nil, fmt.Errorf("validation failed for kubeconfig request: %w", err)
 	}
 
-	// parse kubernetes version to determine if a legacy kubeconfig should be created.
-	c, err := semver.NewConstraint("< v1.20.0")
+	// determine the kubeconfig format, e.g. if a legacy kubeconfig should be created, based on the kubernetes version of the shoot
+	kubeconfigFormat, err := r.getConfig().Kubeconfig.FormatFor(shoot.Spec.Kubernetes.Version)
 	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to parse constraint: %w", err)
+		return ctrl.Result{}, nil, fmt.Errorf("could not determine kubeconfig format of shoot cluster: %w", err)
 	}
 
-	version, err := semver.NewVersion(shoot.Spec.Kubernetes.Version)
+	kubeconfig, err := kubeconfigRequest.generate(kubeconfigFormat)
 	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("could not parse kubernetes version %s of shoot cluster: %w", shoot.Spec.Kubernetes.Version, err)
-	}
-
-	legacy := c.Check(version)
-
-	kubeconfig, err := kubeconfigRequest.generate(legacy)
-	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("generation failed for kubeconfig request: %w", err)
+		return ctrl.Result{}, nil, fmt.Errorf("generation failed for kubeconfig request: %w", err)
 	}
 
 	ownerReference := metav1.NewControllerRef(shoot, gardencorev1beta1.SchemeGroupVersion.WithKind("Shoot"))
 	ownerReference.BlockOwnerDeletion = pointer.BoolPtr(false)
 
 	// store the kubeconfig in a ConfigMap, as it does not contain any credentials or other secret data
-	if _, err = ctrl.CreateOrUpdate(ctx, r.Client, kubeconfigConfigMap, func() error {
+	operationResult, err := ctrl.CreateOrUpdate(ctx, r.Client, kubeconfigConfigMap, func() error {
 		kubeconfigConfigMap.OwnerReferences = []metav1.OwnerReference{*ownerReference}
 
 		if kubeconfigConfigMap.Labels == nil {
 			kubeconfigConfigMap.Labels = make(map[string]string)
 		}
 		kubeconfigConfigMap.Labels[constants.GardenerOperationsRole] = constants.GardenerOperationsKubeconfig
+		// the kubeconfig of a shoot named like an aggregated kubeconfig configMap (e.g. project.kubeconfig) takes precedence
+		delete(kubeconfigConfigMap.Labels, constants.LabelKubeconfigScope)
+
+		metav1.SetMetaDataAnnotation(&kubeconfigConfigMap.ObjectMeta, constants.AnnotationCANotAfter, caNotAfter.UTC().Format(time.RFC3339))
 
 		if kubeconfigConfigMap.Data == nil {
 			kubeconfigConfigMap.Data = make(map[string]string)
 		}
 		kubeconfigConfigMap.Data[constants.DataKeyKubeconfig] = string(kubeconfig)
 		return nil
-	}); err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to create or update kubeconfig configMap %s/%s: %w", kubeconfigConfigMap.Namespace, kubeconfigConfigMap.Name, err)
+	})
+	if err != nil {
+		return ctrl.Result{}, nil, fmt.Errorf("failed to create or update kubeconfig configMap %s/%s: %w", kubeconfigConfigMap.Namespace, kubeconfigConfigMap.Name, err)
 	}
 
-	log.Info("reconciled successfully")
+	if operationResult != controllerutil.OperationResultNone {
+		format := kubeconfigFormatExtension
+		if kubeconfigFormat.Legacy {
+			format = kubeconfigFormatLegacy
+		}
 
-	return ctrl.Result{}, nil
-}
+		kubeconfigsRenderedTotal.WithLabelValues(r.gardenLabel(), format).Inc()
+	}
 
-func (r *ShootReconciler) hasSufficientQuota(ctx context.Context, req ctrl.Request, resourceName corev1.ResourceName) (bool, error) {
-	list := &corev1.ResourceQuotaList{}
+	shootCANotAfterSeconds.WithLabelValues(r.gardenLabel(), shoot.Namespace, shoot.Name).Set(float64(caNotAfter.Unix()))
 
-	err := r.Client.List(ctx, list, client.InNamespace(req.Namespace))
-	if err != nil {
-		return false, err
+	log.Info("reconciled successfully", "caNotAfter", caNotAfter)
+
+	return ctrl.Result{RequeueAfter: caExpiryRequeueAfter(caNotAfter, time.Now())}, &reconcileOutcome{
+		outcome:   OutcomeSucceeded,
+		eventType: corev1.EventTypeNormal,
+		reason:    EventReasonKubeconfigRendered,
+		message:   fmt.Sprintf("kubeconfig configMap %s %s", kubeconfigConfigMap.Name, operationResult),
+		silent:    operationResult == controllerutil.OperationResultNone,
+	}, nil
+}
+
+// gardenLabel returns the value of the garden label of the metrics, which is the name of an additional garden cluster or "main"
+// for the garden cluster of the manager. The label is stable for the lifetime of the reconciler, so that no stale series are left behind,
+// and cheap to compute, as it is also called while the dispatcher holds its lock.
+func (r *ShootReconciler) gardenLabel() string {
+	if r.GardenName != "" {
+		return r.GardenName
 	}
 
-	for _, resourceQuota := range list.Items {
-		if !quotav1.Contains(quotav1.ResourceNames(resourceQuota.Spec.Hard), resourceName) {
-			continue
-		}
+	return mainGardenLabel
+}
 
-		if !quotav1.Contains(quotav1.ResourceNames(resourceQuota.Status.Hard), resourceName) {
-			return false, fmt.Errorf("could not determine hard resource quota status. Status does not seem to be up-to-date")
-		}
+// gardenClusterIdentity returns the configured cluster identity of the garden cluster or, if not configured,
+// the identity of the cluster-identity configMap in the kube-system namespace of the garden cluster, which is read from the dedicated cache.
+// It returns an error wrapping errGardenClusterIdentityMissing in case the configMap does not exist or holds no identity.
+func (r *ShootReconciler) gardenClusterIdentity(ctx context.Context) (string, error) {
+	if r.GardenClusterIdentity != "" {
+		return r.GardenClusterIdentity, nil
+	}
 
-		if !quotav1.Contains(quotav1.ResourceNames(resourceQuota.Status.Used), resourceName) {
-			return false, fmt.Errorf("could not determine used resource quota status. Status does not seem to be up-to-date")
+	clusterIdentityConfigMap := &corev1.ConfigMap{}
+	key := types.NamespacedName{
+		Name:      corev1beta1constants.ClusterIdentity,
+		Namespace: metav1.NamespaceSystem,
+	}
+
+	if err := r.clusterIdentityCache.Get(ctx, key, clusterIdentityConfigMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", fmt.Errorf("%w: configMap %s not found", errGardenClusterIdentityMissing, key)
 		}
 
-		requestedUsage := corev1.ResourceList{resourceName: resource.MustParse("1")}
-		newUsage := quotav1.Add(resourceQuota.Status.Used, requestedUsage)
-		maskedNewUsage := quotav1.Mask(newUsage, quotav1.ResourceNames(requestedUsage))
+		return "", fmt.Errorf("failed to fetch garden cluster identity: %w", err)
+	}
 
-		if allowed, _ := quotav1.LessThanOrEqual(maskedNewUsage, resourceQuota.Status.Hard); !allowed {
-			return false, nil
-		}
+	if clusterIdentityConfigMap.Data[corev1beta1constants.ClusterIdentity] == "" {
+		return "", fmt.Errorf("%w: configMap %s holds no identity", errGardenClusterIdentityMissing, key)
 	}
 
-	return true, nil
+	return clusterIdentityConfigMap.Data[corev1beta1constants.ClusterIdentity], nil
 }
 
-var errCaNotProvisioned = errors.New("certificate authority not yet provisioned")
-
-// clusterCaCert reads the ca certificate from the gardener resource data
-func clusterCaCert(shootState *gardencorev1alpha1.ShootState) ([]byte, error) {
-	resourceDataList := corev1alpha1helper.GardenerResourceDataList(shootState.Spec.Gardener)
+// GardenClusterIdentityCheck is a readiness check that fails in case the garden cluster identity is not known,
+// as no kubeconfig can be rendered without it
+func (r *ShootReconciler) GardenClusterIdentityCheck(req *http.Request) error {
+	_, err := r.gardenClusterIdentity(req.Context())
+	return err
+}
 
-	ca := resourceDataList.Get(corev1beta1constants.SecretNameCACluster)
-	if ca == nil {
-		return nil, errCaNotProvisioned
+// caExpiryRequeueAfter returns the duration after which a shoot with a certificate authority expiring at the given time is reconciled again.
+// The shoot is reconciled caExpiryRequeueLeadTime before the expiry, but not more often than every caExpiryMinRequeueInterval.
+func caExpiryRequeueAfter(caNotAfter time.Time, now time.Time) time.Duration {
+	requeueAfter := caNotAfter.Add(-caExpiryRequeueLeadTime).Sub(now)
+	if requeueAfter < caExpiryMinRequeueInterval {
+		return caExpiryMinRequeueInterval
 	}
 
-	data := make(map[string][]byte)
-	if err := json.Unmarshal(ca.Data.Raw, &data); err != nil {
-		return nil, errors.New("failed to unmarshal certificate authority from raw data")
+	return requeueAfter
+}
+
+// recordOutcome emits an event for the given outcome on the shoot and records the outcome and reason as annotations on the shoot,
+// so that project members can see why the kubeconfig configMap is missing, e.g. with kubectl describe shoot.
+// The shoot is only patched in case the outcome or reason changed, so that repeated reconciliations do not cause Shoot update events.
+func (r *ShootReconciler) recordOutcome(ctx context.Context, log logr.Logger, shoot *gardencorev1beta1.Shoot, outcome *reconcileOutcome) {
+	if !outcome.silent && r.Recorder != nil {
+		r.Recorder.Event(shoot, outcome.eventType, outcome.reason, outcome.message)
 	}
 
-	key := secrets.DataKeyCertificateCA
-	if ca.Type == "certificate" {
-		key = "certificate"
+	if shoot.Annotations[constants.AnnotationLastReconcileOutcome] == outcome.outcome &&
+		shoot.Annotations[constants.AnnotationLastReconcileReason] == outcome.reason {
+		return
 	}
 
-	return data[key], nil
+	patch := client.MergeFrom(shoot.DeepCopy())
+	metav1.SetMetaDataAnnotation(&shoot.ObjectMeta, constants.AnnotationLastReconcileOutcome, outcome.outcome)
+	metav1.SetMetaDataAnnotation(&shoot.ObjectMeta, constants.AnnotationLastReconcileReason, outcome.reason)
+
+	if err := r.Client.Patch(ctx, shoot, patch); err != nil {
+		log.Error(err, "failed to record reconcile outcome on shoot", "outcome", outcome.outcome, "reason", outcome.reason)
+	}
 }
 
 // kubeconfigRequest is a struct which holds information about a Kubeconfig to be generated.
@@ -586,6 +976,8 @@ type kubeconfigRequest struct {
 	shootName string
 	// gardenClusterIdentity is the cluster identifier of the garden cluster.
 	gardenClusterIdentity string
+	// exec defines the command, args, env, install hint and interactive mode of the exec section
+	exec configv1alpha1.ExecConfiguration
 }
 
 // cluster holds the data to describe and connect to a kubernetes cluster
@@ -628,18 +1020,25 @@ func (k *kubeconfigRequest) validate() error {
 		return errors.New("no garden cluster identity defined for kubeconfig request")
 	}
 
+	if k.exec.Command == "" {
+		return errors.New("no exec command defined for kubeconfig request")
+	}
+
 	return nil
 }
 
 // generate generates a Kubernetes kubeconfig for communicating with the kube-apiserver
 // by exec'ing the gardenlogin plugin, which fetches a client certificate.
-// If legacy is false, the shoot reference and garden cluster identity is passed via the cluster extensions,
+// If format.Legacy is false, the shoot reference and garden cluster identity is passed via the cluster extensions,
 // which is supported starting with kubectl version v1.20.0.
-// If legacy is true, the shoot reference and garden cluster identity are passed as command line flags to the plugin
-func (k *kubeconfigRequest) generate(legacy bool) ([]byte, error) {
+// If format.Legacy is true, the shoot reference and garden cluster identity are passed as command line flags to the plugin.
+// The exec section is rendered with the API version format.ExecAPIVersion.
+func (k *kubeconfigRequest) generate(format configv1alpha1.KubeconfigFormat) ([]byte, error) {
 	authName := fmt.Sprintf("%s--%s", k.namespace, k.shootName)
 	name := fmt.Sprintf("%s-%s", authName, k.clusters[0].name)
 
+	legacy := format.Legacy
+
 	var legacyArgs []string
 	if legacy {
 		legacyArgs = []string{
@@ -649,22 +1048,41 @@ func (k *kubeconfigRequest) generate(legacy bool) ([]byte, error) {
 		}
 	}
 
+	var env []clientcmdv1.ExecEnvVar
+	for _, e := range k.exec.Env {
+		env = append(env, clientcmdv1.ExecEnvVar{
+			Name:  e.Name,
+			Value: e.Value,
+		})
+	}
+
+	var args []string
+	args = append(args, k.exec.Args...)
+	args = append(args, legacyArgs...)
+
+	apiVersion := format.ExecAPIVersion
+	if apiVersion == "" {
+		apiVersion = clientauthenticationv1beta1.SchemeGroupVersion.String()
+	}
+
+	interactiveMode := k.exec.InteractiveMode
+	if interactiveMode == "" && apiVersion == clientauthenticationv1.SchemeGroupVersion.String() {
+		// the interactive mode is required for the client.authentication.k8s.io/v1 API
+		interactiveMode = configv1alpha1.ExecInteractiveModeIfAvailable
+	}
+
 	var authInfos []clientcmdv1.NamedAuthInfo
 	authInfos = append(authInfos, clientcmdv1.NamedAuthInfo{
 		Name: authName,
 		AuthInfo: clientcmdv1.AuthInfo{
 			Exec: &clientcmdv1.ExecConfig{
-				Command: "kubectl",
-				Args: append([]string{
-					"gardenlogin",
-					"get-client-certificate",
-				},
-					legacyArgs...,
-				),
-				Env:                nil,
-				APIVersion:         clientauthenticationv1beta1.SchemeGroupVersion.String(),
-				InstallHint:        "",
+				Command:            k.exec.Command,
+				Args:               args,
+				Env:                env,
+				APIVersion:         apiVersion,
+				InstallHint:        k.exec.InstallHint,
 				ProvideClusterInfo: true,
+				InteractiveMode:    clientcmdv1.ExecInteractiveMode(interactiveMode),
 			},
 		},
 	})
@@ -725,57 +1143,48 @@ func (k *kubeconfigRequest) generate(legacy bool) ([]byte, error) {
 	return runtime.Encode(clientcmdlatest.Codec, config)
 }
 
-// getConfig returns the util.ControllerManagerConfiguration of the ShootReconciler
-func (r *ShootReconciler) getConfig() *util.ControllerManagerConfiguration {
+// getConfig returns the configv1alpha1.ControllerManagerConfiguration of the ShootReconciler
+func (r *ShootReconciler) getConfig() *configv1alpha1.ControllerManagerConfiguration {
 	r.configMutex.RLock()
 	defer r.configMutex.RUnlock()
 
 	return r.Config
 }
 
-// injectConfig is mainly used for tests to inject util.ControllerManagerConfiguration configuration
-func (r *ShootReconciler) injectConfig(config *util.ControllerManagerConfiguration) {
+// setResync sets the Resync that is triggered when the kubeconfig section of the configuration changed
+func (r *ShootReconciler) setResync(resync *util.Resync) {
 	r.configMutex.Lock()
 	defer r.configMutex.Unlock()
 
-	r.Config = config
+	r.resync = resync
 }
 
-func (r *ShootReconciler) increaseCounterForNamespace(namespace string) error {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
-	var counter int
-	if c, exists := r.ReconcilerCountPerNamespace[namespace]; !exists {
-		counter = 1
-	} else {
-		counter = c + 1
-	}
+// InjectConfig replaces the configv1alpha1.ControllerManagerConfiguration of the ShootReconciler, e.g. when the configuration file changed.
+// All shoots are reconciled in case the kubeconfig section changed, so that the kubeconfigs are rendered with the new configuration.
+func (r *ShootReconciler) InjectConfig(config *configv1alpha1.ControllerManagerConfiguration) {
+	r.configMutex.Lock()
+	defer r.configMutex.Unlock()
 
-	if counter > r.getConfig().Controllers.Shoot.MaxConcurrentReconcilesPerNamespace {
-		return fmt.Errorf("max count reached")
+	if r.resync != nil && util.KubeconfigChanged(r.Config, config) {
+		r.resync.Trigger()
 	}
 
-	r.ReconcilerCountPerNamespace[namespace] = counter
-
-	return nil
+	r.Config = config
 }
 
-func (r *ShootReconciler) decreaseCounterForNamespace(namespace string) {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
-	var counter int
-
-	c, exists := r.ReconcilerCountPerNamespace[namespace]
-	if !exists {
-		panic("entry expected!")
-	}
-
-	counter = c - 1
-	if counter == 0 {
-		delete(r.ReconcilerCountPerNamespace, namespace)
-	} else {
-		r.ReconcilerCountPerNamespace[namespace] = counter
+// enqueuePendingRequest enqueues the given request to the work queue of the controller, it returns once the context is done
+func (r *ShootReconciler) enqueuePendingRequest(ctx context.Context, req types.NamespacedName) error {
+	select {
+	case r.pendingRequests <- event.GenericEvent{
+		Object: &metav1.PartialObjectMetadata{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      req.Name,
+				Namespace: req.Namespace,
+			},
+		},
+	}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
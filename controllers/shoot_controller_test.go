@@ -7,8 +7,13 @@ SPDX-License-Identifier: Apache-2.0
 package controllers
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"time"
 
 	gardencorev1alpha1 "github.com/gardener/gardener/pkg/apis/core/v1alpha1"
@@ -18,21 +23,34 @@ import (
 	"github.com/gardener/gardener/pkg/utils/gardener"
 	"github.com/gardener/gardener/pkg/utils/secrets"
 	"github.com/gardener/gardener/pkg/utils/test/matchers"
+	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
+	gardenloginv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/gardenlogin/v1alpha1"
 	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1"
 	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1/constants"
 	"github.com/gardener/gardenlogin-controller-manager/internal/test"
+	"github.com/gardener/gardenlogin-controller-manager/internal/util"
 )
 
 var _ = Describe("ShootController", func() {
@@ -55,7 +73,7 @@ var _ = Describe("ShootController", func() {
 	)
 	BeforeEach(func() {
 		cmConfig = test.DefaultConfiguration()
-		shootReconciler.injectConfig(cmConfig)
+		shootReconciler.InjectConfig(cmConfig)
 
 		By("ensuring that required resources for shoot and shootstate exist")
 		Expect(k8sClient.Create(ctx, &gardencorev1beta1.ControllerRegistration{
@@ -350,6 +368,37 @@ var _ = Describe("ShootController", func() {
 				"gardenlogin",
 				"get-client-certificate",
 			}))
+
+			Expect(testutil.ToFloat64(kubeconfigsRenderedTotal.WithLabelValues("main", kubeconfigFormatExtension))).To(BeNumerically(">", 0))
+
+			By("verifying the provenance annotations")
+			configMap := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, configMapKey, configMap)).To(Succeed())
+			Expect(configMap.Annotations).To(HaveKeyWithValue(constants.AnnotationControllerVersion, "v0.0.0-test"))
+			Expect(configMap.Annotations).To(HaveKeyWithValue(constants.AnnotationShootKubernetesVersion, k8sVersion))
+			Expect(configMap.Annotations).To(HaveKeyWithValue(constants.AnnotationKubeconfigFormat, constants.KubeconfigFormatExtension))
+			Expect(configMap.Annotations).To(HaveKeyWithValue(constants.AnnotationKubeconfigExecAPIVersion, "client.authentication.k8s.io/v1beta1"))
+			Expect(configMap.Annotations).To(HaveKey(constants.AnnotationCABundleSHA256))
+			Expect(configMap.Annotations).To(HaveKey(constants.AnnotationGeneratedAt))
+
+			By("verifying the expiry of the certificate authority")
+			caNotAfter := ca.Certificate.NotAfter.UTC().Truncate(time.Second)
+			Expect(configMap.Annotations).To(HaveKeyWithValue(constants.AnnotationCANotAfter, caNotAfter.Format(time.RFC3339)))
+			Expect(testutil.ToFloat64(shootCANotAfterSeconds.WithLabelValues("main", namespace, name))).To(Equal(float64(caNotAfter.Unix())))
+		})
+
+		It("should record the reconcile outcome on the shoot", func() {
+			Eventually(func() map[string]string {
+				s := &gardencorev1beta1.Shoot{}
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(shoot), s); err != nil {
+					return nil
+				}
+
+				return s.Annotations
+			}, timeout, interval).Should(And(
+				HaveKeyWithValue(constants.AnnotationLastReconcileOutcome, OutcomeSucceeded),
+				HaveKeyWithValue(constants.AnnotationLastReconcileReason, EventReasonKubeconfigRendered),
+			))
 		})
 
 		Context("when the ca is stored with type 'certificate' in resource data list", func() {
@@ -388,6 +437,218 @@ var _ = Describe("ShootController", func() {
 			})
 		})
 
+		It("should aggregate the kubeconfig into the project kubeconfig configMap", func() {
+			projectConfigMapKey := types.NamespacedName{Namespace: namespace, Name: constants.ProjectKubeconfigConfigMapName}
+
+			var kubeconfig string
+			Eventually(func() bool {
+				configMap := &corev1.ConfigMap{}
+				if err := k8sClient.Get(ctx, projectConfigMapKey, configMap); err != nil {
+					return false
+				}
+
+				kubeconfig = configMap.Data[constants.DataKeyKubeconfig]
+				return kubeconfig != "" && configMap.Labels[constants.LabelKubeconfigScope] == constants.KubeconfigScopeProject
+			}, timeout, interval).Should(BeTrue())
+
+			rawConfig, err := clientcmd.Load([]byte(kubeconfig))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(rawConfig.Clusters).To(HaveLen(2))
+			Expect(rawConfig.Clusters).To(HaveKey(fmt.Sprintf("%s--%s-shoot-address1", namespace, name)))
+			Expect(rawConfig.Contexts).To(HaveLen(2))
+			Expect(rawConfig.AuthInfos).To(HaveLen(1))
+
+			By("deleting the shoot kubeconfig configMap owner")
+			shootCopy := shoot.DeepCopy()
+			shoot.Annotations = map[string]string{
+				gardener.ConfirmationDeletion: "true",
+			}
+			Expect(k8sClient.Patch(ctx, shoot, client.MergeFrom(shootCopy))).To(Succeed())
+			Expect(k8sClient.Delete(ctx, shoot)).To(Succeed())
+
+			By("verifying that the project kubeconfig configMap is deleted")
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, projectConfigMapKey, &corev1.ConfigMap{})
+				return apierrors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("should aggregate the kubeconfig into a kubeconfig bundle", func() {
+			bundle := &gardenloginv1alpha1.KubeconfigBundle{
+				ObjectMeta: metav1.ObjectMeta{Name: "bundle", Namespace: namespace},
+				Spec: gardenloginv1alpha1.KubeconfigBundleSpec{
+					Namespaces: []string{namespace},
+				},
+			}
+			Expect(k8sClient.Create(ctx, bundle)).To(Succeed())
+
+			By("verifying that the requester is recorded")
+			Expect(bundle.Annotations).To(HaveKey(constants.AnnotationRequestedBy))
+
+			bundleConfigMapKey := types.NamespacedName{Namespace: namespace, Name: "bundle" + BundleKubeconfigConfigMapNameSuffix}
+
+			var kubeconfig string
+			Eventually(func() bool {
+				configMap := &corev1.ConfigMap{}
+				if err := k8sClient.Get(ctx, bundleConfigMapKey, configMap); err != nil {
+					return false
+				}
+
+				kubeconfig = configMap.Data[constants.DataKeyKubeconfig]
+				return configMap.Labels[constants.LabelKubeconfigScope] == constants.KubeconfigScopeBundle && strings.Contains(kubeconfig, name)
+			}, timeout, interval).Should(BeTrue())
+
+			rawConfig, err := clientcmd.Load([]byte(kubeconfig))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rawConfig.Clusters).To(HaveKey(fmt.Sprintf("%s--%s-shoot-address1", namespace, name)))
+
+			By("verifying the status of the bundle")
+			Eventually(func() bool {
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(bundle), bundle); err != nil {
+					return false
+				}
+
+				return meta.IsStatusConditionTrue(bundle.Status.Conditions, gardenloginv1alpha1.KubeconfigBundleConditionReady) && bundle.Status.Shoots == 1
+			}, timeout, interval).Should(BeTrue())
+
+			By("excluding the shoot with a shoot selector")
+			bundleCopy := bundle.DeepCopy()
+			bundle.Spec.ShootSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}}
+			Expect(k8sClient.Patch(ctx, bundle, client.MergeFrom(bundleCopy))).To(Succeed())
+
+			Eventually(func() bool {
+				configMap := &corev1.ConfigMap{}
+				if err := k8sClient.Get(ctx, bundleConfigMapKey, configMap); err != nil {
+					return false
+				}
+
+				return !strings.Contains(configMap.Data[constants.DataKeyKubeconfig], name)
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("should deny kubeconfig bundles of namespaces the requester cannot access", func() {
+			By("allowing the user to create kubeconfig bundles, but not to list the shoots")
+			grantRole(namespace, "bundle-creator", bundleCreatorRules, rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "foo"})
+
+			bundle := &gardenloginv1alpha1.KubeconfigBundle{
+				ObjectMeta: metav1.ObjectMeta{Name: "forbidden", Namespace: namespace},
+				Spec: gardenloginv1alpha1.KubeconfigBundleSpec{
+					Namespaces: []string{namespace},
+				},
+			}
+
+			By("verifying that the webhook denies the bundle")
+			var err error
+			Eventually(func() bool {
+				err = impersonatedClient("foo").Create(ctx, bundle.DeepCopy())
+				// wait until the role binding is effective
+				return err != nil && strings.Contains(err.Error(), fmt.Sprintf("not allowed to list shoots in namespace %s", namespace))
+			}, timeout, interval).Should(BeTrue())
+			Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		})
+
+		It("should deny kubeconfig bundles whose namespace readers cannot access the source namespaces", func() {
+			sourceNamespace := namespace + "-source"
+			Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: sourceNamespace}})).To(Succeed())
+
+			By("allowing the user to create kubeconfig bundles and to list the shoots of both namespaces")
+			foo := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "foo"}
+			grantRole(namespace, "bundle-creator", bundleCreatorRules, foo)
+			grantRole(sourceNamespace, "shoot-lister", shootListerRules, foo)
+
+			By("allowing a group to read the configMaps of the bundle namespace only")
+			grantRole(namespace, "configmap-reader", []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get", "list"}},
+			}, rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.GroupKind, Name: "sre"})
+
+			bundle := &gardenloginv1alpha1.KubeconfigBundle{
+				ObjectMeta: metav1.ObjectMeta{Name: "unauthorized-readers", Namespace: namespace},
+				Spec: gardenloginv1alpha1.KubeconfigBundleSpec{
+					Namespaces: []string{sourceNamespace},
+				},
+			}
+
+			By("verifying that the webhook denies the bundle")
+			var err error
+			Eventually(func() bool {
+				err = impersonatedClient("foo").Create(ctx, bundle.DeepCopy())
+				// wait until the role bindings are effective
+				return err != nil && strings.Contains(err.Error(), fmt.Sprintf("Group sre can read configmaps in namespace %s but not in namespace %s", namespace, sourceNamespace))
+			}, timeout, interval).Should(BeTrue())
+			Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		})
+
+		It("should revoke the kubeconfig bundle once the requester loses access", func() {
+			By("rechecking the authorization of the requester frequently")
+			bundleConfig := cmConfig.DeepCopy()
+			bundleConfig.Controllers.KubeconfigBundle.AuthorizationRecheckInterval = metav1.Duration{Duration: time.Second}
+			bundleReconciler.InjectConfig(bundleConfig)
+			DeferCleanup(bundleReconciler.InjectConfig, cmConfig)
+
+			By("allowing the user to create kubeconfig bundles and to list the shoots")
+			foo := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "foo"}
+			grantRole(namespace, "bundle-creator", bundleCreatorRules, foo)
+			shootLister := grantRole(namespace, "shoot-lister", shootListerRules, foo)
+
+			bundle := &gardenloginv1alpha1.KubeconfigBundle{
+				ObjectMeta: metav1.ObjectMeta{Name: "revoked", Namespace: namespace},
+				Spec: gardenloginv1alpha1.KubeconfigBundleSpec{
+					Namespaces: []string{namespace},
+				},
+			}
+			Eventually(func() error {
+				// wait until the role bindings are effective
+				return impersonatedClient("foo").Create(ctx, bundle)
+			}, timeout, interval).Should(Succeed())
+
+			bundleConfigMapKey := types.NamespacedName{Namespace: namespace, Name: bundle.Name + BundleKubeconfigConfigMapNameSuffix}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, bundleConfigMapKey, &corev1.ConfigMap{})
+			}, timeout, interval).Should(Succeed())
+
+			By("revoking the permission to list the shoots")
+			Expect(k8sClient.Delete(ctx, shootLister)).To(Succeed())
+
+			By("verifying that the bundle kubeconfig is deleted")
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, bundleConfigMapKey, &corev1.ConfigMap{})
+				return apierrors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(bundle), bundle)).To(Succeed())
+			condition := meta.FindStatusCondition(bundle.Status.Conditions, gardenloginv1alpha1.KubeconfigBundleConditionReady)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(gardenloginv1alpha1.KubeconfigBundleReasonForbidden))
+		})
+
+		It("should publish the garden kubeconfig into the project namespace", func() {
+			gardenConfigMapKey := types.NamespacedName{Namespace: namespace, Name: constants.GardenKubeconfigConfigMapName}
+
+			var kubeconfig string
+			Eventually(func() bool {
+				configMap := &corev1.ConfigMap{}
+				if err := k8sClient.Get(ctx, gardenConfigMapKey, configMap); err != nil {
+					return false
+				}
+
+				kubeconfig = configMap.Data[constants.DataKeyKubeconfig]
+				return configMap.Labels[constants.LabelKubeconfigScope] == constants.KubeconfigScopeGarden
+			}, timeout, interval).Should(BeTrue())
+
+			rawConfig, err := clientcmd.Load([]byte(kubeconfig))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rawConfig.Clusters["garden"].Server).To(Equal(cmConfig.Kubeconfig.Garden.Server))
+			Expect(rawConfig.Contexts[rawConfig.CurrentContext].Namespace).To(Equal(namespace))
+
+			By("verifying that the garden kubeconfig is restored")
+			Expect(k8sClient.Delete(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: gardenConfigMapKey.Name, Namespace: namespace}})).To(Succeed())
+			Eventually(func() error {
+				return k8sClient.Get(ctx, gardenConfigMapKey, &corev1.ConfigMap{})
+			}, timeout, interval).Should(Succeed())
+		})
+
 		It("should restore kubeconfig configMap", func() {
 			shoot.Spec.Kubernetes.Version = k8sVersion
 
@@ -397,7 +658,12 @@ var _ = Describe("ShootController", func() {
 			}).Should(Succeed())
 
 			By("changing the kubeconfig")
-			configMap.Data[constants.DataKeyKubeconfig] = "foo-kubeconfig"
+			rawConfig, err := clientcmd.Load([]byte(configMap.Data[constants.DataKeyKubeconfig]))
+			Expect(err).ToNot(HaveOccurred())
+			rawConfig.CurrentContext = ""
+			changedKubeconfig, err := clientcmd.Write(*rawConfig)
+			Expect(err).ToNot(HaveOccurred())
+			configMap.Data[constants.DataKeyKubeconfig] = string(changedKubeconfig)
 			Expect(k8sClient.Update(ctx, configMap)).To(Succeed())
 
 			By("verifying that kubeconfig is restored")
@@ -409,7 +675,131 @@ var _ = Describe("ShootController", func() {
 
 				kubeconfig := configMap.Data[constants.DataKeyKubeconfig]
 				return kubeconfig
-			}, timeout, interval).ShouldNot(Equal("foo-kubeconfig"))
+			}, timeout, interval).ShouldNot(Equal(string(changedKubeconfig)))
+
+			By("verifying that an invalid kubeconfig is rejected")
+			configMap.Data[constants.DataKeyKubeconfig] = "foo-kubeconfig"
+			Expect(k8sClient.Update(ctx, configMap)).To(MatchError(ContainSubstring("could not parse kubeconfig")))
+
+			By("verifying that a kubeconfig pointing to another server is rejected")
+			Expect(k8sClient.Get(ctx, configMapKey, configMap)).To(Succeed())
+			rawConfig, err = clientcmd.Load([]byte(configMap.Data[constants.DataKeyKubeconfig]))
+			Expect(err).ToNot(HaveOccurred())
+			for _, cluster := range rawConfig.Clusters {
+				cluster.Server = "https://foo.example.com"
+			}
+			rogueKubeconfig, err := clientcmd.Write(*rawConfig)
+			Expect(err).ToNot(HaveOccurred())
+			configMap.Data[constants.DataKeyKubeconfig] = string(rogueKubeconfig)
+			Expect(k8sClient.Update(ctx, configMap)).To(MatchError(ContainSubstring("must match one of the advertised addresses of the shoot")))
+		})
+
+		It("should only allow managers and allowlisted users to delete the kubeconfig configMap", func() {
+			gcUser := "system:serviceaccount:kube-system:generic-garbage-collector"
+
+			Eventually(func() error {
+				return k8sClient.Get(ctx, configMapKey, &corev1.ConfigMap{})
+			}, timeout, interval).Should(Succeed())
+
+			By("allowing the users to delete configMaps")
+			role := &rbacv1.Role{
+				ObjectMeta: metav1.ObjectMeta{Name: "configmap-deleter", Namespace: namespace},
+				Rules: []rbacv1.PolicyRule{
+					{
+						APIGroups: []string{""},
+						Resources: []string{"configmaps"},
+						Verbs:     []string{"delete"},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, role)).To(Succeed())
+
+			roleBinding := &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "configmap-deleter", Namespace: namespace},
+				RoleRef: rbacv1.RoleRef{
+					APIGroup: rbacv1.GroupName,
+					Kind:     "Role",
+					Name:     role.Name,
+				},
+				Subjects: []rbacv1.Subject{
+					{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "foo"},
+					{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: gcUser},
+				},
+			}
+			Expect(k8sClient.Create(ctx, roleBinding)).To(Succeed())
+
+			clientFor := func(user string) client.Client {
+				impersonatedConfig := rest.CopyConfig(k8sManager.GetConfig())
+				impersonatedConfig.Impersonate = rest.ImpersonationConfig{UserName: user}
+				impersonatedClient, err := client.New(impersonatedConfig, client.Options{Scheme: k8sManager.GetScheme()})
+				Expect(err).ToNot(HaveOccurred())
+
+				return impersonatedClient
+			}
+
+			By("verifying that a user without the permission to manage configMaps cannot delete the configMap")
+			var err error
+			Eventually(func() bool {
+				err = clientFor("foo").Delete(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: configMapKey.Name, Namespace: namespace}})
+				// wait until the role binding is effective
+				return err != nil && strings.Contains(err.Error(), "not allowed to manage configmaps")
+			}, timeout, interval).Should(BeTrue())
+			Expect(apierrors.IsForbidden(err)).To(BeTrue())
+
+			By("verifying that the garbage collector can delete the configMap")
+			Eventually(func() error {
+				return clientFor(gcUser).Delete(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: configMapKey.Name, Namespace: namespace}})
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("should only allow managers to remove the kubeconfig role label", func() {
+			configMap := &corev1.ConfigMap{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, configMapKey, configMap)
+			}, timeout, interval).Should(Succeed())
+
+			By("allowing the user to update configMaps")
+			role := &rbacv1.Role{
+				ObjectMeta: metav1.ObjectMeta{Name: "configmap-updater", Namespace: namespace},
+				Rules: []rbacv1.PolicyRule{
+					{
+						APIGroups: []string{""},
+						Resources: []string{"configmaps"},
+						Verbs:     []string{"get", "update", "patch"},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, role)).To(Succeed())
+
+			roleBinding := &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "configmap-updater", Namespace: namespace},
+				RoleRef: rbacv1.RoleRef{
+					APIGroup: rbacv1.GroupName,
+					Kind:     "Role",
+					Name:     role.Name,
+				},
+				Subjects: []rbacv1.Subject{
+					{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "foo"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, roleBinding)).To(Succeed())
+
+			impersonatedConfig := rest.CopyConfig(k8sManager.GetConfig())
+			impersonatedConfig.Impersonate = rest.ImpersonationConfig{UserName: "foo"}
+			impersonatedClient, err := client.New(impersonatedConfig, client.Options{Scheme: k8sManager.GetScheme()})
+			Expect(err).ToNot(HaveOccurred())
+
+			By("verifying that a user without the permission to manage configMaps cannot remove the label")
+			Eventually(func() bool {
+				patch := client.MergeFrom(configMap.DeepCopy())
+				unlabelled := configMap.DeepCopy()
+				delete(unlabelled.Labels, constants.GardenerOperationsRole)
+
+				err = impersonatedClient.Patch(ctx, unlabelled, patch)
+				// wait until the role binding is effective
+				return err != nil && strings.Contains(err.Error(), "required for removing the kubeconfig role label")
+			}, timeout, interval).Should(BeTrue())
+			Expect(apierrors.IsForbidden(err)).To(BeTrue())
 		})
 
 		It("should delete kubeconfig configMap", func() {
@@ -438,6 +828,9 @@ var _ = Describe("ShootController", func() {
 				err := k8sClient.Get(ctx, configMapKey, &corev1.ConfigMap{})
 				return apierrors.IsNotFound(err)
 			}, timeout, interval).Should(BeTrue())
+
+			By("verifying that the ca expiry series of the shoot is deleted")
+			Expect(shootCANotAfterSeconds.DeleteLabelValues("main", namespace, name)).To(BeFalse())
 		})
 
 		It("should not delete kubeconfig configMap when shoot deletion timestamp is set", func() {
@@ -505,8 +898,24 @@ var _ = Describe("ShootController", func() {
 				usedQuota = "2"
 				withResourceQuota = true
 
-				cmConfig.Controllers.Shoot.QuotaExceededRetryDelay = 50 * time.Millisecond
-				shootReconciler.injectConfig(cmConfig)
+				cmConfig.Controllers.Shoot.QuotaExceededRetryDelay = metav1.Duration{Duration: 50 * time.Millisecond}
+				shootReconciler.InjectConfig(cmConfig)
+			})
+
+			It("should record that the configMap quota is exceeded", func() {
+				Eventually(func() map[string]string {
+					s := &gardencorev1beta1.Shoot{}
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(shoot), s); err != nil {
+						return nil
+					}
+
+					return s.Annotations
+				}, timeout, interval).Should(And(
+					HaveKeyWithValue(constants.AnnotationLastReconcileOutcome, OutcomeSkipped),
+					HaveKeyWithValue(constants.AnnotationLastReconcileReason, EventReasonKubeconfigQuotaExceeded),
+				))
+
+				Expect(testutil.ToFloat64(reconcileSkipsTotal.WithLabelValues("main", EventReasonKubeconfigQuotaExceeded))).To(BeNumerically(">", 0))
 			})
 
 			It("should create kubeconfig configMap after quota increase", func() {
@@ -573,6 +982,153 @@ var _ = Describe("ShootController", func() {
 					return kubeconfig != ""
 				}).Should(BeTrue())
 			})
+
+			Context("prioritize policy", func() {
+				var orphan *corev1.ConfigMap
+
+				BeforeEach(func() {
+					cmConfig.Controllers.Shoot.QuotaExhaustedPolicy = configv1alpha1.QuotaExhaustedPolicyPrioritize
+					shootReconciler.InjectConfig(cmConfig)
+
+					orphan = &corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "deleted-shoot.kubeconfig",
+							Namespace: namespace,
+							Labels: map[string]string{
+								constants.GardenerOperationsRole: constants.GardenerOperationsKubeconfig,
+							},
+							OwnerReferences: []metav1.OwnerReference{{
+								APIVersion: gardencorev1beta1.SchemeGroupVersion.String(),
+								Kind:       "Shoot",
+								Name:       "deleted-shoot",
+								UID:        "deleted-shoot-uid",
+								Controller: pointer.BoolPtr(true),
+							}},
+						},
+						Data: map[string]string{
+							constants.DataKeyKubeconfig: "apiVersion: v1\nkind: Config\n",
+						},
+					}
+					Expect(k8sClient.Create(ctx, orphan)).To(Succeed())
+				})
+
+				AfterEach(func() {
+					cmConfig.Controllers.Shoot.QuotaExhaustedPolicy = configv1alpha1.QuotaExhaustedPolicyWait
+					shootReconciler.InjectConfig(cmConfig)
+				})
+
+				It("should garbage collect orphaned kubeconfig configMaps", func() {
+					Eventually(func() bool {
+						return apierrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(orphan), &corev1.ConfigMap{}))
+					}, timeout, interval).Should(BeTrue())
+				})
+			})
+		})
+
+		Context("custom exec configuration", func() {
+			BeforeEach(func() {
+				cmConfig.Kubeconfig.Exec = configv1alpha1.ExecConfiguration{
+					Standalone:      true,
+					Command:         "kubectl-gardenlogin",
+					Args:            []string{"get-client-certificate"},
+					Env:             []configv1alpha1.ExecEnvVar{{Name: "FOO", Value: "bar"}},
+					InstallHint:     "install the gardenlogin plugin",
+					InteractiveMode: configv1alpha1.ExecInteractiveModeNever,
+				}
+				shootReconciler.InjectConfig(cmConfig)
+			})
+
+			It("should render the configured exec section", func() {
+				var kubeconfig string
+				Eventually(func() bool {
+					configMap := &corev1.ConfigMap{}
+					err := k8sClient.Get(ctx, configMapKey, configMap)
+					if err != nil {
+						return false
+					}
+
+					kubeconfig = configMap.Data[constants.DataKeyKubeconfig]
+					return kubeconfig != ""
+				}, timeout, interval).Should(BeTrue())
+
+				clientConfig, err := clientcmd.NewClientConfigFromBytes([]byte(kubeconfig))
+				Expect(err).ToNot(HaveOccurred())
+
+				rawConfig, err := clientConfig.RawConfig()
+				Expect(err).ToNot(HaveOccurred())
+
+				currentAuthInfo := rawConfig.Contexts[rawConfig.CurrentContext].AuthInfo
+				exec := rawConfig.AuthInfos[currentAuthInfo].Exec
+				Expect(exec.Command).To(Equal("kubectl-gardenlogin"))
+				Expect(exec.Args).To(Equal([]string{"get-client-certificate"}))
+				Expect(exec.Env).To(ConsistOf(clientcmdapi.ExecEnvVar{Name: "FOO", Value: "bar"}))
+				Expect(exec.InstallHint).To(Equal("install the gardenlogin plugin"))
+				Expect(exec.InteractiveMode).To(Equal(clientcmdapi.NeverExecInteractiveMode))
+			})
+
+			It("should render the kubeconfig again once the exec configuration changed", func() {
+				installHint := func() string {
+					configMap := &corev1.ConfigMap{}
+					if err := k8sClient.Get(ctx, configMapKey, configMap); err != nil {
+						return ""
+					}
+
+					rawConfig, err := clientcmd.Load([]byte(configMap.Data[constants.DataKeyKubeconfig]))
+					if err != nil {
+						return ""
+					}
+
+					return rawConfig.AuthInfos[rawConfig.Contexts[rawConfig.CurrentContext].AuthInfo].Exec.InstallHint
+				}
+				Eventually(installHint, timeout, interval).Should(Equal("install the gardenlogin plugin"))
+
+				By("changing the exec configuration without touching the shoot")
+				changedConfig := cmConfig.DeepCopy()
+				changedConfig.Kubeconfig.Exec.InstallHint = "install the gardenlogin plugin, see https://github.com/gardener/gardenlogin"
+				shootReconciler.InjectConfig(changedConfig)
+
+				Eventually(installHint, timeout, interval).Should(Equal("install the gardenlogin plugin, see https://github.com/gardener/gardenlogin"))
+			})
+		})
+
+		Context("client.authentication.k8s.io/v1 kubeconfig", func() {
+			BeforeEach(func() {
+				cmConfig.Kubeconfig.Formats = []configv1alpha1.KubeconfigFormat{
+					{
+						KubernetesVersionConstraint: ">= v1.20.0",
+						ExecAPIVersion:              "client.authentication.k8s.io/v1",
+					},
+				}
+				shootReconciler.InjectConfig(cmConfig)
+			})
+
+			It("should render the exec section with the configured API version", func() {
+				var kubeconfig string
+				Eventually(func() bool {
+					configMap := &corev1.ConfigMap{}
+					err := k8sClient.Get(ctx, configMapKey, configMap)
+					if err != nil {
+						return false
+					}
+
+					kubeconfig = configMap.Data[constants.DataKeyKubeconfig]
+					return kubeconfig != ""
+				}, timeout, interval).Should(BeTrue())
+
+				clientConfig, err := clientcmd.NewClientConfigFromBytes([]byte(kubeconfig))
+				Expect(err).ToNot(HaveOccurred())
+
+				rawConfig, err := clientConfig.RawConfig()
+				Expect(err).ToNot(HaveOccurred())
+
+				currentCluster := rawConfig.Contexts[rawConfig.CurrentContext].Cluster
+				Expect(rawConfig.Clusters[currentCluster].Extensions).ToNot(BeEmpty())
+
+				currentAuthInfo := rawConfig.Contexts[rawConfig.CurrentContext].AuthInfo
+				exec := rawConfig.AuthInfos[currentAuthInfo].Exec
+				Expect(exec.APIVersion).To(Equal("client.authentication.k8s.io/v1"))
+				Expect(exec.InteractiveMode).To(Equal(clientcmdapi.IfAvailableExecInteractiveMode))
+			})
 		})
 
 		Context("legacy kubeconfig", func() {
@@ -618,12 +1174,289 @@ var _ = Describe("ShootController", func() {
 					fmt.Sprintf("--namespace=%s", namespace),
 					"--garden-cluster-identity=envtest",
 				}))
+
+				Expect(testutil.ToFloat64(kubeconfigsRenderedTotal.WithLabelValues("main", kubeconfigFormatLegacy))).To(BeNumerically(">", 0))
 			})
 		})
 	})
 
 })
 
+// patchCountingClient counts the patches sent to the API server
+type patchCountingClient struct {
+	client.Client
+	patches int
+}
+
+func (c *patchCountingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	c.patches++
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+var (
+	// bundleCreatorRules allow to create kubeconfig bundles
+	bundleCreatorRules = []rbacv1.PolicyRule{
+		{APIGroups: []string{gardenloginv1alpha1.GroupVersion.Group}, Resources: []string{"kubeconfigbundles"}, Verbs: []string{"create"}},
+	}
+	// shootListerRules allow to list shoots
+	shootListerRules = []rbacv1.PolicyRule{
+		{APIGroups: []string{gardencorev1beta1.SchemeGroupVersion.Group}, Resources: []string{"shoots"}, Verbs: []string{"list"}},
+	}
+)
+
+// grantRole creates a role with the given name and rules in the given namespace and binds it to the given subjects.
+// It returns the role binding, so that the permissions can be revoked again.
+func grantRole(namespace string, name string, rules []rbacv1.PolicyRule, subjects ...rbacv1.Subject) *rbacv1.RoleBinding {
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Rules:      rules,
+	}
+	Expect(k8sClient.Create(ctx, role)).To(Succeed())
+
+	roleBinding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     role.Name,
+		},
+		Subjects: subjects,
+	}
+	Expect(k8sClient.Create(ctx, roleBinding)).To(Succeed())
+
+	return roleBinding
+}
+
+// impersonatedClient returns a client that impersonates the given user
+func impersonatedClient(user string) client.Client {
+	impersonatedConfig := rest.CopyConfig(k8sManager.GetConfig())
+	impersonatedConfig.Impersonate = rest.ImpersonationConfig{UserName: user}
+
+	c, err := client.New(impersonatedConfig, client.Options{Scheme: k8sManager.GetScheme()})
+	Expect(err).ToNot(HaveOccurred())
+
+	return c
+}
+
+var _ = Describe("#recordOutcome", func() {
+	var (
+		ctx        context.Context
+		c          *patchCountingClient
+		reconciler *ShootReconciler
+		shoot      *gardencorev1beta1.Shoot
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		shoot = &gardencorev1beta1.Shoot{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "garden-foo"}}
+
+		scheme := runtime.NewScheme()
+		Expect(gardencorev1beta1.AddToScheme(scheme)).To(Succeed())
+
+		c = &patchCountingClient{Client: fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(shoot).Build()}
+		reconciler = &ShootReconciler{Client: c, Recorder: record.NewFakeRecorder(10)}
+	})
+
+	It("should only patch the shoot in case the outcome changed", func() {
+		rendered := &reconcileOutcome{outcome: OutcomeSucceeded, eventType: corev1.EventTypeNormal, reason: EventReasonKubeconfigRendered}
+
+		reconciler.recordOutcome(ctx, logr.Discard(), shoot, rendered)
+		reconciler.recordOutcome(ctx, logr.Discard(), shoot, rendered)
+		Expect(c.patches).To(Equal(1))
+
+		reconciler.recordOutcome(ctx, logr.Discard(), shoot, &reconcileOutcome{outcome: OutcomeSkipped, eventType: corev1.EventTypeWarning, reason: EventReasonKubeconfigQuotaExceeded})
+		Expect(c.patches).To(Equal(2))
+		Expect(shoot.Annotations).To(HaveKeyWithValue(constants.AnnotationLastReconcileReason, EventReasonKubeconfigQuotaExceeded))
+	})
+})
+
+var _ = Describe("#gardenLabel", func() {
+	It("should return the name of an additional garden cluster", func() {
+		Expect((&ShootReconciler{GardenName: "dev", GardenClusterIdentity: "dev-identity"}).gardenLabel()).To(Equal("dev"))
+	})
+
+	It("should return main for the garden cluster of the manager regardless of its identity", func() {
+		Expect((&ShootReconciler{GardenClusterIdentity: "landscape-dev"}).gardenLabel()).To(Equal("main"))
+		Expect((&ShootReconciler{}).gardenLabel()).To(Equal("main"))
+	})
+})
+
+var _ = Describe("#countOutcome", func() {
+	It("should count every reconciliation that did not render a kubeconfig as skip per garden cluster", func() {
+		reconciler := &ShootReconciler{GardenName: "count-outcome"}
+		skips := func(reason string) float64 {
+			return testutil.ToFloat64(reconcileSkipsTotal.WithLabelValues("count-outcome", reason))
+		}
+
+		reconciler.countOutcome(&reconcileOutcome{outcome: OutcomeFailed, reason: EventReasonCAInvalid})
+		reconciler.countOutcome(&reconcileOutcome{outcome: OutcomeSkipped, reason: EventReasonAdvertisedAddressesMissing})
+		reconciler.countOutcome(&reconcileOutcome{outcome: OutcomeSucceeded, reason: EventReasonKubeconfigRendered})
+
+		Expect(skips(EventReasonCAInvalid)).To(Equal(1.0))
+		Expect(skips(EventReasonAdvertisedAddressesMissing)).To(Equal(1.0))
+		Expect(skips(EventReasonKubeconfigRendered)).To(BeZero())
+	})
+})
+
+var _ = Describe("#sortShootsByPriority", func() {
+	It("should sort production shoots first and then by creation time", func() {
+		now := metav1.Now()
+		earlier := metav1.NewTime(now.Add(-time.Hour))
+		production := gardencorev1beta1.ShootPurposeProduction
+		development := gardencorev1beta1.ShootPurposeDevelopment
+
+		shoots := []gardencorev1beta1.Shoot{
+			{ObjectMeta: metav1.ObjectMeta{Name: "dev-new", CreationTimestamp: now}, Spec: gardencorev1beta1.ShootSpec{Purpose: &development}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "prod-new", CreationTimestamp: now}, Spec: gardencorev1beta1.ShootSpec{Purpose: &production}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "none-old", CreationTimestamp: earlier}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "prod-old", CreationTimestamp: earlier}, Spec: gardencorev1beta1.ShootSpec{Purpose: &production}},
+		}
+
+		sortShootsByPriority(shoots)
+
+		var names []string
+		for _, shoot := range shoots {
+			names = append(names, shoot.Name)
+		}
+
+		Expect(names).To(Equal([]string{"prod-old", "prod-new", "none-old", "dev-new"}))
+	})
+})
+
+var _ = Describe("#admitByQuotaPriority", func() {
+	var (
+		ctx        context.Context
+		recorder   *record.FakeRecorder
+		reconciler *ShootReconciler
+		namespace  string
+		production gardencorev1beta1.ShootPurpose
+	)
+
+	newShoot := func(name string, created time.Time, purpose *gardencorev1beta1.ShootPurpose, addresses ...string) *gardencorev1beta1.Shoot {
+		shoot := &gardencorev1beta1.Shoot{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, CreationTimestamp: metav1.NewTime(created)},
+			Spec:       gardencorev1beta1.ShootSpec{Purpose: purpose},
+		}
+
+		for _, address := range addresses {
+			shoot.Status.AdvertisedAddresses = append(shoot.Status.AdvertisedAddresses, gardencorev1beta1.ShootAdvertisedAddress{Name: "external", URL: address})
+		}
+
+		return shoot
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		recorder = record.NewFakeRecorder(10)
+		namespace = "garden-quota"
+		production = gardencorev1beta1.ShootPurposeProduction
+	})
+
+	build := func(objs ...client.Object) {
+		quota := &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: namespace},
+			Spec:       corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{"count/configmaps": resource.MustParse("1")}},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{"count/configmaps": resource.MustParse("1")},
+				Used: corev1.ResourceList{"count/configmaps": resource.MustParse("0")},
+			},
+		}
+
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(gardencorev1beta1.AddToScheme(scheme)).To(Succeed())
+
+		reconciler = &ShootReconciler{
+			Client:   fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(append(objs, quota)...).Build(),
+			Recorder: recorder,
+		}
+	}
+
+	It("should not hand the quota to shoots that do not advertise any addresses", func() {
+		now := time.Now()
+		unrenderable := newShoot("prod", now.Add(-time.Hour), &production)
+		shoot := newShoot("dev", now, nil, "https://api.dev.example.com")
+		build(unrenderable, shoot)
+
+		Expect(reconciler.admitByQuotaPriority(ctx, logr.Discard(), shoot)).To(BeTrue())
+	})
+
+	It("should not hand the quota to shoots whose kubeconfig could not be rendered", func() {
+		now := time.Now()
+		unrenderable := newShoot("prod", now.Add(-time.Hour), &production, "https://api.prod.example.com")
+		shoot := newShoot("dev", now, nil, "https://api.dev.example.com")
+		build(unrenderable, shoot)
+
+		Expect(reconciler.admitByQuotaPriority(ctx, logr.Discard(), shoot)).To(BeFalse())
+
+		reconciler.quota.setUnrenderable(client.ObjectKeyFromObject(unrenderable), true)
+		Expect(reconciler.admitByQuotaPriority(ctx, logr.Discard(), shoot)).To(BeTrue())
+
+		By("ensuring that the unrenderable shoot is still admitted in its own reconciliation")
+		Expect(reconciler.admitByQuotaPriority(ctx, logr.Discard(), unrenderable)).To(BeTrue())
+	})
+
+	It("should report the waiting shoots only once per namespace", func() {
+		now := time.Now()
+		first := newShoot("first", now.Add(-time.Hour), nil, "https://api.first.example.com")
+		second := newShoot("second", now, nil, "https://api.second.example.com")
+		build(first, second)
+
+		Expect(reconciler.admitByQuotaPriority(ctx, logr.Discard(), second)).To(BeFalse())
+		Expect(reconciler.admitByQuotaPriority(ctx, logr.Discard(), second)).To(BeFalse())
+
+		Expect(recorder.Events).To(HaveLen(1))
+		Expect(<-recorder.Events).To(ContainSubstring(EventReasonKubeconfigsWaitingForQuota))
+	})
+})
+
+var _ = Describe("#caExpiryRequeueAfter", func() {
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	It("should requeue shortly before the certificate authority expires", func() {
+		Expect(caExpiryRequeueAfter(now.Add(30*24*time.Hour), now)).To(Equal(29 * 24 * time.Hour))
+	})
+
+	It("should not requeue more often than the minimum interval", func() {
+		Expect(caExpiryRequeueAfter(now.Add(time.Hour), now)).To(Equal(caExpiryMinRequeueInterval))
+		Expect(caExpiryRequeueAfter(now.Add(-time.Hour), now)).To(Equal(caExpiryMinRequeueInterval))
+	})
+})
+
+var _ = Describe("#clusterIdentityPredicate", func() {
+	clusterIdentity := func(identity string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: corev1beta1constants.ClusterIdentity, Namespace: metav1.NamespaceSystem},
+			Data:       map[string]string{corev1beta1constants.ClusterIdentity: identity},
+		}
+	}
+
+	It("should reconcile all shoots once the identity is known or changed", func() {
+		p := clusterIdentityPredicate()
+
+		Expect(p.Create(event.CreateEvent{Object: clusterIdentity("landscape")})).To(BeTrue())
+		Expect(p.Update(event.UpdateEvent{ObjectOld: clusterIdentity("landscape"), ObjectNew: clusterIdentity("landscape-dev")})).To(BeTrue())
+	})
+
+	It("should ignore updates that do not change the identity and deletions", func() {
+		p := clusterIdentityPredicate()
+
+		updated := clusterIdentity("landscape")
+		updated.Labels = map[string]string{"foo": "bar"}
+
+		Expect(p.Update(event.UpdateEvent{ObjectOld: clusterIdentity("landscape"), ObjectNew: updated})).To(BeFalse())
+		Expect(p.Delete(event.DeleteEvent{Object: clusterIdentity("landscape")})).To(BeFalse())
+	})
+})
+
+var _ = Describe("#GardenClusterIdentityCheck", func() {
+	It("should succeed for a configured garden cluster identity", func() {
+		reconciler := &ShootReconciler{GardenClusterIdentity: "landscape-dev"}
+
+		Expect(reconciler.GardenClusterIdentityCheck(httptest.NewRequest(http.MethodGet, "/readyz", nil))).To(Succeed())
+	})
+})
+
 func generateCaCert() *secrets.Certificate {
 	csc := &secrets.CertificateSecretConfig{
 		Name:       "ca-test",
@@ -635,3 +1468,124 @@ func generateCaCert() *secrets.Certificate {
 
 	return caCertificate
 }
+
+var _ = Describe("ShootReconciler certificate authorities rotation", func() {
+	var (
+		ctx        context.Context
+		c          client.Client
+		reconciler *ShootReconciler
+		shoot      *gardencorev1beta1.Shoot
+		shootState *gardencorev1alpha1.ShootState
+		oldCA      []byte
+		newCA      []byte
+	)
+
+	generateCA := func(name string) []byte {
+		csc := &secrets.CertificateSecretConfig{Name: name, CommonName: name, CertType: secrets.CACert}
+		cert, err := csc.GenerateCertificate()
+		Expect(err).ToNot(HaveOccurred())
+
+		return cert.CertificatePEM
+	}
+
+	resourceData := func(name string, data map[string][]byte) gardencorev1alpha1.GardenerResourceData {
+		raw, err := json.Marshal(data)
+		Expect(err).ToNot(HaveOccurred())
+
+		return gardencorev1alpha1.GardenerResourceData{Name: name, Type: "secret", Data: runtime.RawExtension{Raw: raw}}
+	}
+
+	// shootWithPhase returns the shoot as unstructured object, as it is read by the reconciler, in the given phase of the rotation
+	shootWithPhase := func(phase string) *unstructured.Unstructured {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(shoot)
+		Expect(err).ToNot(HaveOccurred())
+
+		obj := &unstructured.Unstructured{Object: content}
+		obj.SetGroupVersionKind(gardencorev1beta1.SchemeGroupVersion.WithKind("Shoot"))
+
+		if phase != "" {
+			Expect(unstructured.SetNestedField(obj.Object, phase, "status", "credentials", "rotation", "certificateAuthorities", "phase")).To(Succeed())
+		}
+
+		return obj
+	}
+
+	// setShootState updates the certificate authority and the ca bundle of the ShootState, as done by gardener during the rotation
+	setShootState := func(ca []byte, bundle ...[]byte) {
+		shootState.Spec.Gardener = []gardencorev1alpha1.GardenerResourceData{
+			resourceData("ca", map[string][]byte{"ca.crt": ca}),
+			resourceData("ca-bundle", map[string][]byte{"bundle.crt": bytes.Join(bundle, nil)}),
+		}
+		Expect(c.Update(ctx, shootState)).To(Succeed())
+	}
+
+	// reconcileInPhase reconciles the shoot in the given phase of the rotation and returns the certificate-authority-data of the kubeconfig
+	reconcileInPhase := func(phase string) []byte {
+		obj := shootWithPhase(phase)
+
+		s, err := util.ShootFromUnstructured(obj)
+		Expect(err).ToNot(HaveOccurred())
+
+		kubeconfigConfigMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: shoot.Name + KubeconfigConfigMapNameSuffix, Namespace: shoot.Namespace}}
+		_, _, err = reconciler.reconcileKubeconfig(ctx, logr.Discard(), s, util.CARotationPhase(obj), kubeconfigConfigMap)
+		Expect(err).ToNot(HaveOccurred())
+
+		kubeconfig, err := clientcmd.Load([]byte(kubeconfigConfigMap.Data[constants.DataKeyKubeconfig]))
+		Expect(err).ToNot(HaveOccurred())
+
+		return kubeconfig.Clusters[kubeconfig.Contexts[kubeconfig.CurrentContext].Cluster].CertificateAuthorityData
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		oldCA = generateCA("old")
+		newCA = generateCA("new")
+
+		shoot = &gardencorev1beta1.Shoot{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "garden-foo", UID: "d5b5ee8b-5d32-4c6c-8fb2-b8a8b4e5c6a1"},
+			Spec:       gardencorev1beta1.ShootSpec{Kubernetes: gardencorev1beta1.Kubernetes{Version: "1.22.2"}},
+			Status: gardencorev1beta1.ShootStatus{
+				AdvertisedAddresses: []gardencorev1beta1.ShootAdvertisedAddress{{Name: "external", URL: "https://api.foo.example.com"}},
+			},
+		}
+		shootState = &gardencorev1alpha1.ShootState{ObjectMeta: metav1.ObjectMeta{Name: shoot.Name, Namespace: shoot.Namespace}}
+
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(gardencorev1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		c = fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(shootState).Build()
+		reconciler = &ShootReconciler{
+			Client:                c,
+			Log:                   logr.Discard(),
+			Config:                test.DefaultConfiguration(),
+			GardenClusterIdentity: "landscape",
+			caSource:              &util.ShootStateCASource{Reader: c},
+		}
+	})
+
+	It("should publish the ca bundle while the certificate authorities are rotated", func() {
+		By("publishing the certificate authority before the rotation")
+		setShootState(oldCA, oldCA)
+		Expect(reconcileInPhase("")).To(Equal(oldCA))
+
+		By("publishing the ca bundle once the rotation is prepared")
+		setShootState(oldCA, oldCA, newCA)
+		Expect(reconciler.shootPredicate().Update(event.UpdateEvent{ObjectOld: shootWithPhase(""), ObjectNew: shootWithPhase(util.CARotationPhasePreparing)})).To(BeTrue())
+		Expect(reconcileInPhase(util.CARotationPhasePreparing)).To(Equal(append(oldCA, newCA...)))
+
+		By("keeping the ca bundle while the rotation is completed, although the ShootState holds the new certificate authority")
+		setShootState(newCA, oldCA, newCA)
+		Expect(reconciler.shootPredicate().Update(event.UpdateEvent{ObjectOld: shootWithPhase(util.CARotationPhasePreparing), ObjectNew: shootWithPhase(util.CARotationPhaseCompleting)})).To(BeTrue())
+		Expect(reconcileInPhase(util.CARotationPhaseCompleting)).To(Equal(append(oldCA, newCA...)))
+
+		By("publishing the new certificate authority once the rotation is completed")
+		setShootState(newCA, newCA)
+		Expect(reconciler.shootPredicate().Update(event.UpdateEvent{ObjectOld: shootWithPhase(util.CARotationPhaseCompleting), ObjectNew: shootWithPhase("Completed")})).To(BeTrue())
+		Expect(reconcileInPhase("Completed")).To(Equal(newCA))
+	})
+
+	It("should ignore updates that change neither the advertised addresses nor the rotation phase", func() {
+		Expect(reconciler.shootPredicate().Update(event.UpdateEvent{ObjectOld: shootWithPhase(util.CARotationPhasePrepared), ObjectNew: shootWithPhase(util.CARotationPhasePrepared)})).To(BeFalse())
+	})
+})
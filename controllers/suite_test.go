@@ -18,8 +18,8 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
 	"github.com/gardener/gardenlogin-controller-manager/internal/test"
-	"github.com/gardener/gardenlogin-controller-manager/internal/util"
 	"github.com/gardener/gardenlogin-controller-manager/webhooks"
 )
 
@@ -32,14 +32,17 @@ const (
 )
 
 var (
-	k8sClient       client.Client
-	testEnv         *gardenenvtest.GardenerTestEnvironment
-	ctx             context.Context
-	cancel          context.CancelFunc
-	k8sManager      ctrl.Manager
-	cmConfig        *util.ControllerManagerConfiguration
-	validator       *webhooks.ConfigmapValidator
-	shootReconciler *ShootReconciler
+	k8sClient         client.Client
+	testEnv           *gardenenvtest.GardenerTestEnvironment
+	ctx               context.Context
+	cancel            context.CancelFunc
+	k8sManager        ctrl.Manager
+	cmConfig          *configv1alpha1.ControllerManagerConfiguration
+	validator         *webhooks.ConfigmapValidator
+	shootReconciler   *ShootReconciler
+	projectReconciler *ProjectReconciler
+	bundleReconciler  *KubeconfigBundleReconciler
+	gardenReconciler  *GardenReconciler
 )
 
 // TODO rename file to controllers_suite_test.go
@@ -60,21 +63,58 @@ var _ = BeforeSuite(func() {
 		Config: cmConfig,
 	}
 
-	environment := test.New(validator)
+	mutator := &webhooks.ConfigmapMutator{
+		Log:     ctrl.Log.WithName("webhooks").WithName("ConfigmapMutation"),
+		Version: "v0.0.0-test",
+	}
+
+	bundleMutator := &webhooks.KubeconfigBundleMutator{
+		Log: ctrl.Log.WithName("webhooks").WithName("KubeconfigBundleMutation"),
+	}
+
+	environment := test.New(validator, mutator, bundleMutator)
 	testEnv = environment.GardenEnv
 	k8sManager = environment.K8sManager
 	k8sClient = environment.K8sClient
 
 	shootReconciler = &ShootReconciler{
-		Client:                      k8sManager.GetClient(),
-		Log:                         ctrl.Log.WithName("controllers").WithName("Shoot"),
-		Scheme:                      k8sManager.GetScheme(),
-		Config:                      cmConfig,
-		ReconcilerCountPerNamespace: map[string]int{},
+		Client:    k8sManager.GetClient(),
+		APIReader: k8sManager.GetAPIReader(),
+		Log:       ctrl.Log.WithName("controllers").WithName("Shoot"),
+		Recorder:  k8sManager.GetEventRecorderFor("gardenlogin-controller-manager"),
+		Scheme:    k8sManager.GetScheme(),
+		Config:    cmConfig,
 	}
 	err := shootReconciler.SetupWithManager(ctx, k8sManager, cmConfig.Controllers.Shoot)
 	Expect(err).ToNot(HaveOccurred())
 
+	projectReconciler = &ProjectReconciler{
+		Client: k8sManager.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("Project"),
+		Scheme: k8sManager.GetScheme(),
+		Config: cmConfig,
+	}
+	err = projectReconciler.SetupWithManager(k8sManager, cmConfig.Controllers.Project)
+	Expect(err).ToNot(HaveOccurred())
+
+	bundleReconciler = &KubeconfigBundleReconciler{
+		Client: k8sManager.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("KubeconfigBundle"),
+		Scheme: k8sManager.GetScheme(),
+		Config: cmConfig,
+	}
+	err = bundleReconciler.SetupWithManager(k8sManager, cmConfig.Controllers.KubeconfigBundle)
+	Expect(err).ToNot(HaveOccurred())
+
+	gardenReconciler = &GardenReconciler{
+		Client: k8sManager.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("Garden"),
+		Scheme: k8sManager.GetScheme(),
+		Config: cmConfig,
+	}
+	err = gardenReconciler.SetupWithManager(ctx, k8sManager, cmConfig.Controllers.Garden)
+	Expect(err).ToNot(HaveOccurred())
+
 	environment.Start(ctx)
 })
 
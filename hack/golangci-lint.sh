@@ -35,5 +35,6 @@ function run_lint {
 config="${SOURCE_PATH}/.golangci.yaml"
 
 run_lint gardenlogin-controller-manager "${SOURCE_PATH}" "${config}"
+run_lint gardenlogin-config-api "${SOURCE_PATH}/api/config" "${config}"
 # submodules are currently ignored by golangci-lint, hence we have to scan it separately (https://github.com/golangci/golangci-lint/issues/828)
 run_lint gardenlogin-container-deployer "${SOURCE_PATH}/.landscaper/container" "${config}"
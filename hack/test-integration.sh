@@ -20,3 +20,8 @@ GO_TEST_ADDITIONAL_FLAGS=${GO_TEST_ADDITIONAL_FLAGS:-""}
 source "${SOURCE_PATH}/hack/test-common.sh"
 
 run_test gardenlogin-controller-manager "${SOURCE_PATH}" "${GO_TEST_ADDITIONAL_FLAGS}"
+
+echo "> Test gardenlogin-config-api"
+pushd "${SOURCE_PATH}/api/config" || exit
+GO111MODULE=on go test ./... -coverprofile cover.out "${GO_TEST_ADDITIONAL_FLAGS}"
+popd || exit
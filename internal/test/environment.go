@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/gardener/gardener/pkg/client/kubernetes"
@@ -20,6 +21,7 @@ import (
 	"github.com/onsi/gomega"
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/rest"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -30,6 +32,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
+	gardenloginv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/gardenlogin/v1alpha1"
 	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1/constants"
 	"github.com/gardener/gardenlogin-controller-manager/internal/util"
 )
@@ -37,7 +41,9 @@ import (
 var (
 	gardenTestEnv *gardenenvtest.GardenerTestEnvironment
 
-	configMapValidatingWebhookPath = "/configmap/validate"
+	configMapValidatingWebhookPath      = "/configmap/validate"
+	configMapMutatingWebhookPath        = "/configmap/mutate"
+	kubeconfigBundleMutatingWebhookPath = "/kubeconfigbundle/mutate"
 )
 
 type Environment struct {
@@ -47,7 +53,7 @@ type Environment struct {
 	K8sClient  client.Client
 }
 
-func New(validator admission.Handler) Environment {
+func New(validator admission.Handler, mutator admission.Handler, bundleMutator admission.Handler) Environment {
 	logf.SetLogger(zap.New(zap.WriteTo(ginkgo.GinkgoWriter), zap.UseDevMode(true)))
 
 	ginkgo.By("bootstrapping test environment")
@@ -58,6 +64,7 @@ func New(validator admission.Handler) Environment {
 			Operations: []admissionregistrationv1.OperationType{
 				admissionregistrationv1.Create,
 				admissionregistrationv1.Update,
+				admissionregistrationv1.Delete,
 			},
 			Rule: admissionregistrationv1.Rule{
 				APIGroups:   []string{""},
@@ -67,8 +74,80 @@ func New(validator admission.Handler) Environment {
 		},
 	}
 
+	mutatingRules := []admissionregistrationv1.RuleWithOperations{
+		{
+			Operations: []admissionregistrationv1.OperationType{
+				admissionregistrationv1.Create,
+				admissionregistrationv1.Update,
+			},
+			Rule: admissionregistrationv1.Rule{
+				APIGroups:   []string{""},
+				APIVersions: []string{"v1"},
+				Resources:   []string{"configmaps"},
+			},
+		},
+	}
+
+	bundleRules := []admissionregistrationv1.RuleWithOperations{
+		{
+			Operations: []admissionregistrationv1.OperationType{
+				admissionregistrationv1.Create,
+				admissionregistrationv1.Update,
+			},
+			Rule: admissionregistrationv1.Rule{
+				APIGroups:   []string{gardenloginv1alpha1.GroupVersion.Group},
+				APIVersions: []string{gardenloginv1alpha1.GroupVersion.Version},
+				Resources:   []string{"kubeconfigbundles"},
+			},
+		},
+	}
+
 	noSideEffects := admissionregistrationv1.SideEffectClassNone
 	webhookInstallOptions := envtest.WebhookInstallOptions{
+		MutatingWebhooks: []*admissionregistrationv1.MutatingWebhookConfiguration{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-mutating-webhook-configuration",
+				},
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "MutatingWebhookConfiguration",
+					APIVersion: "admissionregistration.k8s.io/v1",
+				},
+				Webhooks: []admissionregistrationv1.MutatingWebhook{
+					{
+						Name:           "test-mutating-create-update-configmap.gardenlogin.gardener.cloud",
+						FailurePolicy:  &failPolicy,
+						TimeoutSeconds: pointer.Int32Ptr(10),
+						ClientConfig: admissionregistrationv1.WebhookClientConfig{
+							Service: &admissionregistrationv1.ServiceReference{
+								Path: &configMapMutatingWebhookPath,
+							},
+						},
+						Rules: mutatingRules,
+						ObjectSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{
+								constants.GardenerOperationsRole: constants.GardenerOperationsKubeconfig,
+							},
+						},
+						AdmissionReviewVersions: []string{"v1", "v1beta1"},
+						SideEffects:             &noSideEffects,
+					},
+					{
+						Name:           "test-mutating-create-update-kubeconfigbundle.gardenlogin.gardener.cloud",
+						FailurePolicy:  &failPolicy,
+						TimeoutSeconds: pointer.Int32Ptr(10),
+						ClientConfig: admissionregistrationv1.WebhookClientConfig{
+							Service: &admissionregistrationv1.ServiceReference{
+								Path: &kubeconfigBundleMutatingWebhookPath,
+							},
+						},
+						Rules:                   bundleRules,
+						AdmissionReviewVersions: []string{"v1", "v1beta1"},
+						SideEffects:             &noSideEffects,
+					},
+				},
+			},
+		},
 		ValidatingWebhooks: []*admissionregistrationv1.ValidatingWebhookConfiguration{
 			{
 				ObjectMeta: metav1.ObjectMeta{
@@ -116,6 +195,8 @@ func New(validator admission.Handler) Environment {
 					},
 				},
 			},
+			CRDDirectoryPaths:     []string{filepath.Join("..", ".landscaper", "blueprint", "config", "crd", "bases")},
+			ErrorIfCRDPathMissing: true,
 			WebhookInstallOptions: webhookInstallOptions,
 		},
 		GardenerAPIServer: &gardenenvtest.GardenerAPIServer{
@@ -123,6 +204,8 @@ func New(validator admission.Handler) Environment {
 		},
 	}
 
+	utilruntime.Must(gardenloginv1alpha1.AddToScheme(kubernetes.GardenScheme))
+
 	cfg, err := gardenTestEnv.Start()
 	gomega.Expect(err).NotTo(gomega.HaveOccurred())
 	gomega.Expect(cfg).NotTo(gomega.BeNil())
@@ -136,6 +219,7 @@ func New(validator admission.Handler) Environment {
 	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
 	k8sManager, err := ctrl.NewManager(cfg, ctrl.Options{
 		Scheme:             kubernetes.GardenScheme,
+		NewClient:          util.NewClient,
 		LeaderElection:     false,
 		Host:               gardenTestEnv.WebhookInstallOptions.LocalServingHost,
 		Port:               gardenTestEnv.WebhookInstallOptions.LocalServingPort,
@@ -149,6 +233,8 @@ func New(validator admission.Handler) Environment {
 
 	hookServer := k8sManager.GetWebhookServer()
 	hookServer.Register(configMapValidatingWebhookPath, &webhook.Admission{Handler: validator})
+	hookServer.Register(configMapMutatingWebhookPath, &webhook.Admission{Handler: mutator})
+	hookServer.Register(kubeconfigBundleMutatingWebhookPath, &webhook.Admission{Handler: bundleMutator})
 
 	return Environment{
 		gardenTestEnv,
@@ -180,18 +266,69 @@ func (e Environment) Start(ctx context.Context) {
 	}).Should(gomega.Succeed())
 }
 
-func DefaultConfiguration() *util.ControllerManagerConfiguration {
-	return &util.ControllerManagerConfiguration{
-		Controllers: util.ControllerManagerControllerConfiguration{
-			Shoot: util.ShootControllerConfiguration{
+func DefaultConfiguration() *configv1alpha1.ControllerManagerConfiguration {
+	return &configv1alpha1.ControllerManagerConfiguration{
+		Controllers: configv1alpha1.ControllerManagerControllerConfiguration{
+			Shoot: configv1alpha1.ShootControllerConfiguration{
 				MaxConcurrentReconciles:             50,
 				MaxConcurrentReconcilesPerNamespace: 3,
-				QuotaExceededRetryDelay:             1 * time.Second,
+				QuotaExceededRetryDelay:             metav1.Duration{Duration: 1 * time.Second},
+			},
+			Project: configv1alpha1.ProjectControllerConfiguration{
+				Enabled:                 true,
+				MaxConcurrentReconciles: 5,
+				QuotaExceededRetryDelay: metav1.Duration{Duration: 1 * time.Second},
+			},
+			KubeconfigBundle: configv1alpha1.KubeconfigBundleControllerConfiguration{
+				Enabled:                      true,
+				MaxConcurrentReconciles:      5,
+				AuthorizationRecheckInterval: metav1.Duration{Duration: 10 * time.Minute},
+				QuotaExceededRetryDelay:      metav1.Duration{Duration: 1 * time.Second},
+			},
+			Garden: configv1alpha1.GardenControllerConfiguration{
+				Enabled:                 true,
+				MaxConcurrentReconciles: 5,
+				QuotaExceededRetryDelay: metav1.Duration{Duration: 1 * time.Second},
 			},
 		},
-		Webhooks: util.ControllerManagerWebhookConfiguration{
-			ConfigMapValidation: util.ConfigMapValidatingWebhookConfiguration{
+		Webhooks: configv1alpha1.ControllerManagerWebhookConfiguration{
+			ConfigMapValidation: configv1alpha1.ConfigMapValidatingWebhookConfiguration{
 				MaxObjectSize: 100 * 1024,
+				DeletionAllowedUsers: []string{
+					"system:serviceaccount:kube-system:generic-garbage-collector",
+					"system:serviceaccount:kube-system:namespace-controller",
+				},
+				Strict: true,
+				AccessReviewCache: configv1alpha1.AccessReviewCacheConfiguration{
+					Enabled:    true,
+					AllowedTTL: metav1.Duration{Duration: 5 * time.Minute},
+					DeniedTTL:  metav1.Duration{Duration: 30 * time.Second},
+				},
+			},
+		},
+		Kubeconfig: configv1alpha1.KubeconfigConfiguration{
+			Exec: configv1alpha1.ExecConfiguration{
+				Command: "kubectl",
+				Args:    []string{"gardenlogin", "get-client-certificate"},
+			},
+			Formats: []configv1alpha1.KubeconfigFormat{
+				{
+					KubernetesVersionConstraint: "< v1.20.0",
+					Legacy:                      true,
+					ExecAPIVersion:              "client.authentication.k8s.io/v1beta1",
+				},
+				{
+					KubernetesVersionConstraint: ">= v1.20.0",
+					ExecAPIVersion:              "client.authentication.k8s.io/v1beta1",
+				},
+			},
+			Garden: configv1alpha1.GardenKubeconfigConfiguration{
+				Name:   "garden",
+				Server: "https://api.garden.example.com",
+				OIDC: &configv1alpha1.GardenOIDCConfiguration{
+					IssuerURL: "https://issuer.example.com",
+					ClientID:  "gardener",
+				},
 			},
 		},
 	}
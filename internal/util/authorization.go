@@ -0,0 +1,155 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util
+
+import (
+	"context"
+	"fmt"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CanListShoots returns true in case the given user is allowed to list the shoots of the given namespace
+func CanListShoots(ctx context.Context, c client.Client, userInfo authenticationv1.UserInfo, namespace string) (bool, error) {
+	return canAccessShoots(ctx, c, userInfo, "list", namespace, "")
+}
+
+// CanGetShoot returns true in case the given user is allowed to get the shoot with the given key
+func CanGetShoot(ctx context.Context, c client.Client, userInfo authenticationv1.UserInfo, key client.ObjectKey) (bool, error) {
+	return canAccessShoots(ctx, c, userInfo, "get", key.Namespace, key.Name)
+}
+
+// canAccessShoots returns true in case the given user is allowed to perform the given verb on the shoots of the given namespace, optionally restricted to the given name
+func canAccessShoots(ctx context.Context, c client.Client, userInfo authenticationv1.UserInfo, verb string, namespace string, name string) (bool, error) {
+	extra := make(map[string]authorizationv1.ExtraValue)
+	for k, v := range userInfo.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+
+	subjectAccessReview := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Group:     gardencorev1beta1.SchemeGroupVersion.Group,
+				Resource:  "shoots",
+				Verb:      verb,
+				Namespace: namespace,
+				Name:      name,
+			},
+			User:   userInfo.Username,
+			Groups: userInfo.Groups,
+			UID:    userInfo.UID,
+			Extra:  extra,
+		},
+	}
+	err := c.Create(ctx, subjectAccessReview)
+
+	return subjectAccessReview.Status.Allowed, err
+}
+
+// UnauthorizedConfigMapReader returns a subject that is allowed to read the configMaps of the given namespace but not those of the given
+// source namespace, e.g. because a kubeconfig aggregating the shoots of the source namespace is published in the namespace.
+// An empty string is returned in case all readers of the namespace may read the configMaps of the source namespace.
+// The readers are the subjects of the RoleBindings of the namespace. Subjects of ClusterRoleBindings are not reviewed, as a ClusterRoleBinding
+// grants the same permissions in all namespaces. Permissions that are not granted by RBAC, e.g. by a webhook authorizer, are not considered.
+func UnauthorizedConfigMapReader(ctx context.Context, c client.Client, namespace string, sourceNamespace string) (string, error) {
+	if namespace == sourceNamespace {
+		return "", nil
+	}
+
+	roleBindings := &rbacv1.RoleBindingList{}
+	if err := c.List(ctx, roleBindings, client.InNamespace(namespace)); err != nil {
+		return "", fmt.Errorf("failed to list role bindings of namespace %s: %w", namespace, err)
+	}
+
+	reviewed := make(map[rbacv1.Subject]bool)
+
+	for _, roleBinding := range roleBindings.Items {
+		for _, subject := range roleBinding.Subjects {
+			if subject.Kind == rbacv1.ServiceAccountKind && subject.Namespace == "" {
+				// service accounts without namespace refer to the namespace of the role binding
+				subject.Namespace = roleBinding.Namespace
+			}
+
+			subject.APIGroup = ""
+			if reviewed[subject] {
+				continue
+			}
+
+			reviewed[subject] = true
+
+			reader, err := canReadConfigMaps(ctx, c, subject, namespace)
+			if err != nil {
+				return "", err
+			}
+
+			if !reader {
+				continue
+			}
+
+			allowed, err := canReadConfigMaps(ctx, c, subject, sourceNamespace)
+			if err != nil {
+				return "", err
+			}
+
+			if !allowed {
+				return fmt.Sprintf("%s %s", subject.Kind, subjectName(subject)), nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// canReadConfigMaps returns true in case the given subject is allowed to get or list the configMaps of the given namespace
+func canReadConfigMaps(ctx context.Context, c client.Client, subject rbacv1.Subject, namespace string) (bool, error) {
+	spec := authorizationv1.SubjectAccessReviewSpec{}
+
+	switch subject.Kind {
+	case rbacv1.UserKind:
+		spec.User = subject.Name
+	case rbacv1.GroupKind:
+		spec.Groups = []string{subject.Name}
+	case rbacv1.ServiceAccountKind:
+		spec.User = serviceaccount.MakeUsername(subject.Namespace, subject.Name)
+		spec.Groups = serviceaccount.MakeGroupNames(subject.Namespace)
+	default:
+		return false, nil
+	}
+
+	for _, verb := range []string{"get", "list"} {
+		spec.ResourceAttributes = &authorizationv1.ResourceAttributes{
+			Resource:  "configmaps",
+			Verb:      verb,
+			Namespace: namespace,
+		}
+
+		subjectAccessReview := &authorizationv1.SubjectAccessReview{Spec: spec}
+		if err := c.Create(ctx, subjectAccessReview); err != nil {
+			return false, fmt.Errorf("failed to review access of %s %s to namespace %s: %w", subject.Kind, subjectName(subject), namespace, err)
+		}
+
+		if subjectAccessReview.Status.Allowed {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// subjectName returns the name of the given subject, which includes the namespace for service accounts
+func subjectName(subject rbacv1.Subject) string {
+	if subject.Kind == rbacv1.ServiceAccountKind {
+		return subject.Namespace + "/" + subject.Name
+	}
+
+	return subject.Name
+}
@@ -0,0 +1,97 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util_test
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/gardener/gardenlogin-controller-manager/internal/util"
+)
+
+// accessReviewClient answers SubjectAccessReviews for configMaps with the given readers per namespace, identified by user or first group
+type accessReviewClient struct {
+	client.Client
+	readers map[string][]string
+}
+
+func (c *accessReviewClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	review, ok := obj.(*authorizationv1.SubjectAccessReview)
+	if !ok {
+		return c.Client.Create(ctx, obj, opts...)
+	}
+
+	subject := review.Spec.User
+	if subject == "" {
+		subject = strings.Join(review.Spec.Groups, ",")
+	}
+
+	for _, reader := range c.readers[review.Spec.ResourceAttributes.Namespace] {
+		if reader == subject {
+			review.Status.Allowed = true
+		}
+	}
+
+	return nil
+}
+
+var _ = Describe("authorization", func() {
+	Describe("#UnauthorizedConfigMapReader", func() {
+		var (
+			ctx     context.Context
+			readers map[string][]string
+			c       client.Client
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			readers = map[string][]string{
+				"garden-sre":       {"alice", "sre", "system:serviceaccount:garden-sre:robot"},
+				"garden-project-a": {"alice", "sre", "system:serviceaccount:garden-sre:robot"},
+				"garden-project-b": {"alice"},
+			}
+
+			roleBinding := &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "readers", Namespace: "garden-sre"},
+				Subjects: []rbacv1.Subject{
+					{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "alice"},
+					{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "bob"}, // bound, but not allowed to read configMaps
+					{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "sre"},
+					{Kind: rbacv1.ServiceAccountKind, Name: "robot"},
+				},
+			}
+
+			c = &accessReviewClient{Client: fakeclient.NewClientBuilder().WithObjects(roleBinding).Build(), readers: readers}
+		})
+
+		It("should accept source namespaces that all readers of the namespace may read", func() {
+			Expect(util.UnauthorizedConfigMapReader(ctx, c, "garden-sre", "garden-project-a")).To(BeEmpty())
+		})
+
+		It("should accept the namespace itself", func() {
+			Expect(util.UnauthorizedConfigMapReader(ctx, c, "garden-sre", "garden-sre")).To(BeEmpty())
+		})
+
+		It("should return a reader of the namespace that may not read the source namespace", func() {
+			Expect(util.UnauthorizedConfigMapReader(ctx, c, "garden-sre", "garden-project-b")).To(Equal("Group sre"))
+		})
+
+		It("should review service accounts with their namespace", func() {
+			readers["garden-project-a"] = []string{"alice", "sre"}
+
+			Expect(util.UnauthorizedConfigMapReader(ctx, c, "garden-sre", "garden-project-a")).To(Equal("ServiceAccount garden-sre/robot"))
+		})
+	})
+})
@@ -0,0 +1,175 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	gardencorev1alpha1 "github.com/gardener/gardener/pkg/apis/core/v1alpha1"
+	"github.com/gardener/gardener/pkg/utils/secrets"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
+)
+
+// CAClusterNameSuffix is the name suffix of the ConfigMap or Secret in the project namespace that holds the cluster certificate authority of a shoot,
+// which is named <shoot>.ca-cluster
+const CAClusterNameSuffix = ".ca-cluster"
+
+// ErrCASourceMissing is returned by a CASource in case the resource holding the certificate authority of the shoot does not exist
+var ErrCASourceMissing = errors.New("source of certificate authority does not exist")
+
+// CASource reads the cluster certificate authority of shoots. The returned certificates are the ones to publish in the kubeconfigs of the shoot,
+// i.e. they contain the old and the new certificate authority while the certificate authorities are rotated.
+type CASource interface {
+	// ClusterCA returns the PEM encoded certificate authorities of the shoot with the given key, whose certificate authorities rotation
+	// is in the given phase, see CARotationPhase.
+	// It returns an error wrapping ErrCASourceMissing in case the source does not hold the certificate authority of the shoot
+	// and ErrCANotProvisioned in case the certificate authority is not yet provisioned.
+	ClusterCA(ctx context.Context, key client.ObjectKey, rotationPhase string) ([]byte, error)
+}
+
+// NewCASource returns the CASource for the given source types. In case several types are given, they are tried in the given order.
+// The reader is used for ShootStates and ConfigMaps, the apiReader for Secrets, so that the Secrets of the garden cluster are not cached.
+func NewCASource(types []configv1alpha1.CASourceType, reader client.Reader, apiReader client.Reader) (CASource, error) {
+	var sources CASources
+
+	for _, t := range types {
+		switch t {
+		case configv1alpha1.CASourceShootState:
+			sources = append(sources, &ShootStateCASource{Reader: reader})
+		case configv1alpha1.CASourceConfigMap:
+			sources = append(sources, &ConfigMapCASource{Reader: reader})
+		case configv1alpha1.CASourceSecret:
+			sources = append(sources, &SecretCASource{Reader: apiReader})
+		default:
+			return nil, fmt.Errorf("unsupported ca source %q", t)
+		}
+	}
+
+	if len(sources) == 1 {
+		return sources[0], nil
+	}
+
+	return sources, nil
+}
+
+// CASources tries the contained sources in order and returns the certificate authority of the first source that holds it.
+type CASources []CASource
+
+var _ CASource = CASources{}
+
+// ClusterCA returns the certificate authorities of the first source that holds the certificate authority of the shoot.
+// Errors other than ErrCASourceMissing and ErrCANotProvisioned are returned immediately. In case no source holds a provisioned certificate authority,
+// ErrCANotProvisioned is returned if any source reported it, otherwise ErrCASourceMissing.
+func (s CASources) ClusterCA(ctx context.Context, key client.ObjectKey, rotationPhase string) ([]byte, error) {
+	notProvisioned := false
+
+	for _, source := range s {
+		ca, err := source.ClusterCA(ctx, key, rotationPhase)
+
+		switch {
+		case err == nil:
+			return ca, nil
+		case errors.Is(err, ErrCANotProvisioned):
+			notProvisioned = true
+		case !errors.Is(err, ErrCASourceMissing):
+			return nil, err
+		}
+	}
+
+	if notProvisioned {
+		return nil, ErrCANotProvisioned
+	}
+
+	return nil, fmt.Errorf("%w for shoot %s", ErrCASourceMissing, key)
+}
+
+// ShootStateCASource reads the certificate authority from the gardener resource data of the ShootState of the shoot.
+// During a rotation of the certificate authorities, the ca bundle of the ShootState is returned.
+type ShootStateCASource struct {
+	Reader client.Reader
+}
+
+var _ CASource = &ShootStateCASource{}
+
+// ClusterCA returns the certificate authorities of the shoot with the given key that are stored in its ShootState
+func (s *ShootStateCASource) ClusterCA(ctx context.Context, key client.ObjectKey, rotationPhase string) ([]byte, error) {
+	shootState := &gardencorev1alpha1.ShootState{}
+	if err := s.Reader.Get(ctx, key, shootState); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: shootstate %s not found", ErrCASourceMissing, key)
+		}
+
+		return nil, fmt.Errorf("failed to fetch shootstate %s: %w", key, err)
+	}
+
+	return ClusterCACertificates(shootState, rotationPhase)
+}
+
+// ConfigMapCASource reads the certificate authority from the <shoot>.ca-cluster ConfigMap in the project namespace.
+// Gardener publishes the ca bundle in this ConfigMap during a rotation of the certificate authorities.
+type ConfigMapCASource struct {
+	Reader client.Reader
+}
+
+var _ CASource = &ConfigMapCASource{}
+
+// ClusterCA returns the certificate authorities of the shoot with the given key that are stored in the <shoot>.ca-cluster ConfigMap
+func (s *ConfigMapCASource) ClusterCA(ctx context.Context, key client.ObjectKey, _ string) ([]byte, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := s.Reader.Get(ctx, caClusterKey(key), configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: configmap %s not found", ErrCASourceMissing, caClusterKey(key))
+		}
+
+		return nil, fmt.Errorf("failed to fetch configmap %s: %w", caClusterKey(key), err)
+	}
+
+	ca := configMap.Data[secrets.DataKeyCertificateCA]
+	if ca == "" {
+		return nil, ErrCANotProvisioned
+	}
+
+	return []byte(ca), nil
+}
+
+// SecretCASource reads the certificate authority from the <shoot>.ca-cluster Secret in the project namespace.
+// Gardener publishes the ca bundle in this Secret during a rotation of the certificate authorities.
+type SecretCASource struct {
+	Reader client.Reader
+}
+
+var _ CASource = &SecretCASource{}
+
+// ClusterCA returns the certificate authorities of the shoot with the given key that are stored in the <shoot>.ca-cluster Secret
+func (s *SecretCASource) ClusterCA(ctx context.Context, key client.ObjectKey, _ string) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := s.Reader.Get(ctx, caClusterKey(key), secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: secret %s not found", ErrCASourceMissing, caClusterKey(key))
+		}
+
+		return nil, fmt.Errorf("failed to fetch secret %s: %w", caClusterKey(key), err)
+	}
+
+	ca := secret.Data[secrets.DataKeyCertificateCA]
+	if len(ca) == 0 {
+		return nil, ErrCANotProvisioned
+	}
+
+	return ca, nil
+}
+
+// caClusterKey returns the key of the <shoot>.ca-cluster resource of the shoot with the given key
+func caClusterKey(key client.ObjectKey) client.ObjectKey {
+	return client.ObjectKey{Namespace: key.Namespace, Name: key.Name + CAClusterNameSuffix}
+}
@@ -0,0 +1,160 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	gardencorev1alpha1 "github.com/gardener/gardener/pkg/apis/core/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
+	"github.com/gardener/gardenlogin-controller-manager/internal/util"
+)
+
+// fakeCASource returns the configured certificate authority or error
+type fakeCASource struct {
+	ca  []byte
+	err error
+}
+
+func (s *fakeCASource) ClusterCA(_ context.Context, _ client.ObjectKey, _ string) ([]byte, error) {
+	return s.ca, s.err
+}
+
+var _ = Describe("CASource", func() {
+	var (
+		ctx context.Context
+		key client.ObjectKey
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		key = client.ObjectKey{Namespace: "garden-dev", Name: "foo"}
+	})
+
+	Describe("#ShootStateCASource", func() {
+		It("should read the ca bundle from the ShootState while the certificate authorities are rotated", func() {
+			scheme := runtime.NewScheme()
+			Expect(gardencorev1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			raw := func(data map[string][]byte) runtime.RawExtension {
+				b, err := json.Marshal(data)
+				Expect(err).ToNot(HaveOccurred())
+
+				return runtime.RawExtension{Raw: b}
+			}
+
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&gardencorev1alpha1.ShootState{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "garden-dev", Name: "foo"},
+				Spec: gardencorev1alpha1.ShootStateSpec{
+					Gardener: []gardencorev1alpha1.GardenerResourceData{
+						{Name: "ca", Type: "secret", Data: raw(map[string][]byte{"ca.crt": []byte("new")})},
+						{Name: "ca-bundle", Type: "secret", Data: raw(map[string][]byte{"bundle.crt": []byte("old\nnew")})},
+					},
+				},
+			}).Build()
+			source := &util.ShootStateCASource{Reader: c}
+
+			Expect(source.ClusterCA(ctx, key, util.CARotationPhaseCompleting)).To(BeEquivalentTo("old\nnew"))
+			Expect(source.ClusterCA(ctx, key, "")).To(BeEquivalentTo("new"))
+		})
+	})
+
+	Describe("#ConfigMapCASource", func() {
+		It("should read the certificate authority from the <shoot>.ca-cluster configmap", func() {
+			c := fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "garden-dev", Name: "foo.ca-cluster"},
+				Data:       map[string]string{"ca.crt": "ca"},
+			}).Build()
+
+			Expect((&util.ConfigMapCASource{Reader: c}).ClusterCA(ctx, key, "")).To(BeEquivalentTo("ca"))
+		})
+
+		It("should return ErrCASourceMissing in case the configmap does not exist", func() {
+			_, err := (&util.ConfigMapCASource{Reader: fake.NewClientBuilder().Build()}).ClusterCA(ctx, key, "")
+			Expect(err).To(MatchError(util.ErrCASourceMissing))
+		})
+	})
+
+	Describe("#SecretCASource", func() {
+		It("should read the certificate authority from the <shoot>.ca-cluster secret", func() {
+			c := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "garden-dev", Name: "foo.ca-cluster"},
+				Data:       map[string][]byte{"ca.crt": []byte("ca")},
+			}).Build()
+
+			Expect((&util.SecretCASource{Reader: c}).ClusterCA(ctx, key, "")).To(BeEquivalentTo("ca"))
+		})
+
+		It("should return ErrCANotProvisioned in case the secret holds no certificate authority", func() {
+			c := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "garden-dev", Name: "foo.ca-cluster"},
+			}).Build()
+
+			_, err := (&util.SecretCASource{Reader: c}).ClusterCA(ctx, key, "")
+			Expect(err).To(MatchError(util.ErrCANotProvisioned))
+		})
+	})
+
+	Describe("#CASources", func() {
+		missing := &fakeCASource{err: util.ErrCASourceMissing}
+		notProvisioned := &fakeCASource{err: util.ErrCANotProvisioned}
+
+		It("should return the certificate authority of the first source that holds it", func() {
+			sources := util.CASources{missing, &fakeCASource{ca: []byte("first")}, &fakeCASource{ca: []byte("second")}}
+
+			Expect(sources.ClusterCA(ctx, key, "")).To(BeEquivalentTo("first"))
+		})
+
+		It("should return other errors immediately", func() {
+			failed := errors.New("failed")
+			sources := util.CASources{&fakeCASource{err: failed}, &fakeCASource{ca: []byte("ca")}}
+
+			_, err := sources.ClusterCA(ctx, key, "")
+			Expect(err).To(MatchError(failed))
+		})
+
+		It("should return ErrCANotProvisioned in case a source reported it", func() {
+			_, err := util.CASources{notProvisioned, missing}.ClusterCA(ctx, key, "")
+			Expect(err).To(MatchError(util.ErrCANotProvisioned))
+		})
+
+		It("should return ErrCASourceMissing in case no source exists", func() {
+			_, err := util.CASources{missing, missing}.ClusterCA(ctx, key, "")
+			Expect(err).To(MatchError(util.ErrCASourceMissing))
+		})
+	})
+
+	Describe("#NewCASource", func() {
+		It("should fall back to the secret in case the configmap does not exist", func() {
+			reader := fake.NewClientBuilder().Build()
+			apiReader := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "garden-dev", Name: "foo.ca-cluster"},
+				Data:       map[string][]byte{"ca.crt": []byte("ca")},
+			}).Build()
+
+			caSource, err := util.NewCASource([]configv1alpha1.CASourceType{configv1alpha1.CASourceConfigMap, configv1alpha1.CASourceSecret}, reader, apiReader)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(caSource.ClusterCA(ctx, key, "")).To(BeEquivalentTo("ca"))
+		})
+
+		It("should reject unsupported source types", func() {
+			_, err := util.NewCASource([]configv1alpha1.CASourceType{"Vault"}, nil, nil)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,383 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
+	"github.com/gardener/gardenlogin-controller-manager/internal/util"
+)
+
+var _ = Describe("Config", func() {
+	var configFile string
+
+	writeConfig := func(content string) {
+		configFile = filepath.Join(GinkgoT().TempDir(), "config.yaml")
+		Expect(os.WriteFile(configFile, []byte(content), 0600)).To(Succeed())
+	}
+
+	Describe("#ReadControllerManagerConfiguration", func() {
+		It("should default the exec section", func() {
+			cfg, err := util.ReadControllerManagerConfiguration("")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cfg.Kubeconfig.Exec.Command).To(Equal("kubectl"))
+			Expect(cfg.Kubeconfig.Exec.Args).To(Equal([]string{"gardenlogin", "get-client-certificate"}))
+		})
+
+		It("should default the exec section for the standalone binary", func() {
+			writeConfig(`
+kubeconfig:
+  exec:
+    standalone: true
+`)
+			cfg, err := util.ReadControllerManagerConfiguration(configFile)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cfg.Kubeconfig.Exec.Command).To(Equal("kubectl-gardenlogin"))
+			Expect(cfg.Kubeconfig.Exec.Args).To(Equal([]string{"get-client-certificate"}))
+		})
+
+		It("should not overwrite a configured exec section", func() {
+			writeConfig(`
+kubeconfig:
+  exec:
+    command: gardenlogin
+    args:
+    - get-client-certificate
+    env:
+    - name: FOO
+      value: bar
+    installHint: foo
+    interactiveMode: Never
+`)
+			cfg, err := util.ReadControllerManagerConfiguration(configFile)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cfg.Kubeconfig.Exec).To(Equal(configv1alpha1.ExecConfiguration{
+				Command:         "gardenlogin",
+				Args:            []string{"get-client-certificate"},
+				Env:             []configv1alpha1.ExecEnvVar{{Name: "FOO", Value: "bar"}},
+				InstallHint:     "foo",
+				InteractiveMode: configv1alpha1.ExecInteractiveModeNever,
+			}))
+		})
+
+		It("should fail for an unsupported interactive mode", func() {
+			writeConfig(`
+kubeconfig:
+  exec:
+    interactiveMode: Sometimes
+`)
+			_, err := util.ReadControllerManagerConfiguration(configFile)
+			Expect(err).To(MatchError(ContainSubstring("kubeconfig.exec.interactiveMode")))
+		})
+
+		It("should fail for an environment variable without name", func() {
+			writeConfig(`
+kubeconfig:
+  exec:
+    env:
+    - value: bar
+`)
+			_, err := util.ReadControllerManagerConfiguration(configFile)
+			Expect(err).To(MatchError(ContainSubstring("kubeconfig.exec.env[0].name")))
+		})
+
+		It("should accept the config.gardenlogin.gardener.cloud/v1alpha1 API version", func() {
+			writeConfig(`
+apiVersion: config.gardenlogin.gardener.cloud/v1alpha1
+kind: ControllerManagerConfiguration
+controllers:
+  shoot:
+    quotaExceededRetryDelay: 1h
+`)
+			cfg, err := util.ReadControllerManagerConfiguration(configFile)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cfg.Controllers.Shoot.QuotaExceededRetryDelay.Duration).To(Equal(time.Hour))
+			Expect(cfg.Controllers.Shoot.MaxConcurrentReconciles).To(Equal(50))
+		})
+
+		It("should accept the legacy API version", func() {
+			writeConfig(`
+apiVersion: v1alpha1
+kind: ControllerManagerConfiguration
+controllers:
+  shoot:
+    maxConcurrentReconciles: 10
+`)
+			cfg, err := util.ReadControllerManagerConfiguration(configFile)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cfg.Controllers.Shoot.MaxConcurrentReconciles).To(Equal(10))
+		})
+
+		It("should accept the legacy integer form of the quota exceeded retry delay", func() {
+			writeConfig(`
+apiVersion: v1alpha1
+kind: ControllerManagerConfiguration
+controllers:
+  shoot:
+    maxConcurrentReconciles: 50
+    maxConcurrentReconcilesPerNamespace: 3
+    quotaExceededRetryDelay: 86400000000000
+`)
+			cfg, err := util.ReadControllerManagerConfiguration(configFile)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cfg.Controllers.Shoot.QuotaExceededRetryDelay.Duration).To(Equal(24 * time.Hour))
+		})
+
+		It("should reject the integer form of the quota exceeded retry delay for the current API version", func() {
+			writeConfig(`
+apiVersion: config.gardenlogin.gardener.cloud/v1alpha1
+kind: ControllerManagerConfiguration
+controllers:
+  shoot:
+    quotaExceededRetryDelay: 86400000000000
+`)
+			_, err := util.ReadControllerManagerConfiguration(configFile)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fail for an unknown kind", func() {
+			writeConfig(`
+apiVersion: config.gardenlogin.gardener.cloud/v1alpha1
+kind: Foo
+`)
+			_, err := util.ReadControllerManagerConfiguration(configFile)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fail for an unknown API version", func() {
+			writeConfig(`
+apiVersion: config.gardenlogin.gardener.cloud/v1
+kind: ControllerManagerConfiguration
+`)
+			_, err := util.ReadControllerManagerConfiguration(configFile)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fail for unknown fields", func() {
+			writeConfig(`
+controllers:
+  shootState:
+    maxConcurrentReconciles: 10
+`)
+			_, err := util.ReadControllerManagerConfiguration(configFile)
+			Expect(err).To(MatchError(ContainSubstring("shootState")))
+		})
+
+		It("should report the field path of the shoot controller", func() {
+			writeConfig(`
+controllers:
+  shoot:
+    maxConcurrentReconciles: 2
+    maxConcurrentReconcilesPerNamespace: 3
+`)
+			_, err := util.ReadControllerManagerConfiguration(configFile)
+			Expect(err).To(MatchError(ContainSubstring("controllers.shoot.maxConcurrentReconcilesPerNamespace")))
+		})
+
+		It("should default the users that may delete kubeconfig configMaps", func() {
+			cfg, err := util.ReadControllerManagerConfiguration("")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cfg.Webhooks.ConfigMapValidation.DeletionAllowedUsers).To(ConsistOf(
+				"system:serviceaccount:kube-system:generic-garbage-collector",
+				"system:serviceaccount:kube-system:namespace-controller",
+			))
+		})
+
+		It("should keep an empty list of users that may delete kubeconfig configMaps", func() {
+			writeConfig(`
+webhooks:
+  configMapValidation:
+    deletionAllowedUsers: []
+`)
+			cfg, err := util.ReadControllerManagerConfiguration(configFile)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cfg.Webhooks.ConfigMapValidation.DeletionAllowedUsers).To(BeEmpty())
+		})
+
+		It("should default the access review cache", func() {
+			cfg, err := util.ReadControllerManagerConfiguration("")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cfg.Webhooks.ConfigMapValidation.AccessReviewCache.Enabled).To(BeFalse())
+			Expect(cfg.Webhooks.ConfigMapValidation.AccessReviewCache.AllowedTTL.Duration).To(Equal(5 * time.Minute))
+			Expect(cfg.Webhooks.ConfigMapValidation.AccessReviewCache.DeniedTTL.Duration).To(Equal(30 * time.Second))
+		})
+
+		It("should fail for an invalid TTL of the access review cache", func() {
+			writeConfig(`
+webhooks:
+  configMapValidation:
+    accessReviewCache:
+      enabled: true
+      deniedTTL: -1s
+`)
+			_, err := util.ReadControllerManagerConfiguration(configFile)
+			Expect(err).To(MatchError(ContainSubstring("webhooks.configMapValidation.accessReviewCache.deniedTTL")))
+		})
+
+		It("should default the kubeconfig bundle controller", func() {
+			cfg, err := util.ReadControllerManagerConfiguration("")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cfg.Controllers.KubeconfigBundle.Enabled).To(BeFalse())
+			Expect(cfg.Controllers.KubeconfigBundle.MaxConcurrentReconciles).To(Equal(5))
+			Expect(cfg.Controllers.KubeconfigBundle.AuthorizationRecheckInterval.Duration).To(Equal(10 * time.Minute))
+		})
+
+		It("should fail for an invalid authorization recheck interval of the kubeconfig bundle controller", func() {
+			writeConfig(`
+controllers:
+  kubeconfigBundle:
+    enabled: true
+    authorizationRecheckInterval: -1m
+`)
+			_, err := util.ReadControllerManagerConfiguration(configFile)
+			Expect(err).To(MatchError(ContainSubstring("controllers.kubeconfigBundle.authorizationRecheckInterval")))
+		})
+
+		It("should accept a garden kubeconfig with oidc authentication", func() {
+			writeConfig(`
+controllers:
+  garden:
+    enabled: true
+kubeconfig:
+  garden:
+    server: https://api.garden.example.com
+    oidc:
+      issuerURL: https://issuer.example.com
+      clientID: gardener
+`)
+			cfg, err := util.ReadControllerManagerConfiguration(configFile)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cfg.Kubeconfig.Garden.Name).To(Equal("garden"))
+			Expect(cfg.Kubeconfig.Garden.OIDC.ClientID).To(Equal("gardener"))
+		})
+
+		It("should default the API version of the garden kubeconfig exec section", func() {
+			writeConfig(`
+controllers:
+  garden:
+    enabled: true
+kubeconfig:
+  garden:
+    server: https://api.garden.example.com
+    exec:
+      command: gardenctl
+`)
+			cfg, err := util.ReadControllerManagerConfiguration(configFile)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cfg.Kubeconfig.Garden.Exec.APIVersion).To(Equal("client.authentication.k8s.io/v1beta1"))
+		})
+
+		It("should fail for a garden kubeconfig without server", func() {
+			writeConfig(`
+controllers:
+  garden:
+    enabled: true
+`)
+			_, err := util.ReadControllerManagerConfiguration(configFile)
+			Expect(err).To(MatchError(ContainSubstring("kubeconfig.garden.server")))
+		})
+
+		It("should fail for a garden kubeconfig with oidc and exec authentication", func() {
+			writeConfig(`
+controllers:
+  garden:
+    enabled: true
+kubeconfig:
+  garden:
+    server: https://api.garden.example.com
+    oidc:
+      issuerURL: https://issuer.example.com
+      clientID: gardener
+    exec:
+      command: gardenctl
+`)
+			_, err := util.ReadControllerManagerConfiguration(configFile)
+			Expect(err).To(MatchError(ContainSubstring("exactly one of oidc or exec must be set")))
+		})
+	})
+
+	Describe("#FormatFor", func() {
+		var cfg *configv1alpha1.ControllerManagerConfiguration
+
+		BeforeEach(func() {
+			var err error
+			cfg, err = util.ReadControllerManagerConfiguration("")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		DescribeTable("default formats",
+			func(version string, expected configv1alpha1.KubeconfigFormat) {
+				Expect(cfg.Kubeconfig.FormatFor(version)).To(Equal(expected))
+			},
+			Entry("legacy", "1.19.9", configv1alpha1.KubeconfigFormat{KubernetesVersionConstraint: "< v1.20.0", Legacy: true, ExecAPIVersion: "client.authentication.k8s.io/v1beta1"}),
+			Entry("extension", "1.20.0", configv1alpha1.KubeconfigFormat{KubernetesVersionConstraint: ">= v1.20.0, < v1.24.0", ExecAPIVersion: "client.authentication.k8s.io/v1beta1"}),
+			Entry("extension before v1.24.0", "1.23.9", configv1alpha1.KubeconfigFormat{KubernetesVersionConstraint: ">= v1.20.0, < v1.24.0", ExecAPIVersion: "client.authentication.k8s.io/v1beta1"}),
+			Entry("extension with client.authentication.k8s.io/v1", "1.24.0", configv1alpha1.KubeconfigFormat{KubernetesVersionConstraint: ">= v1.24.0", ExecAPIVersion: "client.authentication.k8s.io/v1"}),
+		)
+
+		It("should use the first matching format", func() {
+			cfg.Kubeconfig.Formats = append([]configv1alpha1.KubeconfigFormat{
+				{KubernetesVersionConstraint: ">= v1.22.0", ExecAPIVersion: "client.authentication.k8s.io/v1"},
+			}, cfg.Kubeconfig.Formats...)
+
+			Expect(cfg.Kubeconfig.FormatFor("1.22.1")).To(Equal(configv1alpha1.KubeconfigFormat{KubernetesVersionConstraint: ">= v1.22.0", ExecAPIVersion: "client.authentication.k8s.io/v1"}))
+			Expect(cfg.Kubeconfig.FormatFor("1.21.1")).To(Equal(configv1alpha1.KubeconfigFormat{KubernetesVersionConstraint: ">= v1.20.0, < v1.24.0", ExecAPIVersion: "client.authentication.k8s.io/v1beta1"}))
+		})
+
+		It("should fall back to the extension format in case no format matches", func() {
+			cfg.Kubeconfig.Formats = nil
+
+			Expect(cfg.Kubeconfig.FormatFor("1.24.1")).To(Equal(configv1alpha1.KubeconfigFormat{ExecAPIVersion: "client.authentication.k8s.io/v1beta1"}))
+		})
+
+		It("should fail for an invalid version", func() {
+			_, err := cfg.Kubeconfig.FormatFor("foo")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("#ReadControllerManagerConfiguration formats", func() {
+		It("should fail for an unsupported exec API version", func() {
+			writeConfig(`
+kubeconfig:
+  formats:
+  - kubernetesVersionConstraint: ">= v1.20.0"
+    execAPIVersion: client.authentication.k8s.io/v1alpha1
+`)
+			_, err := util.ReadControllerManagerConfiguration(configFile)
+			Expect(err).To(MatchError(ContainSubstring("kubeconfig.formats[0].execAPIVersion")))
+		})
+
+		It("should fail for an invalid constraint", func() {
+			writeConfig(`
+kubeconfig:
+  formats:
+  - kubernetesVersionConstraint: "foo"
+`)
+			_, err := util.ReadControllerManagerConfiguration(configFile)
+			Expect(err).To(MatchError(ContainSubstring("kubeconfig.formats[0].kubernetesVersionConstraint")))
+		})
+	})
+})
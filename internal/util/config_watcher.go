@@ -0,0 +1,130 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-logr/logr"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
+	configvalidation "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1/validation"
+)
+
+// ConfigInjector is implemented by the controllers and webhooks that support replacing their configuration at runtime
+type ConfigInjector interface {
+	// InjectConfig replaces the configuration
+	InjectConfig(config *configv1alpha1.ControllerManagerConfiguration)
+}
+
+// ConfigWatcher watches the configuration file and injects the configuration into the Injectors whenever the file changed.
+// The new configuration is rejected in case it is invalid or a field that cannot be changed at runtime was changed.
+// It implements the manager.Runnable interface.
+type ConfigWatcher struct {
+	// Path is the path of the configuration file
+	Path string
+	// Log is the logger of the ConfigWatcher
+	Log logr.Logger
+	// Injectors are the components the configuration is injected into
+	Injectors []ConfigInjector
+
+	mutex  sync.Mutex
+	config *configv1alpha1.ControllerManagerConfiguration
+}
+
+// NewConfigWatcher returns a ConfigWatcher for the given configuration file, which was read initially into the given configuration
+func NewConfigWatcher(path string, config *configv1alpha1.ControllerManagerConfiguration, log logr.Logger, injectors ...ConfigInjector) *ConfigWatcher {
+	return &ConfigWatcher{
+		Path:      path,
+		Log:       log,
+		Injectors: injectors,
+		config:    config,
+	}
+}
+
+// Start watches the directory of the configuration file until the context is done.
+// The directory is watched instead of the file, as mounted configMaps are updated by replacing a symlink.
+func (w *ConfigWatcher) Start(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	defer func() {
+		if err := watcher.Close(); err != nil {
+			w.Log.Error(err, "failed to close file watcher")
+		}
+	}()
+
+	if err := watcher.Add(filepath.Dir(w.Path)); err != nil {
+		return fmt.Errorf("failed to watch directory of configuration file %s: %w", w.Path, err)
+	}
+
+	w.Log.Info("watching configuration file", "path", w.Path)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) == 0 {
+				continue
+			}
+
+			w.Reload()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+
+			w.Log.Error(err, "error watching configuration file", "path", w.Path)
+		}
+	}
+}
+
+// NeedLeaderElection returns false, as the configuration is required by all replicas, e.g. to serve the webhooks
+func (w *ConfigWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+// Reload reads the configuration file and injects the configuration into the Injectors in case it changed and is valid.
+func (w *ConfigWatcher) Reload() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	config, err := ReadControllerManagerConfiguration(w.Path)
+	if err != nil {
+		w.Log.Error(err, "rejecting invalid configuration, keeping the current configuration", "path", w.Path)
+		return
+	}
+
+	if apiequality.Semantic.DeepEqual(config, w.config) {
+		return
+	}
+
+	if errs := configvalidation.ValidateControllerManagerConfigurationUpdate(config, w.config); len(errs) > 0 {
+		w.Log.Error(errs.ToAggregate(), "rejecting configuration with changes that require a restart, keeping the current configuration", "path", w.Path)
+		return
+	}
+
+	for _, injector := range w.Injectors {
+		injector.InjectConfig(config)
+	}
+
+	w.config = config
+
+	w.Log.Info("configuration reloaded", "path", w.Path)
+}
@@ -0,0 +1,134 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
+	"github.com/gardener/gardenlogin-controller-manager/internal/util"
+)
+
+type fakeInjector struct {
+	mutex  sync.Mutex
+	config *configv1alpha1.ControllerManagerConfiguration
+}
+
+func (f *fakeInjector) InjectConfig(config *configv1alpha1.ControllerManagerConfiguration) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.config = config
+}
+
+func (f *fakeInjector) getConfig() *configv1alpha1.ControllerManagerConfiguration {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.config
+}
+
+var _ = Describe("ConfigWatcher", func() {
+	var (
+		configFile string
+		injector   *fakeInjector
+		watcher    *util.ConfigWatcher
+	)
+
+	writeConfig := func(content string) {
+		Expect(os.WriteFile(configFile, []byte(content), 0600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		configFile = filepath.Join(GinkgoT().TempDir(), "config.yaml")
+		writeConfig(`
+controllers:
+  shoot:
+    quotaExceededRetryDelay: 1h
+`)
+
+		config, err := util.ReadControllerManagerConfiguration(configFile)
+		Expect(err).ToNot(HaveOccurred())
+
+		injector = &fakeInjector{config: config}
+		watcher = util.NewConfigWatcher(configFile, config, logr.Discard(), injector)
+	})
+
+	Describe("#Reload", func() {
+		It("should inject a changed configuration", func() {
+			writeConfig(`
+controllers:
+  shoot:
+    quotaExceededRetryDelay: 2h
+webhooks:
+  configMapValidation:
+    maxObjectSize: 1024
+`)
+			watcher.Reload()
+
+			Expect(injector.getConfig().Controllers.Shoot.QuotaExceededRetryDelay.Duration).To(Equal(2 * time.Hour))
+			Expect(injector.getConfig().Webhooks.ConfigMapValidation.MaxObjectSize).To(Equal(1024))
+		})
+
+		It("should reject an invalid configuration", func() {
+			original := injector.getConfig()
+
+			writeConfig(`
+kubeconfig:
+  exec:
+    interactiveMode: Sometimes
+`)
+			watcher.Reload()
+
+			Expect(injector.getConfig()).To(BeIdenticalTo(original))
+		})
+
+		It("should reject a configuration that changes fields which cannot be changed at runtime", func() {
+			original := injector.getConfig()
+
+			writeConfig(`
+controllers:
+  shoot:
+    maxConcurrentReconciles: 100
+    quotaExceededRetryDelay: 2h
+`)
+			watcher.Reload()
+
+			Expect(injector.getConfig()).To(BeIdenticalTo(original))
+		})
+	})
+
+	Describe("#Start", func() {
+		It("should reload the configuration when the file changed", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			go func() {
+				defer GinkgoRecover()
+				Expect(watcher.Start(ctx)).To(Succeed())
+			}()
+
+			Eventually(func() time.Duration {
+				// the file is written repeatedly, as the watch might not be established yet
+				writeConfig(`
+controllers:
+  shoot:
+    quotaExceededRetryDelay: 3h
+`)
+				return injector.getConfig().Controllers.Shoot.QuotaExceededRetryDelay.Duration
+			}).Should(Equal(3 * time.Hour))
+		})
+	})
+})
@@ -0,0 +1,275 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// FairDispatcher limits the number of concurrently processed requests in total and per namespace.
+// Requests that cannot be admitted wait in a FIFO queue per namespace. Freed slots are handed out round-robin across the namespaces
+// with waiting requests, so that one busy namespace does not starve the others. The slot of a dispatched request is reserved for it
+// and the request is passed to Enqueue, so that it is processed again.
+type FairDispatcher struct {
+	// limits returns the maximum number of requests processed concurrently in total and per namespace.
+	// It is called on each admission, so that changes of the limits take effect without a restart.
+	limits func() (total int, perNamespace int)
+	// enqueue enqueues a dispatched request again, it must return once the context is done
+	enqueue func(ctx context.Context, req types.NamespacedName) error
+
+	// InFlightChanged is called with the number of admitted requests of a namespace whenever it changes, e.g. to update metrics
+	InFlightChanged func(namespace string, inFlight int)
+
+	mutex sync.Mutex
+	// inFlight is the number of admitted and reserved requests
+	inFlight int
+	// inFlightPerNamespace is the number of admitted and reserved requests per namespace
+	inFlightPerNamespace map[string]int
+	// waiting holds the waiting requests per namespace in the order they arrived
+	waiting map[string][]types.NamespacedName
+	// namespaces holds the namespaces with waiting requests in round-robin order, the first namespace is served next
+	namespaces []string
+	// reserved holds the dispatched requests, for which a slot is reserved
+	reserved map[types.NamespacedName]bool
+	// dispatched holds the dispatched requests that have not been enqueued yet
+	dispatched []types.NamespacedName
+	// notify signals that requests have been dispatched
+	notify chan struct{}
+}
+
+// NewFairDispatcher returns a FairDispatcher with the given limits, which passes dispatched requests to the given enqueue function.
+// The dispatched requests are only enqueued while the dispatcher is started.
+func NewFairDispatcher(limits func() (total int, perNamespace int), enqueue func(ctx context.Context, req types.NamespacedName) error) *FairDispatcher {
+	return &FairDispatcher{
+		limits:               limits,
+		enqueue:              enqueue,
+		inFlightPerNamespace: make(map[string]int),
+		waiting:              make(map[string][]types.NamespacedName),
+		reserved:             make(map[types.NamespacedName]bool),
+		notify:               make(chan struct{}, 1),
+	}
+}
+
+// Admit returns true in case the given request may be processed, i.e. a slot was reserved for it or the limits are not reached.
+// Otherwise, the request waits for a free slot and false is returned. Each admitted request must be finished with Done.
+func (d *FairDispatcher) Admit(req types.NamespacedName) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.reserved[req] {
+		delete(d.reserved, req)
+		return true
+	}
+
+	// hand out slots that became available because the limits were increased
+	d.dispatch()
+
+	total, perNamespace := d.limits()
+	if d.inFlight < total && d.inFlightPerNamespace[req.Namespace] < perNamespace {
+		// no request is waiting for this slot, otherwise it would have been dispatched
+		d.removeWaiting(req)
+		d.setInFlight(req.Namespace, d.inFlightPerNamespace[req.Namespace]+1)
+
+		return true
+	}
+
+	d.addWaiting(req)
+
+	return false
+}
+
+// Done releases the slot of an admitted request of the given namespace and dispatches waiting requests.
+// An error is returned in case no request of the namespace is admitted.
+func (d *FairDispatcher) Done(namespace string) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.inFlightPerNamespace[namespace] == 0 {
+		return fmt.Errorf("no request of namespace %s is in flight", namespace)
+	}
+
+	d.setInFlight(namespace, d.inFlightPerNamespace[namespace]-1)
+	d.dispatch()
+
+	return nil
+}
+
+// Waiting returns the waiting requests of the given namespace
+func (d *FairDispatcher) Waiting(namespace string) []types.NamespacedName {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return append([]types.NamespacedName(nil), d.waiting[namespace]...)
+}
+
+// InFlight returns the number of admitted and reserved requests of the given namespace
+func (d *FairDispatcher) InFlight(namespace string) int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.inFlightPerNamespace[namespace]
+}
+
+// Start enqueues the dispatched requests until the context is done. Afterwards, the state of the dispatcher is reset,
+// so that no slot stays reserved for requests that are not enqueued anymore.
+func (d *FairDispatcher) Start(ctx context.Context) error {
+	defer d.reset()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-d.notify:
+		}
+
+		d.mutex.Lock()
+		dispatched := d.dispatched
+		d.dispatched = nil
+		d.mutex.Unlock()
+
+		for _, req := range dispatched {
+			if err := d.enqueue(ctx, req); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+
+				return fmt.Errorf("failed to enqueue request %s: %w", req, err)
+			}
+		}
+	}
+}
+
+// dispatch reserves free slots for waiting requests. The namespaces with waiting requests are served round-robin,
+// skipping namespaces that reached their limit. The caller must hold the mutex.
+func (d *FairDispatcher) dispatch() {
+	total, perNamespace := d.limits()
+	dispatched := false
+
+	for d.inFlight < total {
+		served := false
+
+		for i, namespace := range d.namespaces {
+			if d.inFlightPerNamespace[namespace] >= perNamespace {
+				continue
+			}
+
+			waiting := d.waiting[namespace]
+			req := waiting[0]
+
+			// the namespace is moved to the end of the round-robin order, or removed in case no request is waiting anymore
+			d.namespaces = append(d.namespaces[:i:i], d.namespaces[i+1:]...)
+			if len(waiting) == 1 {
+				delete(d.waiting, namespace)
+			} else {
+				d.waiting[namespace] = waiting[1:]
+				d.namespaces = append(d.namespaces, namespace)
+			}
+
+			d.reserved[req] = true
+			d.dispatched = append(d.dispatched, req)
+			d.setInFlight(namespace, d.inFlightPerNamespace[namespace]+1)
+
+			served = true
+			dispatched = true
+
+			break
+		}
+
+		if !served {
+			break
+		}
+	}
+
+	if dispatched {
+		select {
+		case d.notify <- struct{}{}:
+		default:
+			// the dispatcher is already notified
+		}
+	}
+}
+
+// addWaiting adds the given request to the waiting requests of its namespace, unless it is already waiting. The caller must hold the mutex.
+func (d *FairDispatcher) addWaiting(req types.NamespacedName) {
+	waiting, ok := d.waiting[req.Namespace]
+	if !ok {
+		d.namespaces = append(d.namespaces, req.Namespace)
+	}
+
+	for _, w := range waiting {
+		if w == req {
+			return
+		}
+	}
+
+	d.waiting[req.Namespace] = append(waiting, req)
+}
+
+// removeWaiting removes the given request from the waiting requests of its namespace. The caller must hold the mutex.
+func (d *FairDispatcher) removeWaiting(req types.NamespacedName) {
+	waiting := d.waiting[req.Namespace]
+
+	for i, w := range waiting {
+		if w != req {
+			continue
+		}
+
+		if len(waiting) > 1 {
+			d.waiting[req.Namespace] = append(waiting[:i:i], waiting[i+1:]...)
+			return
+		}
+
+		delete(d.waiting, req.Namespace)
+
+		for j, namespace := range d.namespaces {
+			if namespace == req.Namespace {
+				d.namespaces = append(d.namespaces[:j:j], d.namespaces[j+1:]...)
+				break
+			}
+		}
+
+		return
+	}
+}
+
+// setInFlight sets the number of admitted and reserved requests of the given namespace. The caller must hold the mutex.
+func (d *FairDispatcher) setInFlight(namespace string, inFlight int) {
+	d.inFlight += inFlight - d.inFlightPerNamespace[namespace]
+
+	if inFlight == 0 {
+		delete(d.inFlightPerNamespace, namespace)
+	} else {
+		d.inFlightPerNamespace[namespace] = inFlight
+	}
+
+	if d.InFlightChanged != nil {
+		d.InFlightChanged(namespace, inFlight)
+	}
+}
+
+// reset forgets all admitted, reserved and waiting requests
+func (d *FairDispatcher) reset() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for namespace := range d.inFlightPerNamespace {
+		if d.InFlightChanged != nil {
+			d.InFlightChanged(namespace, 0)
+		}
+	}
+
+	d.inFlight = 0
+	d.inFlightPerNamespace = make(map[string]int)
+	d.waiting = make(map[string][]types.NamespacedName)
+	d.namespaces = nil
+	d.reserved = make(map[types.NamespacedName]bool)
+	d.dispatched = nil
+}
@@ -0,0 +1,125 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/gardener/gardenlogin-controller-manager/internal/util"
+)
+
+var _ = Describe("FairDispatcher", func() {
+	var (
+		total, perNamespace int
+		enqueued            chan types.NamespacedName
+		dispatcher          *util.FairDispatcher
+	)
+
+	req := func(namespace, name string) types.NamespacedName {
+		return types.NamespacedName{Namespace: namespace, Name: name}
+	}
+
+	BeforeEach(func() {
+		total, perNamespace = 2, 1
+		enqueued = make(chan types.NamespacedName, 10)
+
+		dispatcher = util.NewFairDispatcher(
+			func() (int, int) { return total, perNamespace },
+			func(ctx context.Context, req types.NamespacedName) error {
+				select {
+				case enqueued <- req:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			},
+		)
+	})
+
+	It("should let requests wait only once in case the namespace is saturated", func() {
+		Expect(dispatcher.Admit(req("garden-foo", "first"))).To(BeTrue())
+		Expect(dispatcher.Admit(req("garden-foo", "second"))).To(BeFalse())
+		Expect(dispatcher.Admit(req("garden-foo", "second"))).To(BeFalse())
+
+		Expect(dispatcher.Waiting("garden-foo")).To(Equal([]types.NamespacedName{req("garden-foo", "second")}))
+	})
+
+	It("should not limit requests of other namespaces", func() {
+		Expect(dispatcher.Admit(req("garden-foo", "first"))).To(BeTrue())
+		Expect(dispatcher.Admit(req("garden-bar", "first"))).To(BeTrue())
+	})
+
+	It("should reserve the slot of a finished request for the first waiting request", func() {
+		Expect(dispatcher.Admit(req("garden-foo", "first"))).To(BeTrue())
+		Expect(dispatcher.Admit(req("garden-foo", "second"))).To(BeFalse())
+		Expect(dispatcher.Admit(req("garden-foo", "third"))).To(BeFalse())
+
+		Expect(dispatcher.Done("garden-foo")).To(Succeed())
+		Expect(dispatcher.InFlight("garden-foo")).To(Equal(1))
+
+		By("ensuring that the slot is reserved for the waiting request")
+		Expect(dispatcher.Admit(req("garden-foo", "first"))).To(BeFalse())
+		Expect(dispatcher.Admit(req("garden-foo", "second"))).To(BeTrue())
+		Expect(dispatcher.Waiting("garden-foo")).To(Equal([]types.NamespacedName{req("garden-foo", "third"), req("garden-foo", "first")}))
+	})
+
+	It("should hand out free slots round-robin across the namespaces", func() {
+		perNamespace = 2
+
+		Expect(dispatcher.Admit(req("garden-foo", "1"))).To(BeTrue())
+		Expect(dispatcher.Admit(req("garden-foo", "2"))).To(BeTrue())
+		Expect(dispatcher.Admit(req("garden-foo", "3"))).To(BeFalse())
+		Expect(dispatcher.Admit(req("garden-foo", "4"))).To(BeFalse())
+		Expect(dispatcher.Admit(req("garden-bar", "1"))).To(BeFalse())
+
+		By("ensuring that the quiet namespace is served before the second request of the busy namespace")
+		Expect(dispatcher.Done("garden-foo")).To(Succeed())
+		Expect(dispatcher.Admit(req("garden-foo", "3"))).To(BeTrue())
+		Expect(dispatcher.Done("garden-foo")).To(Succeed())
+		Expect(dispatcher.Admit(req("garden-foo", "4"))).To(BeFalse())
+		Expect(dispatcher.Admit(req("garden-bar", "1"))).To(BeTrue())
+	})
+
+	It("should dispatch waiting requests once the limits are increased", func() {
+		Expect(dispatcher.Admit(req("garden-foo", "first"))).To(BeTrue())
+		Expect(dispatcher.Admit(req("garden-foo", "second"))).To(BeFalse())
+
+		perNamespace = 2
+
+		Expect(dispatcher.Admit(req("garden-foo", "third"))).To(BeFalse())
+		Expect(dispatcher.Admit(req("garden-foo", "second"))).To(BeTrue())
+	})
+
+	It("should return an error in case no request of the namespace is in flight", func() {
+		Expect(dispatcher.Done("garden-foo")).ToNot(Succeed())
+	})
+
+	It("should enqueue dispatched requests until it is stopped", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+
+		go func() {
+			done <- dispatcher.Start(ctx)
+		}()
+
+		Expect(dispatcher.Admit(req("garden-foo", "first"))).To(BeTrue())
+		Expect(dispatcher.Admit(req("garden-foo", "second"))).To(BeFalse())
+		Expect(dispatcher.Done("garden-foo")).To(Succeed())
+
+		Eventually(enqueued).Should(Receive(Equal(req("garden-foo", "second"))))
+
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+
+		By("ensuring that the reserved slot is released")
+		Expect(dispatcher.InFlight("garden-foo")).To(BeZero())
+	})
+})
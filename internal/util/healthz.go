@@ -0,0 +1,113 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// cacheSyncCheckTimeout is the maximum duration the CacheSyncCheck waits for an informer
+const cacheSyncCheckTimeout = time.Second
+
+// CacheSyncCheck returns a readiness check that fails in case the informers of the given cache for the given objects have not synced yet.
+// The informers are created in case they do not exist yet, hence only objects that are watched anyhow should be passed.
+func CacheSyncCheck(c cache.Cache, objs ...client.Object) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), cacheSyncCheckTimeout)
+		defer cancel()
+
+		for _, obj := range objs {
+			informer, err := c.GetInformer(ctx, obj)
+			if err != nil {
+				return fmt.Errorf("failed to get informer for %T: %w", obj, err)
+			}
+
+			if !informer.HasSynced() {
+				return fmt.Errorf("informer for %T has not synced yet", obj)
+			}
+		}
+
+		return nil
+	}
+}
+
+// CertificateCheck returns a readiness check that fails in case the certificate and key in the given files cannot be loaded
+// or the certificate expires within the given minimum validity. The expiry is additionally exposed by the CertificateNotAfterGauge.
+// The files are read on each check, so that certificates that are rotated on disk are picked up.
+func CertificateCheck(certFile string, keyFile string, minValidity time.Duration) healthz.Checker {
+	return func(_ *http.Request) error {
+		certificate, err := loadCertificate(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+
+		if notAfter := certificate.NotAfter; time.Now().Add(minValidity).After(notAfter) {
+			return fmt.Errorf("certificate expires at %s, which is within %s", notAfter.UTC().Format(time.RFC3339), minValidity)
+		}
+
+		return nil
+	}
+}
+
+// CertificateNotAfterGauge returns a gauge with the given options that reads the certificate and key in the given files on each scrape.
+// The gauge is the expiry of the certificate in seconds since the epoch, so that an upcoming expiry can be alerted on before the
+// CertificateCheck fails. It is 0 in case the certificate cannot be loaded.
+func CertificateNotAfterGauge(opts prometheus.GaugeOpts, certFile string, keyFile string) prometheus.GaugeFunc {
+	return prometheus.NewGaugeFunc(opts, func() float64 {
+		certificate, err := loadCertificate(certFile, keyFile)
+		if err != nil {
+			return 0
+		}
+
+		return float64(certificate.NotAfter.Unix())
+	})
+}
+
+// loadCertificate loads the certificate and key in the given files and returns the parsed serving certificate
+func loadCertificate(certFile string, keyFile string) (*x509.Certificate, error) {
+	keyPair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	// the first certificate of the chain is the serving certificate
+	certificate, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	return certificate, nil
+}
+
+// ReadinessGauge returns a gauge with the given options that runs the given checks on each scrape. The gauge is 1 in case all checks succeed, otherwise 0.
+// It exposes the readiness of components that must not gate the readiness of the manager, e.g. additional garden clusters.
+func ReadinessGauge(opts prometheus.GaugeOpts, checks ...healthz.Checker) prometheus.GaugeFunc {
+	return prometheus.NewGaugeFunc(opts, func() float64 {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/readyz", nil)
+		if err != nil {
+			return 0
+		}
+
+		for _, check := range checks {
+			if err := check(req); err != nil {
+				return 0
+			}
+		}
+
+		return 1
+	})
+}
@@ -0,0 +1,162 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gardener/gardener/pkg/utils"
+	"github.com/gardener/gardener/pkg/utils/secrets"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+
+	"github.com/gardener/gardenlogin-controller-manager/internal/util"
+)
+
+var _ = Describe("healthz", func() {
+	var req *http.Request
+
+	BeforeEach(func() {
+		req = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	})
+
+	Describe("#CacheSyncCheck", func() {
+		var informers *informertest.FakeInformers
+
+		synced := func(obj runtime.Object, synced bool) {
+			informer, err := informers.FakeInformerFor(obj)
+			Expect(err).ToNot(HaveOccurred())
+
+			informer.Synced = synced
+		}
+
+		BeforeEach(func() {
+			informers = &informertest.FakeInformers{}
+		})
+
+		It("should succeed once the informers have synced", func() {
+			synced(&corev1.ConfigMap{}, true)
+			synced(&corev1.ResourceQuota{}, true)
+
+			Expect(util.CacheSyncCheck(informers, &corev1.ConfigMap{}, &corev1.ResourceQuota{})(req)).To(Succeed())
+		})
+
+		It("should fail as long as an informer has not synced", func() {
+			synced(&corev1.ConfigMap{}, true)
+			synced(&corev1.ResourceQuota{}, false)
+
+			Expect(util.CacheSyncCheck(informers, &corev1.ConfigMap{}, &corev1.ResourceQuota{})(req)).To(MatchError(ContainSubstring("ResourceQuota has not synced yet")))
+		})
+	})
+
+	Describe("#CertificateCheck", func() {
+		var (
+			certFile, keyFile string
+			notAfter          time.Time
+		)
+
+		// writeCertificate writes a certificate with the given validity, a negative validity yields an expired certificate
+		writeCertificate := func(validity time.Duration) {
+			csc := &secrets.CertificateSecretConfig{
+				Name:       "webhook",
+				CommonName: "webhook",
+				CertType:   secrets.CACert,
+				Validity:   &validity,
+			}
+
+			cert, err := csc.GenerateCertificate()
+			Expect(err).ToNot(HaveOccurred())
+
+			certificate, err := utils.DecodeCertificate(cert.CertificatePEM)
+			Expect(err).ToNot(HaveOccurred())
+			notAfter = certificate.NotAfter
+
+			Expect(os.WriteFile(certFile, cert.CertificatePEM, 0600)).To(Succeed())
+			Expect(os.WriteFile(keyFile, cert.PrivateKeyPEM, 0600)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			dir, err := os.MkdirTemp("", "certificate-check")
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(os.RemoveAll, dir)
+
+			certFile = filepath.Join(dir, "tls.crt")
+			keyFile = filepath.Join(dir, "tls.key")
+
+			writeCertificate(time.Hour)
+		})
+
+		It("should succeed for a certificate that does not expire within the minimum validity", func() {
+			Expect(util.CertificateCheck(certFile, keyFile, 30*time.Minute)(req)).To(Succeed())
+		})
+
+		It("should fail for a certificate that expires within the minimum validity", func() {
+			Expect(util.CertificateCheck(certFile, keyFile, 2*time.Hour)(req)).To(MatchError(ContainSubstring("certificate expires at")))
+		})
+
+		It("should fail for an expired certificate", func() {
+			writeCertificate(-time.Hour)
+
+			Expect(util.CertificateCheck(certFile, keyFile, 0)(req)).To(MatchError(ContainSubstring("certificate expires at")))
+		})
+
+		It("should fail in case the certificate cannot be loaded", func() {
+			Expect(os.Remove(keyFile)).To(Succeed())
+
+			Expect(util.CertificateCheck(certFile, keyFile, 30*time.Minute)(req)).To(MatchError(ContainSubstring("failed to load certificate")))
+		})
+
+		Describe("#CertificateNotAfterGauge", func() {
+			var opts prometheus.GaugeOpts
+
+			BeforeEach(func() {
+				opts = prometheus.GaugeOpts{Name: "certificate_not_after_seconds"}
+			})
+
+			It("should be the expiry of the certificate", func() {
+				Expect(testutil.ToFloat64(util.CertificateNotAfterGauge(opts, certFile, keyFile))).To(Equal(float64(notAfter.Unix())))
+			})
+
+			It("should be 0 in case the certificate cannot be loaded", func() {
+				Expect(os.Remove(keyFile)).To(Succeed())
+
+				Expect(testutil.ToFloat64(util.CertificateNotAfterGauge(opts, certFile, keyFile))).To(Equal(0.0))
+			})
+		})
+	})
+
+	Describe("#ReadinessGauge", func() {
+		var (
+			opts    prometheus.GaugeOpts
+			failing healthz.Checker
+		)
+
+		BeforeEach(func() {
+			opts = prometheus.GaugeOpts{Name: "ready"}
+			failing = func(_ *http.Request) error { return errors.New("not ready") }
+		})
+
+		It("should be 1 in case all checks succeed", func() {
+			Expect(testutil.ToFloat64(util.ReadinessGauge(opts, healthz.Ping, healthz.Ping))).To(Equal(1.0))
+		})
+
+		It("should be 0 in case a check fails", func() {
+			Expect(testutil.ToFloat64(util.ReadinessGauge(opts, healthz.Ping, failing))).To(Equal(0.0))
+		})
+	})
+})
@@ -6,7 +6,92 @@ SPDX-License-Identifier: Apache-2.0
 
 package util
 
-import corev1 "k8s.io/api/core/v1"
+import (
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+)
+
+// ErrQuotaStatusOutdated is returned by HasSufficientQuota in case the status of a resource quota does not yet reflect its spec,
+// e.g. because the quota was just created or changed and the quota controller did not yet calculate the usage
+var ErrQuotaStatusOutdated = errors.New("resource quota status is not up-to-date")
+
+// ConfigMapQuotaUsage returns the usage of one configMap in the resource quotas of a namespace. ConfigMaps are counted both with the
+// object count resource name count/configmaps and the legacy resource name configmaps.
+// The size of a configMap is not part of the usage, as Kubernetes does not account it in any quota resource.
+func ConfigMapQuotaUsage() corev1.ResourceList {
+	return corev1.ResourceList{
+		"count/configmaps":        resource.MustParse("1"),
+		corev1.ResourceConfigMaps: resource.MustParse("1"),
+	}
+}
+
+// HasSufficientQuota returns true in case the given resource quotas allow the requested usage for each resource of the request.
+// Quotas with scopes or a scope selector are skipped, as they only track resources supporting scopes, i.e. pods.
+// An error wrapping ErrQuotaStatusOutdated is returned in case the status of a quota limiting a requested resource is not up-to-date.
+func HasSufficientQuota(resourceQuotas []corev1.ResourceQuota, requested corev1.ResourceList) (bool, error) {
+	available, _, err := AvailableQuota(resourceQuotas, requested)
+	if err != nil {
+		return false, err
+	}
+
+	return available != 0, nil
+}
+
+// AvailableQuota returns how many times the requested usage fits into the given resource quotas and the quota that allows the fewest.
+// -1 and nil are returned in case no quota limits the requested resources. Quotas are evaluated like for HasSufficientQuota.
+func AvailableQuota(resourceQuotas []corev1.ResourceQuota, requested corev1.ResourceList) (int64, *corev1.ResourceQuota, error) {
+	var (
+		available int64 = -1
+		limiting  *corev1.ResourceQuota
+	)
+
+	requestedNames := quotav1.ResourceNames(requested)
+
+	for i := range resourceQuotas {
+		resourceQuota := &resourceQuotas[i]
+
+		if IsScopedQuota(resourceQuota) {
+			continue
+		}
+
+		for _, name := range quotav1.Intersection(quotav1.ResourceNames(resourceQuota.Spec.Hard), requestedNames) {
+			hard, ok := resourceQuota.Status.Hard[name]
+			if !ok || hard.Cmp(resourceQuota.Spec.Hard[name]) != 0 {
+				return 0, nil, fmt.Errorf("%w: hard %s of resource quota %s does not match its spec", ErrQuotaStatusOutdated, name, resourceQuota.Name)
+			}
+
+			used, ok := resourceQuota.Status.Used[name]
+			if !ok {
+				return 0, nil, fmt.Errorf("%w: used %s of resource quota %s is unknown", ErrQuotaStatusOutdated, name, resourceQuota.Name)
+			}
+
+			free := hard.DeepCopy()
+			free.Sub(used)
+
+			var fits int64
+			if request := requested[name]; free.Sign() > 0 && request.Sign() > 0 {
+				fits = free.MilliValue() / request.MilliValue()
+			}
+
+			if available < 0 || fits < available {
+				available = fits
+				limiting = resourceQuota
+			}
+		}
+	}
+
+	return available, limiting, nil
+}
+
+// IsScopedQuota returns true in case the given resource quota has scopes or a scope selector. Kubernetes only charges resources
+// supporting scopes, i.e. pods, against such quotas, hence they never limit configMaps.
+func IsScopedQuota(resourceQuota *corev1.ResourceQuota) bool {
+	return len(resourceQuota.Spec.Scopes) > 0 || (resourceQuota.Spec.ScopeSelector != nil && len(resourceQuota.Spec.ScopeSelector.MatchExpressions) > 0)
+}
 
 // LessThan returns true if a < b for each key in b
 func LessThan(a corev1.ResourceList, b corev1.ResourceList) bool {
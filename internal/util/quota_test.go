@@ -7,15 +7,28 @@ SPDX-License-Identifier: Apache-2.0
 package util_test
 
 import (
+	"errors"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/gardener/gardenlogin-controller-manager/internal/util"
 )
 
 var _ = Describe("Quota", func() {
+	quota := func(name corev1.ResourceName, hard, used string) corev1.ResourceQuota {
+		return corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "quota"},
+			Spec:       corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{name: resource.MustParse(hard)}},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{name: resource.MustParse(hard)},
+				Used: corev1.ResourceList{name: resource.MustParse(used)},
+			},
+		}
+	}
 
 	DescribeTable("x less than y",
 		func(x corev1.ResourceList, y corev1.ResourceList, expected bool) {
@@ -26,4 +39,85 @@ var _ = Describe("Quota", func() {
 		Entry("x < y", corev1.ResourceList{"foo/bar": resource.MustParse("1")}, corev1.ResourceList{"foo/bar": resource.MustParse("2")}, true),
 		Entry("x has different resource name than y", corev1.ResourceList{"foo/bar": resource.MustParse("1")}, corev1.ResourceList{"bar/baz": resource.MustParse("2")}, true),
 	)
+
+	Describe("#HasSufficientQuota", func() {
+		DescribeTable("configMap quotas",
+			func(resourceQuota corev1.ResourceQuota, expected bool) {
+				Expect(util.HasSufficientQuota([]corev1.ResourceQuota{resourceQuota}, util.ConfigMapQuotaUsage())).To(Equal(expected))
+			},
+			Entry("count/configmaps available", quota("count/configmaps", "2", "1"), true),
+			Entry("count/configmaps exhausted", quota("count/configmaps", "2", "2"), false),
+			Entry("configmaps available", quota(corev1.ResourceConfigMaps, "2", "1"), true),
+			Entry("configmaps exhausted", quota(corev1.ResourceConfigMaps, "2", "2"), false),
+			Entry("other resource exhausted", quota("count/secrets", "2", "2"), true),
+		)
+
+		It("should skip quotas with scopes", func() {
+			scoped := quota("count/configmaps", "1", "1")
+			scoped.Spec.Scopes = []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeBestEffort}
+
+			selected := quota(corev1.ResourceConfigMaps, "1", "1")
+			selected.Spec.ScopeSelector = &corev1.ScopeSelector{MatchExpressions: []corev1.ScopedResourceSelectorRequirement{{
+				ScopeName: corev1.ResourceQuotaScopePriorityClass,
+				Operator:  corev1.ScopeSelectorOpExists,
+			}}}
+
+			Expect(util.HasSufficientQuota([]corev1.ResourceQuota{scoped, selected}, util.ConfigMapQuotaUsage())).To(BeTrue())
+		})
+
+		It("should not skip quotas with an empty scope selector", func() {
+			selected := quota("count/configmaps", "1", "1")
+			selected.Spec.ScopeSelector = &corev1.ScopeSelector{}
+
+			Expect(util.HasSufficientQuota([]corev1.ResourceQuota{selected}, util.ConfigMapQuotaUsage())).To(BeFalse())
+		})
+
+		It("should check the requested quantity of each resource", func() {
+			storage := quota("example.com/storage", "1Mi", "512Ki")
+
+			Expect(util.HasSufficientQuota([]corev1.ResourceQuota{storage}, corev1.ResourceList{"example.com/storage": resource.MustParse("256Ki")})).To(BeTrue())
+			Expect(util.HasSufficientQuota([]corev1.ResourceQuota{storage}, corev1.ResourceList{"example.com/storage": resource.MustParse("768Ki")})).To(BeFalse())
+		})
+
+		It("should return ErrQuotaStatusOutdated in case the status does not reflect the spec", func() {
+			outdated := quota("count/configmaps", "2", "1")
+			outdated.Spec.Hard["count/configmaps"] = resource.MustParse("3")
+
+			unknownUsage := quota(corev1.ResourceConfigMaps, "2", "1")
+			unknownUsage.Status.Used = nil
+
+			for _, resourceQuota := range []corev1.ResourceQuota{outdated, unknownUsage} {
+				_, err := util.HasSufficientQuota([]corev1.ResourceQuota{resourceQuota}, util.ConfigMapQuotaUsage())
+				Expect(errors.Is(err, util.ErrQuotaStatusOutdated)).To(BeTrue())
+			}
+		})
+	})
+
+	Describe("#AvailableQuota", func() {
+		It("should return the quota that allows the fewest configMaps", func() {
+			resourceQuotas := []corev1.ResourceQuota{
+				quota("count/configmaps", "10", "5"),
+				quota(corev1.ResourceConfigMaps, "4", "2"),
+			}
+			resourceQuotas[1].Name = "legacy"
+
+			available, limiting, err := util.AvailableQuota(resourceQuotas, util.ConfigMapQuotaUsage())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(available).To(BeEquivalentTo(2))
+			Expect(limiting.Name).To(Equal("legacy"))
+		})
+
+		It("should return -1 in case no quota limits configMaps", func() {
+			available, limiting, err := util.AvailableQuota([]corev1.ResourceQuota{quota("count/secrets", "1", "1")}, util.ConfigMapQuotaUsage())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(available).To(BeEquivalentTo(-1))
+			Expect(limiting).To(BeNil())
+		})
+
+		It("should return 0 in case the usage exceeds the quota", func() {
+			available, _, err := util.AvailableQuota([]corev1.ResourceQuota{quota("count/configmaps", "1", "3")}, util.ConfigMapQuotaUsage())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(available).To(BeEquivalentTo(0))
+		})
+	})
 })
@@ -0,0 +1,52 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
+)
+
+// Resync triggers the reconciliation of all objects of a controller, e.g. after a configuration change that affects all of them.
+// The controller watches the Source and maps the resync event to the requests of all its objects.
+// Triggers that arrive while a resync is still pending are coalesced into it.
+type Resync struct {
+	events chan event.GenericEvent
+}
+
+// NewResync returns a new Resync
+func NewResync() *Resync {
+	return &Resync{
+		events: make(chan event.GenericEvent, 1),
+	}
+}
+
+// Source returns the channel source of the resync events, it must be watched by exactly one controller
+func (r *Resync) Source() source.Source {
+	return &source.Channel{Source: r.events}
+}
+
+// Trigger requests a resync. It never blocks and does nothing in case a resync is already pending.
+func (r *Resync) Trigger() {
+	select {
+	case r.events <- event.GenericEvent{Object: &metav1.PartialObjectMetadata{}}:
+	default:
+	}
+}
+
+// KubeconfigChanged returns true in case the kubeconfig section differs between the given configurations
+func KubeconfigChanged(oldConfig, newConfig *configv1alpha1.ControllerManagerConfiguration) bool {
+	if oldConfig == nil || newConfig == nil {
+		return oldConfig != newConfig
+	}
+
+	return !equality.Semantic.DeepEqual(oldConfig.Kubeconfig, newConfig.Kubeconfig)
+}
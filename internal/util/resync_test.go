@@ -0,0 +1,112 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util_test
+
+import (
+	"context"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
+	"github.com/gardener/gardenlogin-controller-manager/internal/util"
+)
+
+var _ = Describe("Resync", func() {
+	var (
+		ctx     context.Context
+		cancel  context.CancelFunc
+		resync  *util.Resync
+		queue   workqueue.RateLimitingInterface
+		resyncs int32
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		resync = util.NewResync()
+		queue = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		atomic.StoreInt32(&resyncs, 0)
+	})
+
+	AfterEach(func() {
+		cancel()
+		queue.ShutDown()
+	})
+
+	start := func() {
+		src := resync.Source()
+		_, err := inject.StopChannelInto(ctx.Done(), src)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(src.Start(ctx, handler.EnqueueRequestsFromMapFunc(func(client.Object) []reconcile.Request {
+			atomic.AddInt32(&resyncs, 1)
+
+			return []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "garden-foo", Name: "foo"}},
+				{NamespacedName: types.NamespacedName{Namespace: "garden-foo", Name: "bar"}},
+			}
+		}), queue)).To(Succeed())
+	}
+
+	It("should enqueue the requests of all objects", func() {
+		start()
+
+		resync.Trigger()
+
+		Eventually(queue.Len).Should(Equal(2))
+		Expect(atomic.LoadInt32(&resyncs)).To(Equal(int32(1)))
+	})
+
+	It("should coalesce triggers while a resync is pending", func() {
+		resync.Trigger()
+		resync.Trigger()
+		resync.Trigger()
+
+		start()
+
+		Eventually(func() int32 { return atomic.LoadInt32(&resyncs) }).Should(Equal(int32(1)))
+		Consistently(func() int32 { return atomic.LoadInt32(&resyncs) }).Should(Equal(int32(1)))
+	})
+})
+
+var _ = Describe("#KubeconfigChanged", func() {
+	var oldConfig, newConfig *configv1alpha1.ControllerManagerConfiguration
+
+	BeforeEach(func() {
+		oldConfig = &configv1alpha1.ControllerManagerConfiguration{
+			Kubeconfig: configv1alpha1.KubeconfigConfiguration{
+				Garden: configv1alpha1.GardenKubeconfigConfiguration{
+					Server: "https://api.garden.example.com",
+				},
+			},
+		}
+		newConfig = oldConfig.DeepCopy()
+	})
+
+	It("should return false in case only other sections changed", func() {
+		newConfig.Controllers.Shoot.MaxConcurrentReconciles = 42
+
+		Expect(util.KubeconfigChanged(oldConfig, newConfig)).To(BeFalse())
+	})
+
+	It("should return true in case the kubeconfig section changed", func() {
+		newConfig.Kubeconfig.Garden.Server = "https://api.other-garden.example.com"
+
+		Expect(util.KubeconfigChanged(oldConfig, newConfig)).To(BeTrue())
+	})
+
+	It("should return true in case there was no configuration before", func() {
+		Expect(util.KubeconfigChanged(nil, newConfig)).To(BeTrue())
+	})
+})
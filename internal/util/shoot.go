@@ -0,0 +1,108 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util
+
+import (
+	"context"
+	"fmt"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Shoots are read as unstructured objects, as the status.credentials field of the Shoot is not part of the vendored gardener API
+// and would be dropped when decoding into the Shoot type. The unstructured Shoots are converted into the Shoot type for all other fields.
+
+// NewClient returns a client that reads from the given cache, including unstructured objects, so that the unstructured Shoots are cached.
+// It is used as NewClient function of the managers.
+func NewClient(cache cache.Cache, config *rest.Config, options client.Options, uncachedObjects ...client.Object) (client.Client, error) {
+	c, err := client.New(config, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.NewDelegatingClient(client.NewDelegatingClientInput{
+		CacheReader:       cache,
+		Client:            c,
+		UncachedObjects:   uncachedObjects,
+		CacheUnstructured: true,
+	})
+}
+
+// NewShoot returns an empty unstructured Shoot, e.g. to read or watch Shoots
+func NewShoot() *unstructured.Unstructured {
+	shoot := &unstructured.Unstructured{}
+	shoot.SetGroupVersionKind(gardencorev1beta1.SchemeGroupVersion.WithKind("Shoot"))
+
+	return shoot
+}
+
+// NewShootList returns an empty unstructured ShootList
+func NewShootList() *unstructured.UnstructuredList {
+	shootList := &unstructured.UnstructuredList{}
+	shootList.SetGroupVersionKind(gardencorev1beta1.SchemeGroupVersion.WithKind("ShootList"))
+
+	return shootList
+}
+
+// ShootFromUnstructured converts the given unstructured Shoot into the Shoot type of the vendored gardener API
+func ShootFromUnstructured(obj *unstructured.Unstructured) (*gardencorev1beta1.Shoot, error) {
+	shoot := &gardencorev1beta1.Shoot{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, shoot); err != nil {
+		return nil, fmt.Errorf("failed to convert shoot %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+
+	return shoot, nil
+}
+
+// GetShoot reads the Shoot with the given key. It returns the Shoot together with the phase of its certificate authorities rotation, see CARotationPhase.
+func GetShoot(ctx context.Context, c client.Reader, key client.ObjectKey) (*gardencorev1beta1.Shoot, string, error) {
+	obj := NewShoot()
+	if err := c.Get(ctx, key, obj); err != nil {
+		return nil, "", err
+	}
+
+	shoot, err := ShootFromUnstructured(obj)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return shoot, CARotationPhase(obj), nil
+}
+
+// ListShoots lists the Shoots matching the given options
+func ListShoots(ctx context.Context, c client.Reader, opts ...client.ListOption) ([]gardencorev1beta1.Shoot, error) {
+	shootList := NewShootList()
+	if err := c.List(ctx, shootList, opts...); err != nil {
+		return nil, err
+	}
+
+	shoots := make([]gardencorev1beta1.Shoot, 0, len(shootList.Items))
+
+	for i := range shootList.Items {
+		shoot, err := ShootFromUnstructured(&shootList.Items[i])
+		if err != nil {
+			return nil, err
+		}
+
+		shoots = append(shoots, *shoot)
+	}
+
+	return shoots, nil
+}
+
+// CARotationPhase returns the phase of the certificate authorities rotation from the status.credentials.rotation.certificateAuthorities field
+// of the given unstructured Shoot. An empty string is returned in case no rotation was ever triggered.
+func CARotationPhase(shoot *unstructured.Unstructured) string {
+	phase, _, _ := unstructured.NestedString(shoot.Object, "status", "credentials", "rotation", "certificateAuthorities", "phase")
+
+	return phase
+}
@@ -0,0 +1,88 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util_test
+
+import (
+	"context"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/gardener/gardenlogin-controller-manager/internal/util"
+)
+
+var _ = Describe("Shoot", func() {
+	var (
+		ctx   context.Context
+		shoot *unstructured.Unstructured
+		c     client.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		shoot = &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": gardencorev1beta1.SchemeGroupVersion.String(),
+			"kind":       "Shoot",
+			"metadata": map[string]interface{}{
+				"name":      "foo",
+				"namespace": "garden-dev",
+			},
+			"spec": map[string]interface{}{
+				"kubernetes": map[string]interface{}{
+					"version": "1.22.2",
+				},
+			},
+			"status": map[string]interface{}{
+				"credentials": map[string]interface{}{
+					"rotation": map[string]interface{}{
+						"certificateAuthorities": map[string]interface{}{
+							"phase": util.CARotationPhasePrepared,
+						},
+					},
+				},
+			},
+		}}
+		// the fake client would drop the status.credentials field when converting to the Shoot type of the gardener scheme
+		c = fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(shoot).Build()
+	})
+
+	Describe("#CARotationPhase", func() {
+		It("should read the phase from the shoot status", func() {
+			Expect(util.CARotationPhase(shoot)).To(Equal(util.CARotationPhasePrepared))
+		})
+
+		It("should return an empty phase in case the certificate authorities were never rotated", func() {
+			Expect(util.CARotationPhase(util.NewShoot())).To(BeEmpty())
+		})
+	})
+
+	Describe("#GetShoot", func() {
+		It("should return the shoot together with the rotation phase", func() {
+			s, rotationPhase, err := util.GetShoot(ctx, c, client.ObjectKey{Namespace: "garden-dev", Name: "foo"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(s.Name).To(Equal("foo"))
+			Expect(s.Spec.Kubernetes.Version).To(Equal("1.22.2"))
+			Expect(rotationPhase).To(Equal(util.CARotationPhasePrepared))
+		})
+	})
+
+	Describe("#ListShoots", func() {
+		It("should return the shoots converted into the Shoot type", func() {
+			shoots, err := util.ListShoots(ctx, c, client.InNamespace("garden-dev"))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(shoots).To(HaveLen(1))
+			Expect(shoots[0].Spec.Kubernetes.Version).To(Equal("1.22.2"))
+		})
+	})
+})
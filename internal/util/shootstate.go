@@ -0,0 +1,96 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"errors"
+
+	gardencorev1alpha1 "github.com/gardener/gardener/pkg/apis/core/v1alpha1"
+	corev1alpha1helper "github.com/gardener/gardener/pkg/apis/core/v1alpha1/helper"
+	corev1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	"github.com/gardener/gardener/pkg/utils/secrets"
+)
+
+const (
+	// shootStateNameCABundle is the name of the gardener resource data holding the ca bundle of the shoot, which contains
+	// the old and the new certificate authority during a rotation of the certificate authorities
+	shootStateNameCABundle = "ca-bundle"
+	// dataKeyCertificateBundle is the data key of the ca bundle
+	dataKeyCertificateBundle = "bundle.crt"
+)
+
+const (
+	// CARotationPhasePreparing is the phase of the certificate authorities rotation in which the new certificate authority is created and distributed
+	CARotationPhasePreparing = "Preparing"
+	// CARotationPhasePrepared is the phase of the certificate authorities rotation in which the shoot trusts both the old and the new certificate authority
+	CARotationPhasePrepared = "Prepared"
+	// CARotationPhaseCompleting is the phase of the certificate authorities rotation in which the old certificate authority is removed
+	CARotationPhaseCompleting = "Completing"
+)
+
+// ErrCANotProvisioned is returned by ClusterCACert in case the certificate authority of the shoot is not yet stored in the ShootState
+var ErrCANotProvisioned = errors.New("certificate authority not yet provisioned")
+
+// ClusterCACert reads the ca certificate from the gardener resource data of the given ShootState
+func ClusterCACert(shootState *gardencorev1alpha1.ShootState) ([]byte, error) {
+	resourceDataList := corev1alpha1helper.GardenerResourceDataList(shootState.Spec.Gardener)
+
+	ca := resourceDataList.Get(corev1beta1constants.SecretNameCACluster)
+	if ca == nil {
+		return nil, ErrCANotProvisioned
+	}
+
+	data := make(map[string][]byte)
+	if err := json.Unmarshal(ca.Data.Raw, &data); err != nil {
+		return nil, errors.New("failed to unmarshal certificate authority from raw data")
+	}
+
+	key := secrets.DataKeyCertificateCA
+	if ca.Type == "certificate" {
+		key = "certificate"
+	}
+
+	return data[key], nil
+}
+
+// ClusterCABundle reads the ca bundle from the gardener resource data of the given ShootState.
+// It returns nil in case the ShootState does not hold a ca bundle.
+func ClusterCABundle(shootState *gardencorev1alpha1.ShootState) ([]byte, error) {
+	resourceDataList := corev1alpha1helper.GardenerResourceDataList(shootState.Spec.Gardener)
+
+	bundle := resourceDataList.Get(shootStateNameCABundle)
+	if bundle == nil {
+		return nil, nil
+	}
+
+	data := make(map[string][]byte)
+	if err := json.Unmarshal(bundle.Data.Raw, &data); err != nil {
+		return nil, errors.New("failed to unmarshal certificate authority bundle from raw data")
+	}
+
+	return data[dataKeyCertificateBundle], nil
+}
+
+// ClusterCACertificates returns the certificate authorities to publish in the kubeconfigs of the shoot. While the certificate authorities are rotated,
+// i.e. in the given rotation phase, the shoot serves with both the old and the new certificate authority and the ca bundle containing both is returned,
+// so that kubeconfigs downloaded mid-rotation keep working. Otherwise, or in case the ShootState does not hold a ca bundle, the ca certificate is returned.
+func ClusterCACertificates(shootState *gardencorev1alpha1.ShootState, rotationPhase string) ([]byte, error) {
+	switch rotationPhase {
+	case CARotationPhasePreparing, CARotationPhasePrepared, CARotationPhaseCompleting:
+		bundle, err := ClusterCABundle(shootState)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(bundle) > 0 {
+			return bundle, nil
+		}
+	}
+
+	return ClusterCACert(shootState)
+}
@@ -0,0 +1,64 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util_test
+
+import (
+	"encoding/json"
+
+	gardencorev1alpha1 "github.com/gardener/gardener/pkg/apis/core/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/gardener/gardenlogin-controller-manager/internal/util"
+)
+
+var _ = Describe("ShootState", func() {
+	var shootState *gardencorev1alpha1.ShootState
+
+	resourceData := func(name string, data map[string][]byte) gardencorev1alpha1.GardenerResourceData {
+		raw, err := json.Marshal(data)
+		Expect(err).ToNot(HaveOccurred())
+
+		return gardencorev1alpha1.GardenerResourceData{Name: name, Type: "secret", Data: runtime.RawExtension{Raw: raw}}
+	}
+
+	BeforeEach(func() {
+		shootState = &gardencorev1alpha1.ShootState{
+			Spec: gardencorev1alpha1.ShootStateSpec{
+				Gardener: []gardencorev1alpha1.GardenerResourceData{
+					resourceData("ca", map[string][]byte{"ca.crt": []byte("new")}),
+					resourceData("ca-bundle", map[string][]byte{"bundle.crt": []byte("old\nnew")}),
+				},
+			},
+		}
+	})
+
+	DescribeTable("#ClusterCACertificates",
+		func(rotationPhase string, expected string) {
+			Expect(util.ClusterCACertificates(shootState, rotationPhase)).To(BeEquivalentTo(expected))
+		},
+		Entry("no rotation", "", "new"),
+		Entry("preparing", util.CARotationPhasePreparing, "old\nnew"),
+		Entry("prepared", util.CARotationPhasePrepared, "old\nnew"),
+		Entry("completing", util.CARotationPhaseCompleting, "old\nnew"),
+		Entry("completed", "Completed", "new"),
+	)
+
+	It("should fall back to the ca certificate in case the ShootState holds no ca bundle", func() {
+		shootState.Spec.Gardener = shootState.Spec.Gardener[:1]
+
+		Expect(util.ClusterCACertificates(shootState, util.CARotationPhasePrepared)).To(BeEquivalentTo("new"))
+	})
+
+	It("should return ErrCANotProvisioned in case the ShootState holds no ca certificate", func() {
+		shootState.Spec.Gardener = nil
+
+		_, err := util.ClusterCACertificates(shootState, "")
+		Expect(err).To(MatchError(util.ErrCANotProvisioned))
+	})
+})
@@ -11,20 +11,59 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"time"
 )
 
+// ParseCertificates takes a byte slice, decodes all PEM blocks of it, ensures their type is Certificate,
+// and parses them as x509.Certificate. A bundle of several certificates, e.g. during a rotation of the certificate authorities, is supported.
+// In case the byte slice contains no certificate or a block cannot be parsed, an error is returned.
+func ParseCertificates(bytes []byte) ([]*x509.Certificate, error) {
+	var certificates []*x509.Certificate
+
+	for rest := bytes; ; {
+		var block *pem.Block
+
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			return nil, errors.New("PEM block type must be CERTIFICATE")
+		}
+
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate %d: %w", len(certificates), err)
+		}
+
+		certificates = append(certificates, certificate)
+	}
+
+	if len(certificates) == 0 {
+		return nil, errors.New("PEM block type must be CERTIFICATE")
+	}
+
+	return certificates, nil
+}
+
 // ValidateCertificate takes a byte slice, decodes it from the PEM format, ensures it's type is Certificate,
-// and tries to parse it as x509.Certificate. In case an error occurs, it returns the error.
+// and tries to parse it as x509.Certificate. All certificates of a bundle are validated. In case an error occurs, it returns the error.
 func ValidateCertificate(bytes []byte) error {
-	block, _ := pem.Decode(bytes)
-	if block == nil || block.Type != "CERTIFICATE" {
-		return errors.New("PEM block type must be CERTIFICATE")
-	}
+	_, err := ParseCertificates(bytes)
+	return err
+}
+
+// EarliestNotAfter returns the earliest expiry of the given certificates, i.e. the time until the whole bundle is valid.
+// The zero time is returned in case no certificate is given.
+func EarliestNotAfter(certificates []*x509.Certificate) time.Time {
+	var notAfter time.Time
 
-	_, err := x509.ParseCertificate(block.Bytes)
-	if err != nil {
-		return fmt.Errorf("failed to parse certificate: %w", err)
+	for _, certificate := range certificates {
+		if notAfter.IsZero() || certificate.NotAfter.Before(notAfter) {
+			notAfter = certificate.NotAfter
+		}
 	}
 
-	return nil
+	return notAfter
 }
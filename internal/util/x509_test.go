@@ -0,0 +1,73 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util_test
+
+import (
+	"bytes"
+	"crypto/x509"
+	"time"
+
+	"github.com/gardener/gardener/pkg/utils/secrets"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gardener/gardenlogin-controller-manager/internal/util"
+)
+
+var _ = Describe("x509", func() {
+	var (
+		oldCA *secrets.Certificate
+		newCA *secrets.Certificate
+	)
+
+	generateCA := func(name string, validity time.Duration) *secrets.Certificate {
+		csc := &secrets.CertificateSecretConfig{
+			Name:       name,
+			CommonName: name,
+			CertType:   secrets.CACert,
+			Validity:   &validity,
+		}
+
+		ca, err := csc.GenerateCertificate()
+		Expect(err).ToNot(HaveOccurred())
+
+		return ca
+	}
+
+	BeforeEach(func() {
+		oldCA = generateCA("old", 24*time.Hour)
+		newCA = generateCA("new", 10*24*time.Hour)
+	})
+
+	Describe("#ParseCertificates", func() {
+		It("should parse all certificates of a bundle", func() {
+			certificates, err := util.ParseCertificates(bytes.Join([][]byte{newCA.CertificatePEM, oldCA.CertificatePEM}, nil))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(certificates).To(HaveLen(2))
+			Expect(certificates[0].Subject.CommonName).To(Equal("new"))
+			Expect(certificates[1].Subject.CommonName).To(Equal("old"))
+		})
+
+		It("should reject bundles containing other PEM blocks", func() {
+			_, err := util.ParseCertificates(bytes.Join([][]byte{newCA.CertificatePEM, newCA.PrivateKeyPEM}, nil))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should reject data without certificates", func() {
+			_, err := util.ParseCertificates([]byte("foo"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("#EarliestNotAfter", func() {
+		It("should return the earliest expiry of the certificates", func() {
+			Expect(util.EarliestNotAfter(nil)).To(BeZero())
+			Expect(util.EarliestNotAfter([]*x509.Certificate{newCA.Certificate, oldCA.Certificate})).To(Equal(oldCA.Certificate.NotAfter))
+		})
+	})
+})
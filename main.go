@@ -8,28 +8,49 @@ package main
 
 import (
 	"context"
+	_ "embed"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	gardencorev1alpha1 "github.com/gardener/gardener/pkg/apis/core/v1alpha1"
 	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
+	gardenloginv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/gardenlogin/v1alpha1"
 	"github.com/gardener/gardenlogin-controller-manager/controllers"
 	"github.com/gardener/gardenlogin-controller-manager/internal/util"
 	"github.com/gardener/gardenlogin-controller-manager/webhooks"
 )
 
+// leaderElectionID is the name of the leader election lock, which is maintained in the garden cluster of the manager and in each additional garden cluster
+const leaderElectionID = "40ca8637.gardener.cloud"
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
+
+	// version is the version of the gardenlogin-controller-manager
+	//go:embed VERSION
+	version string
 )
 
 func init() {
@@ -37,15 +58,17 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(gardencorev1alpha1.AddToScheme(scheme))
 	utilruntime.Must(gardencorev1beta1.AddToScheme(scheme))
+	utilruntime.Must(gardenloginv1alpha1.AddToScheme(scheme))
 }
 
 func main() {
 	var (
-		metricsAddr          string
-		enableLeaderElection bool
-		probeAddr            string
-		certDir              string
-		configFile           string
+		metricsAddr                   string
+		enableLeaderElection          bool
+		probeAddr                     string
+		certDir                       string
+		configFile                    string
+		webhookCertificateMinValidity time.Duration
 	)
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
@@ -54,6 +77,8 @@ func main() {
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&certDir, "cert-dir", "/tmp/k8s-webhook-server/serving-certs", "CertDir is the directory that contains the server key and certificate.")
+	flag.DurationVar(&webhookCertificateMinValidity, "webhook-certificate-min-validity", 24*time.Hour,
+		"The minimum remaining validity of the webhook server certificate, below which the manager is not ready.")
 	flag.StringVar(&configFile, "config-file", "/etc/gardenlogin-controller-manager/config.yaml", "The path to the configuration file.")
 
 	opts := zap.Options{
@@ -72,42 +97,43 @@ func main() {
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
+		NewClient:              util.NewClient,
 		MetricsBindAddress:     metricsAddr,
 		Port:                   9443,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "40ca8637.gardener.cloud",
+		LeaderElectionID:       leaderElectionID,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	// configInjectors are the components into which the configuration is injected when the configuration file changed
+	var configInjectors []util.ConfigInjector
+
 	ctx := context.Background()
-	if err = (&controllers.ShootReconciler{
-		Client:                      mgr.GetClient(),
-		Log:                         ctrl.Log.WithName("controllers").WithName("Shoot"),
-		Scheme:                      mgr.GetScheme(),
-		Config:                      cmConfig,
-		ReconcilerCountPerNamespace: map[string]int{},
-	}).SetupWithManager(ctx, mgr, cmConfig.Controllers.Shoot); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Shoot")
-		os.Exit(1)
+	shootReconciler := &controllers.ShootReconciler{
+		Client:    mgr.GetClient(),
+		APIReader: mgr.GetAPIReader(),
+		Log:       ctrl.Log.WithName("controllers").WithName("Shoot"),
+		Recorder:  mgr.GetEventRecorderFor("gardenlogin-controller-manager"),
+		Scheme:    mgr.GetScheme(),
+		Config:    cmConfig,
 	}
+	configInjectors = append(configInjectors, shootReconciler)
 
-	//+kubebuilder:scaffold:builder
-
-	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up health check")
+	if err = shootReconciler.SetupWithManager(ctx, mgr, cmConfig.Controllers.Shoot); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Shoot")
 		os.Exit(1)
 	}
 
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up ready check")
+	if err := mgr.AddReadyzCheck("garden-cluster-identity", shootReconciler.GardenClusterIdentityCheck); err != nil {
+		setupLog.Error(err, "unable to set up garden cluster identity check")
 		os.Exit(1)
 	}
 
-	// Setup webhooks
+	// Setup webhooks, the webhooks of the additional garden clusters are registered together with their controllers
 	setupLog.Info("setting up webhook server")
 
 	hookServer := &webhook.Server{
@@ -118,16 +144,269 @@ func main() {
 		os.Exit(1)
 	}
 
+	for _, garden := range cmConfig.Gardens {
+		gardenMgr, err := newGardenManager(garden, enableLeaderElection)
+		if err != nil {
+			setupLog.Error(err, "unable to start manager for garden cluster", "garden", garden.Name)
+			os.Exit(1)
+		}
+
+		gardenShootReconciler := &controllers.ShootReconciler{
+			Client:                gardenMgr.GetClient(),
+			APIReader:             gardenMgr.GetAPIReader(),
+			Log:                   ctrl.Log.WithName("controllers").WithName("Shoot").WithValues("garden", garden.Name),
+			Recorder:              gardenMgr.GetEventRecorderFor("gardenlogin-controller-manager"),
+			Scheme:                gardenMgr.GetScheme(),
+			Config:                cmConfig,
+			GardenName:            garden.Name,
+			GardenClusterIdentity: garden.ClusterIdentity,
+		}
+		configInjectors = append(configInjectors, gardenShootReconciler)
+
+		if err = gardenShootReconciler.SetupWithManager(ctx, gardenMgr, cmConfig.Controllers.Shoot); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Shoot", "garden", garden.Name)
+			os.Exit(1)
+		}
+
+		// the kubeconfig configMaps of the garden cluster are protected by the webhooks served for it, which review the requests with its clients
+		gardenConfigmapValidator := &webhooks.ConfigmapValidator{
+			Log:    ctrl.Log.WithName("webhooks").WithName("ConfigmapValidation").WithValues("garden", garden.Name),
+			Config: cmConfig,
+		}
+		configInjectors = append(configInjectors, gardenConfigmapValidator)
+
+		if err := registerGardenWebhook(hookServer, gardenMgr, "/validate-configmap/"+garden.Name, gardenConfigmapValidator); err != nil {
+			setupLog.Error(err, "unable to register webhook", "webhook", "ConfigmapValidation", "garden", garden.Name)
+			os.Exit(1)
+		}
+
+		if err := registerGardenWebhook(hookServer, gardenMgr, "/mutate-configmap/"+garden.Name, &webhooks.ConfigmapMutator{
+			Log:     ctrl.Log.WithName("webhooks").WithName("ConfigmapMutation").WithValues("garden", garden.Name),
+			Version: strings.TrimSpace(version),
+		}); err != nil {
+			setupLog.Error(err, "unable to register webhook", "webhook", "ConfigmapMutation", "garden", garden.Name)
+			os.Exit(1)
+		}
+
+		// the manager of the garden cluster serves no health probes, hence the checks are added to the manager of the process.
+		// They only gate its readiness if configured, so that an unavailable garden cluster does not take the webhooks out of service.
+		cacheSyncCheck := util.CacheSyncCheck(gardenMgr.GetCache(), shootControllerObjects(cmConfig)...)
+
+		if err := metrics.Registry.Register(util.ReadinessGauge(prometheus.GaugeOpts{
+			Name:        "gardenlogin_garden_ready",
+			Help:        "Whether the caches of the additional garden cluster are synced, its cluster identity is known and its access reviews succeed",
+			ConstLabels: prometheus.Labels{"garden": garden.Name},
+		}, gardenShootReconciler.GardenClusterIdentityCheck, cacheSyncCheck, gardenConfigmapValidator.AccessReviewCheck)); err != nil {
+			setupLog.Error(err, "unable to register garden cluster readiness metric", "garden", garden.Name)
+			os.Exit(1)
+		}
+
+		if garden.GateReadiness {
+			if err := mgr.AddReadyzCheck("garden-cluster-identity-"+garden.Name, gardenShootReconciler.GardenClusterIdentityCheck); err != nil {
+				setupLog.Error(err, "unable to set up garden cluster identity check", "garden", garden.Name)
+				os.Exit(1)
+			}
+
+			if err := mgr.AddReadyzCheck("cache-sync-"+garden.Name, cacheSyncCheck); err != nil {
+				setupLog.Error(err, "unable to set up cache sync check", "garden", garden.Name)
+				os.Exit(1)
+			}
+
+			if err := mgr.AddReadyzCheck("access-review-"+garden.Name, gardenConfigmapValidator.AccessReviewCheck); err != nil {
+				setupLog.Error(err, "unable to set up access review check", "garden", garden.Name)
+				os.Exit(1)
+			}
+		}
+
+		if err := mgr.Add(&gardenManagerRunnable{manager: gardenMgr}); err != nil {
+			setupLog.Error(err, "unable to register manager for garden cluster with manager", "garden", garden.Name)
+			os.Exit(1)
+		}
+	}
+
+	if cmConfig.Controllers.Project.Enabled {
+		projectReconciler := &controllers.ProjectReconciler{
+			Client: mgr.GetClient(),
+			Log:    ctrl.Log.WithName("controllers").WithName("Project"),
+			Scheme: mgr.GetScheme(),
+			Config: cmConfig,
+		}
+		configInjectors = append(configInjectors, projectReconciler)
+
+		if err = projectReconciler.SetupWithManager(mgr, cmConfig.Controllers.Project); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Project")
+			os.Exit(1)
+		}
+	} else if err := mgr.Add(&controllers.ProjectKubeconfigCleanup{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("ProjectKubeconfigCleanup"),
+	}); err != nil {
+		setupLog.Error(err, "unable to register project kubeconfig cleanup with manager")
+		os.Exit(1)
+	}
+
+	if cmConfig.Controllers.KubeconfigBundle.Enabled {
+		kubeconfigBundleReconciler := &controllers.KubeconfigBundleReconciler{
+			Client: mgr.GetClient(),
+			Log:    ctrl.Log.WithName("controllers").WithName("KubeconfigBundle"),
+			Scheme: mgr.GetScheme(),
+			Config: cmConfig,
+		}
+		configInjectors = append(configInjectors, kubeconfigBundleReconciler)
+
+		if err = kubeconfigBundleReconciler.SetupWithManager(mgr, cmConfig.Controllers.KubeconfigBundle); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "KubeconfigBundle")
+			os.Exit(1)
+		}
+	}
+
+	if cmConfig.Controllers.Garden.Enabled {
+		gardenReconciler := &controllers.GardenReconciler{
+			Client: mgr.GetClient(),
+			Log:    ctrl.Log.WithName("controllers").WithName("Garden"),
+			Scheme: mgr.GetScheme(),
+			Config: cmConfig,
+		}
+		configInjectors = append(configInjectors, gardenReconciler)
+
+		if err = gardenReconciler.SetupWithManager(ctx, mgr, cmConfig.Controllers.Garden); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Garden")
+			os.Exit(1)
+		}
+	} else if err := mgr.Add(&controllers.GardenKubeconfigCleanup{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("GardenKubeconfigCleanup"),
+	}); err != nil {
+		setupLog.Error(err, "unable to register garden kubeconfig cleanup with manager")
+		os.Exit(1)
+	}
+
+	//+kubebuilder:scaffold:builder
+
+	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
+		setupLog.Error(err, "unable to set up health check")
+		os.Exit(1)
+	}
+
+	if err := mgr.AddReadyzCheck("cache-sync", util.CacheSyncCheck(mgr.GetCache(), shootControllerObjects(cmConfig)...)); err != nil {
+		setupLog.Error(err, "unable to set up cache sync check")
+		os.Exit(1)
+	}
+
 	setupLog.Info("registering webhooks to the webhook server")
-	hookServer.Register("/validate-configmap", &webhook.Admission{Handler: &webhooks.ConfigmapValidator{
+	configmapValidator := &webhooks.ConfigmapValidator{
 		Log:    ctrl.Log.WithName("webhooks").WithName("ConfigmapValidation"),
 		Config: cmConfig,
+	}
+	configInjectors = append(configInjectors, configmapValidator)
+
+	if err := mgr.AddReadyzCheck("access-review", configmapValidator.AccessReviewCheck); err != nil {
+		setupLog.Error(err, "unable to set up access review check")
+		os.Exit(1)
+	}
+
+	webhookCertFile, webhookKeyFile := filepath.Join(certDir, "tls.crt"), filepath.Join(certDir, "tls.key")
+	if err := mgr.AddReadyzCheck("webhook-certificate", util.CertificateCheck(webhookCertFile, webhookKeyFile, webhookCertificateMinValidity)); err != nil {
+		setupLog.Error(err, "unable to set up webhook certificate check")
+		os.Exit(1)
+	}
+
+	// the metric allows to alert on an upcoming expiry before the readiness check fails
+	if err := metrics.Registry.Register(util.CertificateNotAfterGauge(prometheus.GaugeOpts{
+		Name: "gardenlogin_webhook_certificate_not_after_seconds",
+		Help: "Expiry of the serving certificate of the webhook server in seconds since the epoch, 0 in case it cannot be loaded",
+	}, webhookCertFile, webhookKeyFile)); err != nil {
+		setupLog.Error(err, "unable to register webhook certificate expiry metric")
+		os.Exit(1)
+	}
+
+	hookServer.Register("/validate-configmap", &webhook.Admission{Handler: configmapValidator})
+	hookServer.Register("/mutate-configmap", &webhook.Admission{Handler: &webhooks.ConfigmapMutator{
+		Log:     ctrl.Log.WithName("webhooks").WithName("ConfigmapMutation"),
+		Version: strings.TrimSpace(version),
+	}})
+	hookServer.Register("/mutate-kubeconfigbundle", &webhook.Admission{Handler: &webhooks.KubeconfigBundleMutator{
+		Log: ctrl.Log.WithName("webhooks").WithName("KubeconfigBundleMutation"),
 	}})
 
-	setupLog.Info("starting manager")
+	if configFile != "" {
+		configWatcher := util.NewConfigWatcher(configFile, cmConfig, ctrl.Log.WithName("config"), configInjectors...)
+		if err := mgr.Add(configWatcher); err != nil {
+			setupLog.Error(err, "unable to register config watcher with manager")
+			os.Exit(1)
+		}
+	}
+
+	setupLog.Info("starting manager", "version", strings.TrimSpace(version))
 
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
+
+// shootControllerObjects returns the objects watched by the Shoot controller with the given configuration, whose caches must be synced for the manager to be ready
+func shootControllerObjects(cmConfig *configv1alpha1.ControllerManagerConfiguration) []client.Object {
+	objs := []client.Object{util.NewShoot(), &corev1.ConfigMap{}, &corev1.ResourceQuota{}}
+
+	for _, caSource := range cmConfig.Kubeconfig.CASources {
+		if caSource == configv1alpha1.CASourceShootState {
+			objs = append(objs, &gardencorev1alpha1.ShootState{})
+		}
+	}
+
+	return objs
+}
+
+// newGardenManager returns the manager for the given additional garden cluster. It has its own caches and leader election lock in the garden cluster,
+// but serves neither metrics, nor health probes, nor webhooks, which are served by the manager of the garden cluster the process runs in.
+// The webhooks of the garden cluster are registered with the webhook server of that manager, see registerGardenWebhook.
+func newGardenManager(garden configv1alpha1.GardenClusterConfiguration, enableLeaderElection bool) (manager.Manager, error) {
+	restConfig, err := clientcmd.BuildConfigFromFlags("", garden.Kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig of garden cluster: %w", err)
+	}
+
+	return ctrl.NewManager(restConfig, ctrl.Options{
+		Scheme:                  scheme,
+		NewClient:               util.NewClient,
+		MetricsBindAddress:      "0",
+		HealthProbeBindAddress:  "0",
+		LeaderElection:          enableLeaderElection,
+		LeaderElectionID:        leaderElectionID,
+		LeaderElectionNamespace: garden.LeaderElectionNamespace,
+	})
+}
+
+// registerGardenWebhook registers the given handler as webhook of an additional garden cluster under the given path of the webhook server.
+// The dependencies of the webhook, e.g. its client, are injected by the manager of the garden cluster. The registered handler hides
+// the injection of the webhook, so that the webhook server does not replace them with the dependencies of the manager of the process.
+func registerGardenWebhook(hookServer *webhook.Server, gardenMgr manager.Manager, path string, handler admission.Handler) error {
+	wh := &webhook.Admission{Handler: handler}
+	if err := gardenMgr.SetFields(wh); err != nil {
+		return fmt.Errorf("failed to inject dependencies of garden cluster into webhook: %w", err)
+	}
+
+	hookServer.Register(path, http.HandlerFunc(wh.ServeHTTP))
+
+	return nil
+}
+
+// gardenManagerRunnable runs the manager of an additional garden cluster as part of the manager of the process.
+// It is started regardless of the leader election of the manager of the process, as it elects its leader in the garden cluster.
+// It does not expose the manager, so that the manager of the process does not wait for the caches of the additional garden cluster.
+type gardenManagerRunnable struct {
+	manager manager.Manager
+}
+
+var _ manager.LeaderElectionRunnable = &gardenManagerRunnable{}
+
+// Start starts the manager of the garden cluster and blocks until the context is done or the manager failed
+func (r *gardenManagerRunnable) Start(ctx context.Context) error {
+	return r.manager.Start(ctx)
+}
+
+// NeedLeaderElection returns false, as the manager of the garden cluster maintains its own leader election lock
+func (r *gardenManagerRunnable) NeedLeaderElection() bool {
+	return false
+}
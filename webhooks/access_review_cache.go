@@ -0,0 +1,117 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhooks
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+// accessReviewCachePurgeThreshold is the number of entries after which the expired entries are removed from the cache when adding a new entry
+const accessReviewCachePurgeThreshold = 1000
+
+// accessReviewCache is an in-memory cache of SubjectAccessReview results with separate TTLs for allowed and denied results.
+// The zero value is an empty cache ready to use.
+type accessReviewCache struct {
+	mutex   sync.Mutex
+	entries map[string]accessReviewCacheEntry
+
+	// now returns the current time, it can be replaced in tests
+	now func() time.Time
+}
+
+type accessReviewCacheEntry struct {
+	allowed   bool
+	expiresAt time.Time
+}
+
+// accessReviewCacheKey is the key of a cached access review result
+type accessReviewCacheKey struct {
+	Username  string              `json:"username"`
+	Groups    []string            `json:"groups"`
+	Extra     map[string][]string `json:"extra"`
+	Namespace string              `json:"namespace"`
+	Name      string              `json:"name"`
+}
+
+// key returns the cache key for an access review of the given user for the configMap with the given namespace and name.
+// The groups are sorted, so that the order in which the groups of the user are passed does not matter.
+func (c *accessReviewCache) key(userInfo authenticationv1.UserInfo, namespace, name string) (string, error) {
+	groups := append([]string{}, userInfo.Groups...)
+	sort.Strings(groups)
+
+	extra := make(map[string][]string, len(userInfo.Extra))
+	for k, v := range userInfo.Extra {
+		extra[k] = v
+	}
+
+	// map keys are sorted by json.Marshal
+	key, err := json.Marshal(accessReviewCacheKey{
+		Username:  userInfo.Username,
+		Groups:    groups,
+		Extra:     extra,
+		Namespace: namespace,
+		Name:      name,
+	})
+
+	return string(key), err
+}
+
+// get returns the cached result for the given key. ok is false in case there is no result or the result expired.
+func (c *accessReviewCache) get(key string) (allowed bool, ok bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return false, false
+	}
+
+	if !c.currentTime().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return false, false
+	}
+
+	return entry.allowed, true
+}
+
+// set caches the given result for the given TTL
+func (c *accessReviewCache) set(key string, allowed bool, ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.currentTime()
+
+	if c.entries == nil {
+		c.entries = make(map[string]accessReviewCacheEntry)
+	}
+
+	if len(c.entries) >= accessReviewCachePurgeThreshold {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+	}
+
+	c.entries[key] = accessReviewCacheEntry{
+		allowed:   allowed,
+		expiresAt: now.Add(ttl),
+	}
+}
+
+func (c *accessReviewCache) currentTime() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+
+	return time.Now()
+}
@@ -0,0 +1,105 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhooks
+
+import (
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+var _ = Describe("accessReviewCache", func() {
+	var (
+		cache *accessReviewCache
+		now   time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		cache = &accessReviewCache{
+			now: func() time.Time { return now },
+		}
+	})
+
+	Describe("#key", func() {
+		It("should not depend on the order of the groups", func() {
+			key1, err := cache.key(authenticationv1.UserInfo{Username: "foo", Groups: []string{"a", "b"}}, "garden-dev", "foo.kubeconfig")
+			Expect(err).ToNot(HaveOccurred())
+
+			key2, err := cache.key(authenticationv1.UserInfo{Username: "foo", Groups: []string{"b", "a"}}, "garden-dev", "foo.kubeconfig")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(key1).To(Equal(key2))
+		})
+
+		It("should differ for different extra values and configMaps", func() {
+			userInfo := authenticationv1.UserInfo{Username: "foo", Extra: map[string]authenticationv1.ExtraValue{"scopes": {"a"}}}
+
+			key1, err := cache.key(userInfo, "garden-dev", "foo.kubeconfig")
+			Expect(err).ToNot(HaveOccurred())
+
+			key2, err := cache.key(userInfo, "garden-dev", "bar.kubeconfig")
+			Expect(err).ToNot(HaveOccurred())
+
+			userInfo.Extra["scopes"] = authenticationv1.ExtraValue{"b"}
+			key3, err := cache.key(userInfo, "garden-dev", "foo.kubeconfig")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(key1).ToNot(Equal(key2))
+			Expect(key1).ToNot(Equal(key3))
+		})
+	})
+
+	Describe("#get", func() {
+		It("should return cached results until they expire", func() {
+			cache.set("allowed", true, time.Minute)
+			cache.set("denied", false, 10*time.Second)
+
+			allowed, ok := cache.get("allowed")
+			Expect(ok).To(BeTrue())
+			Expect(allowed).To(BeTrue())
+
+			allowed, ok = cache.get("denied")
+			Expect(ok).To(BeTrue())
+			Expect(allowed).To(BeFalse())
+
+			now = now.Add(10 * time.Second)
+
+			_, ok = cache.get("denied")
+			Expect(ok).To(BeFalse())
+
+			_, ok = cache.get("allowed")
+			Expect(ok).To(BeTrue())
+
+			now = now.Add(time.Minute)
+
+			_, ok = cache.get("allowed")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("should not return results that were never cached", func() {
+			_, ok := cache.get("foo")
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("#set", func() {
+		It("should purge expired entries once the threshold is reached", func() {
+			for i := 0; i < accessReviewCachePurgeThreshold; i++ {
+				cache.set(strconv.Itoa(i), true, time.Second)
+			}
+
+			now = now.Add(time.Second)
+			cache.set("foo", true, time.Second)
+
+			Expect(cache.entries).To(HaveLen(1))
+		})
+	})
+})
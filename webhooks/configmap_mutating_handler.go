@@ -0,0 +1,282 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1/constants"
+	"github.com/gardener/gardenlogin-controller-manager/internal/util"
+)
+
+// provenanceAnnotations are the annotation keys maintained by the ConfigmapMutator
+var provenanceAnnotations = []string{
+	constants.AnnotationControllerVersion,
+	constants.AnnotationShootKubernetesVersion,
+	constants.AnnotationKubeconfigFormat,
+	constants.AnnotationKubeconfigExecAPIVersion,
+	constants.AnnotationCABundleSHA256,
+	constants.AnnotationGeneratedAt,
+}
+
+// ConfigmapMutator handles ConfigMap.
+// It stamps provenance annotations on kubeconfig configMaps whenever their kubeconfig changes, so that users and tooling like gardenctl
+// can tell whether a local copy of the kubeconfig is stale without comparing the whole kubeconfig.
+type ConfigmapMutator struct {
+	client client.Client
+	Log    logr.Logger
+	// Version is the version of the gardenlogin-controller-manager, which is recorded in the controller-version annotation
+	Version string
+
+	// now returns the current time, defaults to time.Now
+	now func() time.Time
+
+	// Decoder decodes objects
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = &ConfigmapMutator{}
+
+// Handle handles admission requests.
+func (h *ConfigmapMutator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	obj := &corev1.ConfigMap{}
+	if err := h.decoder.Decode(req, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if !hasKubeconfigRole(obj) {
+		return admission.Allowed("not a kubeconfig configmap")
+	}
+
+	if req.AdmissionRequest.Operation == admissionv1.Update {
+		oldObj := &corev1.ConfigMap{}
+		if err := h.decoder.DecodeRaw(req.AdmissionRequest.OldObject, oldObj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+
+		// the provenance only changes with the kubeconfig, e.g. the controller updating the labels must not change the generation timestamp.
+		// The annotations are restored in case someone else tries to change them.
+		if _, ok := oldObj.Annotations[constants.AnnotationGeneratedAt]; ok && hasKubeconfigRole(oldObj) &&
+			oldObj.Data[constants.DataKeyKubeconfig] == obj.Data[constants.DataKeyKubeconfig] {
+			annotations := make(map[string]string)
+
+			for _, key := range provenanceAnnotations {
+				if value, ok := oldObj.Annotations[key]; ok {
+					annotations[key] = value
+				}
+			}
+
+			return h.patchResponse(req, obj, annotations)
+		}
+	}
+
+	return h.patchResponse(req, obj, h.provenance(ctx, obj))
+}
+
+// provenance returns the provenance annotations for the kubeconfig of the given kubeconfig configMap.
+// The kubeconfig format, the exec API version and the kubernetes version of the shoot are only recorded for the kubeconfigs of single shoots.
+// The format and the exec API version are derived from the kubeconfig itself, so that they describe the stored kubeconfig and not the one
+// the current configuration would render. The kubernetes version is skipped in case the shoot cannot be read, so that the configMap is never
+// rejected because of its provenance.
+func (h *ConfigmapMutator) provenance(ctx context.Context, c *corev1.ConfigMap) map[string]string {
+	annotations := map[string]string{
+		constants.AnnotationGeneratedAt: h.currentTime().UTC().Format(time.RFC3339),
+	}
+
+	if h.Version != "" {
+		annotations[constants.AnnotationControllerVersion] = h.Version
+	}
+
+	kubeconfig, err := clientcmd.Load([]byte(c.Data[constants.DataKeyKubeconfig]))
+	if err != nil {
+		// the kubeconfig is rejected by the validating webhook
+		return annotations
+	}
+
+	if caBundleSHA256 := caBundleSHA256(kubeconfig); caBundleSHA256 != "" {
+		annotations[constants.AnnotationCABundleSHA256] = caBundleSHA256
+	}
+
+	if scope, fieldErr := kubeconfigScope(c, field.NewPath("metadata", "labels").Key(constants.LabelKubeconfigScope)); fieldErr != nil || scope != "" {
+		// configMaps with an invalid scope label are rejected by the validating webhook
+		return annotations
+	}
+
+	if format := kubeconfigFormat(kubeconfig); format != "" {
+		annotations[constants.AnnotationKubeconfigFormat] = format
+	}
+
+	if execAPIVersion := kubeconfigExecAPIVersion(kubeconfig); execAPIVersion != "" {
+		annotations[constants.AnnotationKubeconfigExecAPIVersion] = execAPIVersion
+	}
+
+	key := client.ObjectKey{Namespace: c.Namespace, Name: strings.TrimSuffix(c.Name, constants.KubeconfigConfigMapNameSuffix)}
+
+	shoot, _, err := util.GetShoot(ctx, h.client, key)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			h.Log.Error(err, "failed to fetch shoot, skipping shoot kubernetes version annotation", "namespace", c.Namespace, "name", c.Name)
+		}
+
+		return annotations
+	}
+
+	annotations[constants.AnnotationShootKubernetesVersion] = shoot.Spec.Kubernetes.Version
+
+	return annotations
+}
+
+// kubeconfigFormat returns the format of the given kubeconfig: kubeconfigs passing the shoot reference with a cluster extension have the extension format,
+// all others the legacy format. An empty string is returned for kubeconfigs without clusters.
+func kubeconfigFormat(kubeconfig *clientcmdapi.Config) string {
+	if len(kubeconfig.Clusters) == 0 {
+		return ""
+	}
+
+	for _, cluster := range kubeconfig.Clusters {
+		if _, ok := cluster.Extensions[execExtensionName]; ok {
+			return constants.KubeconfigFormatExtension
+		}
+	}
+
+	return constants.KubeconfigFormatLegacy
+}
+
+// kubeconfigExecAPIVersion returns the API version of the exec section of the user of the current context of the given kubeconfig.
+// In case the current context has no user with an exec section, the API version of the first user with an exec section in the order of the user names is returned.
+func kubeconfigExecAPIVersion(kubeconfig *clientcmdapi.Config) string {
+	if currentContext, ok := kubeconfig.Contexts[kubeconfig.CurrentContext]; ok {
+		if authInfo, ok := kubeconfig.AuthInfos[currentContext.AuthInfo]; ok && authInfo.Exec != nil {
+			return authInfo.Exec.APIVersion
+		}
+	}
+
+	var names []string
+	for name := range kubeconfig.AuthInfos {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if exec := kubeconfig.AuthInfos[name].Exec; exec != nil {
+			return exec.APIVersion
+		}
+	}
+
+	return ""
+}
+
+// caBundleSHA256 returns the hex encoded SHA-256 of the distinct certificate authorities of the clusters of the given kubeconfig,
+// concatenated in the order of the cluster names. An empty string is returned in case the kubeconfig contains no certificate authority.
+func caBundleSHA256(kubeconfig *clientcmdapi.Config) string {
+	var names []string
+	for name := range kubeconfig.Clusters {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var caBundle [][]byte
+
+	for _, name := range names {
+		caData := kubeconfig.Clusters[name].CertificateAuthorityData
+		if len(caData) == 0 {
+			continue
+		}
+
+		known := false
+
+		for _, ca := range caBundle {
+			if bytes.Equal(ca, caData) {
+				known = true
+				break
+			}
+		}
+
+		if !known {
+			caBundle = append(caBundle, caData)
+		}
+	}
+
+	if len(caBundle) == 0 {
+		return ""
+	}
+
+	sum := sha256.Sum256(bytes.Join(caBundle, nil))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// patchResponse returns a response that replaces the provenance annotations of the object of the request with the given annotations
+func (h *ConfigmapMutator) patchResponse(req admission.Request, obj *corev1.ConfigMap, annotations map[string]string) admission.Response {
+	if obj.Annotations == nil {
+		obj.Annotations = make(map[string]string)
+	}
+
+	for _, key := range provenanceAnnotations {
+		delete(obj.Annotations, key)
+	}
+
+	for key, value := range annotations {
+		obj.Annotations[key] = value
+	}
+
+	marshaled, err := json.Marshal(obj)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+// currentTime returns the current time of the ConfigmapMutator
+func (h *ConfigmapMutator) currentTime() time.Time {
+	if h.now != nil {
+		return h.now()
+	}
+
+	return time.Now()
+}
+
+var _ inject.Client = &ConfigmapMutator{}
+
+// A client will be automatically injected.
+
+// InjectClient injects the client.
+func (h *ConfigmapMutator) InjectClient(c client.Client) error {
+	h.client = c
+	return nil
+}
+
+// ConfigmapMutator implements admission.DecoderInjector.
+// A decoder will be automatically injected.
+
+// InjectDecoder injects the decoder.
+func (h *ConfigmapMutator) InjectDecoder(d *admission.Decoder) error {
+	h.decoder = d
+	return nil
+}
@@ -0,0 +1,337 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1/constants"
+)
+
+var _ = Describe("ConfigmapMutator", func() {
+	var (
+		ctx       context.Context
+		mutator   *ConfigmapMutator
+		configMap *corev1.ConfigMap
+		now       time.Time
+	)
+
+	kubeconfig := `apiVersion: v1
+kind: Config
+clusters:
+- name: garden-dev--foo-external
+  cluster:
+    server: https://api.foo.garden-dev.example.com
+    certificate-authority-data: Y2E=
+    extensions:
+    - name: client.authentication.k8s.io/exec
+      extension:
+        shootRef:
+          namespace: garden-dev
+          name: foo
+contexts:
+- name: garden-dev--foo-external
+  context:
+    cluster: garden-dev--foo-external
+    user: garden-dev--foo-external
+current-context: garden-dev--foo-external
+users:
+- name: garden-dev--foo-external
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1
+      command: kubectl
+      args:
+      - gardenlogin
+      - get-client-certificate
+`
+
+	legacyKubeconfig := `apiVersion: v1
+kind: Config
+clusters:
+- name: garden-dev--legacy-external
+  cluster:
+    server: https://api.legacy.garden-dev.example.com
+    certificate-authority-data: Y2E=
+contexts:
+- name: garden-dev--legacy-external
+  context:
+    cluster: garden-dev--legacy-external
+    user: garden-dev--legacy-external
+current-context: garden-dev--legacy-external
+users:
+- name: garden-dev--legacy-external
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: kubectl
+      args:
+      - gardenlogin
+      - get-client-certificate
+      - --namespace=garden-dev
+      - --name=legacy
+`
+
+	request := func(operation admissionv1.Operation, obj *corev1.ConfigMap, oldObj *corev1.ConfigMap) admission.Request {
+		raw, err := json.Marshal(obj)
+		Expect(err).ToNot(HaveOccurred())
+
+		req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: operation,
+			Object:    runtime.RawExtension{Raw: raw},
+		}}
+
+		if oldObj != nil {
+			oldRaw, err := json.Marshal(oldObj)
+			Expect(err).ToNot(HaveOccurred())
+
+			req.OldObject = runtime.RawExtension{Raw: oldRaw}
+		}
+
+		return req
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(gardencorev1beta1.AddToScheme(scheme)).To(Succeed())
+
+		shoot := &gardencorev1beta1.Shoot{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "garden-dev"},
+			Spec: gardencorev1beta1.ShootSpec{
+				Kubernetes: gardencorev1beta1.Kubernetes{Version: "1.22.2"},
+			},
+		}
+
+		legacyShoot := shoot.DeepCopy()
+		legacyShoot.Name = "legacy"
+		legacyShoot.Spec.Kubernetes.Version = "1.19.0"
+
+		decoder, err := admission.NewDecoder(scheme)
+		Expect(err).ToNot(HaveOccurred())
+
+		mutator = &ConfigmapMutator{
+			Log:     logr.Discard(),
+			Version: "v1.0.0",
+			now:     func() time.Time { return now },
+		}
+		Expect(mutator.InjectClient(fake.NewClientBuilder().WithScheme(scheme).WithObjects(shoot, legacyShoot).Build())).To(Succeed())
+		Expect(mutator.InjectDecoder(decoder)).To(Succeed())
+
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo.kubeconfig",
+				Namespace: "garden-dev",
+				Labels: map[string]string{
+					constants.GardenerOperationsRole: constants.GardenerOperationsKubeconfig,
+				},
+			},
+			Data: map[string]string{
+				constants.DataKeyKubeconfig: kubeconfig,
+			},
+		}
+	})
+
+	It("should stamp the provenance annotations on created kubeconfig configmaps", func() {
+		resp := mutator.Handle(ctx, request(admissionv1.Create, configMap, nil))
+
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(resp.Patches).To(ConsistOf(And(
+			HaveField("Operation", "add"),
+			HaveField("Path", "/metadata/annotations"),
+			HaveField("Value", map[string]interface{}{
+				constants.AnnotationControllerVersion:        "v1.0.0",
+				constants.AnnotationShootKubernetesVersion:   "1.22.2",
+				constants.AnnotationKubeconfigFormat:         constants.KubeconfigFormatExtension,
+				constants.AnnotationKubeconfigExecAPIVersion: "client.authentication.k8s.io/v1",
+				constants.AnnotationCABundleSHA256:           "6959097001d10501ac7d54c0bdb8db61420f658f2922cc26e46d536119a31126", // sha256 of "ca"
+				constants.AnnotationGeneratedAt:              "2021-10-01T12:00:00Z",
+			}),
+		)))
+	})
+
+	It("should keep the provenance annotations if the kubeconfig did not change", func() {
+		configMap.Annotations = map[string]string{
+			constants.AnnotationControllerVersion: "v0.9.0",
+			constants.AnnotationGeneratedAt:       "2021-09-01T12:00:00Z",
+		}
+		oldConfigMap := configMap.DeepCopy()
+		configMap.Annotations[constants.AnnotationControllerVersion] = "v2.0.0"
+		configMap.Labels["foo"] = "bar"
+
+		resp := mutator.Handle(ctx, request(admissionv1.Update, configMap, oldConfigMap))
+
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(resp.Patches).To(ConsistOf(And(
+			HaveField("Operation", "replace"),
+			HaveField("Path", "/metadata/annotations/gardenlogin.gardener.cloud~1controller-version"),
+			HaveField("Value", "v0.9.0"),
+		)))
+	})
+
+	It("should renew the provenance annotations if the kubeconfig changed", func() {
+		configMap.Annotations = map[string]string{
+			constants.AnnotationControllerVersion: "v0.9.0",
+			constants.AnnotationGeneratedAt:       "2021-09-01T12:00:00Z",
+		}
+		oldConfigMap := configMap.DeepCopy()
+		oldConfigMap.Data[constants.DataKeyKubeconfig] = "foo"
+
+		resp := mutator.Handle(ctx, request(admissionv1.Update, configMap, oldConfigMap))
+
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(resp.Patches).To(ContainElements(
+			And(
+				HaveField("Path", "/metadata/annotations/gardenlogin.gardener.cloud~1controller-version"),
+				HaveField("Value", "v1.0.0"),
+			),
+			And(
+				HaveField("Path", "/metadata/annotations/gardenlogin.gardener.cloud~1generated-at"),
+				HaveField("Value", "2021-10-01T12:00:00Z"),
+			),
+		))
+	})
+
+	It("should record the format and exec API version of the stored kubeconfig", func() {
+		configMap.Name = "legacy.kubeconfig"
+		configMap.Data[constants.DataKeyKubeconfig] = legacyKubeconfig
+
+		resp := mutator.Handle(ctx, request(admissionv1.Create, configMap, nil))
+
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(resp.Patches).To(ConsistOf(HaveField("Value", And(
+			HaveKeyWithValue(constants.AnnotationShootKubernetesVersion, "1.19.0"),
+			HaveKeyWithValue(constants.AnnotationKubeconfigFormat, constants.KubeconfigFormatLegacy),
+			HaveKeyWithValue(constants.AnnotationKubeconfigExecAPIVersion, "client.authentication.k8s.io/v1beta1"),
+		))))
+	})
+
+	It("should record the format of the stored kubeconfig even if it does not match the kubernetes version of the shoot", func() {
+		configMap.Data[constants.DataKeyKubeconfig] = legacyKubeconfig
+
+		resp := mutator.Handle(ctx, request(admissionv1.Create, configMap, nil))
+
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(resp.Patches).To(ConsistOf(HaveField("Value", And(
+			HaveKeyWithValue(constants.AnnotationShootKubernetesVersion, "1.22.2"),
+			HaveKeyWithValue(constants.AnnotationKubeconfigFormat, constants.KubeconfigFormatLegacy),
+			HaveKeyWithValue(constants.AnnotationKubeconfigExecAPIVersion, "client.authentication.k8s.io/v1beta1"),
+		))))
+	})
+
+	It("should skip the kubernetes version annotation in case the shoot cannot be read", func() {
+		Expect(mutator.InjectClient(fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build())).To(Succeed())
+
+		resp := mutator.Handle(ctx, request(admissionv1.Create, configMap, nil))
+
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(resp.Patches).To(ConsistOf(HaveField("Value", And(
+			HaveKey(constants.AnnotationControllerVersion),
+			HaveKey(constants.AnnotationGeneratedAt),
+			HaveKeyWithValue(constants.AnnotationKubeconfigFormat, constants.KubeconfigFormatExtension),
+			HaveKeyWithValue(constants.AnnotationKubeconfigExecAPIVersion, "client.authentication.k8s.io/v1"),
+			Not(HaveKey(constants.AnnotationShootKubernetesVersion)),
+		))))
+	})
+
+	It("should only record the shoot specific annotations for shoot kubeconfigs", func() {
+		configMap.Name = constants.ProjectKubeconfigConfigMapName
+		configMap.Labels[constants.LabelKubeconfigScope] = constants.KubeconfigScopeProject
+
+		resp := mutator.Handle(ctx, request(admissionv1.Create, configMap, nil))
+
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(resp.Patches).To(ConsistOf(HaveField("Value", And(
+			HaveKey(constants.AnnotationControllerVersion),
+			HaveKey(constants.AnnotationCABundleSHA256),
+			HaveKey(constants.AnnotationGeneratedAt),
+			Not(HaveKey(constants.AnnotationShootKubernetesVersion)),
+			Not(HaveKey(constants.AnnotationKubeconfigFormat)),
+			Not(HaveKey(constants.AnnotationKubeconfigExecAPIVersion)),
+		))))
+	})
+
+	It("should not record the shoot specific annotations for configmaps with a forged scope label", func() {
+		configMap.Labels[constants.LabelKubeconfigScope] = constants.KubeconfigScopeBundle
+
+		resp := mutator.Handle(ctx, request(admissionv1.Create, configMap, nil))
+
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(resp.Patches).To(ConsistOf(HaveField("Value", And(
+			HaveKey(constants.AnnotationGeneratedAt),
+			Not(HaveKey(constants.AnnotationKubeconfigFormat)),
+		))))
+	})
+
+	It("should not mutate other configmaps", func() {
+		delete(configMap.Labels, constants.GardenerOperationsRole)
+
+		resp := mutator.Handle(ctx, request(admissionv1.Create, configMap, nil))
+
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(resp.Patches).To(BeEmpty())
+	})
+
+	Describe("#caBundleSHA256", func() {
+		It("should hash the distinct certificate authorities in the order of the cluster names", func() {
+			kubeconfig := clientcmdapi.NewConfig()
+			kubeconfig.Clusters["b"] = &clientcmdapi.Cluster{CertificateAuthorityData: []byte("ca2")}
+			kubeconfig.Clusters["a"] = &clientcmdapi.Cluster{CertificateAuthorityData: []byte("ca1")}
+			kubeconfig.Clusters["c"] = &clientcmdapi.Cluster{CertificateAuthorityData: []byte("ca1")}
+
+			// sha256 of "ca1ca2"
+			Expect(caBundleSHA256(kubeconfig)).To(Equal("aa092970023423911d2fe00c029cc4293e47fe6e5f87da4bd0fb240b69e18e55"))
+		})
+
+		It("should return an empty string without certificate authorities", func() {
+			Expect(caBundleSHA256(clientcmdapi.NewConfig())).To(BeEmpty())
+		})
+	})
+
+	Describe("#kubeconfigExecAPIVersion", func() {
+		It("should prefer the user of the current context", func() {
+			kubeconfig := clientcmdapi.NewConfig()
+			kubeconfig.AuthInfos["a"] = &clientcmdapi.AuthInfo{Exec: &clientcmdapi.ExecConfig{APIVersion: "client.authentication.k8s.io/v1beta1"}}
+			kubeconfig.AuthInfos["b"] = &clientcmdapi.AuthInfo{Exec: &clientcmdapi.ExecConfig{APIVersion: "client.authentication.k8s.io/v1"}}
+			kubeconfig.Contexts["b"] = &clientcmdapi.Context{AuthInfo: "b"}
+			kubeconfig.CurrentContext = "b"
+
+			Expect(kubeconfigExecAPIVersion(kubeconfig)).To(Equal("client.authentication.k8s.io/v1"))
+		})
+
+		It("should fall back to the first user with an exec section in the order of the user names", func() {
+			kubeconfig := clientcmdapi.NewConfig()
+			kubeconfig.AuthInfos["a"] = &clientcmdapi.AuthInfo{}
+			kubeconfig.AuthInfos["c"] = &clientcmdapi.AuthInfo{Exec: &clientcmdapi.ExecConfig{APIVersion: "client.authentication.k8s.io/v1"}}
+			kubeconfig.AuthInfos["b"] = &clientcmdapi.AuthInfo{Exec: &clientcmdapi.ExecConfig{APIVersion: "client.authentication.k8s.io/v1beta1"}}
+
+			Expect(kubeconfigExecAPIVersion(kubeconfig)).To(Equal("client.authentication.k8s.io/v1beta1"))
+		})
+
+		It("should return an empty string without exec sections", func() {
+			Expect(kubeconfigExecAPIVersion(clientcmdapi.NewConfig())).To(BeEmpty())
+		})
+	})
+})
@@ -8,52 +8,362 @@ package webhooks
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	admissionv1 "k8s.io/api/admission/v1"
 	authenticationv1 "k8s.io/api/authentication/v1"
 	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
+	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1/constants"
 	"github.com/gardener/gardenlogin-controller-manager/internal/util"
 )
 
+// accessReviewCheckInterval is the interval in which the AccessReviewCheck expects a successful SubjectAccessReview round-trip
+const accessReviewCheckInterval = time.Minute
+
 // ConfigmapValidator handles ConfigMap
 type ConfigmapValidator struct {
 	client      client.Client
+	apiReader   client.Reader
 	Log         logr.Logger
-	Config      *util.ControllerManagerConfiguration
+	Config      *configv1alpha1.ControllerManagerConfiguration
 	configMutex sync.RWMutex
+	// caSource reads the cluster certificate authority of the shoots from the configured ca sources. It is built on first use and
+	// built again once the ca sources or the clients are replaced.
+	caSource util.CASource
+
+	// accessReviews caches the results of the SubjectAccessReviews
+	accessReviews accessReviewCache
+	// lastAccessReview is the time of the last successful SubjectAccessReview round-trip, it is reported by the AccessReviewCheck
+	lastAccessReview      time.Time
+	lastAccessReviewMutex sync.RWMutex
 
 	// Decoder decodes objects
 	decoder *admission.Decoder
 }
 
-func (h *ConfigmapValidator) getConfig() *util.ControllerManagerConfiguration {
+func (h *ConfigmapValidator) getConfig() *configv1alpha1.ControllerManagerConfiguration {
 	h.configMutex.RLock()
 	defer h.configMutex.RUnlock()
 
 	return h.Config
 }
 
-func (h *ConfigmapValidator) validatingKubeconfigConfigMapFn(ctx context.Context, c *corev1.ConfigMap, oldC *corev1.ConfigMap, admissionReq admissionv1.AdmissionRequest) (bool, string, error) {
-	fldValidations := getFieldValidations(c)
-	if err := validateRequiredFields(fldValidations); err != nil {
-		return false, err.Error(), nil
+// InjectConfig replaces the configv1alpha1.ControllerManagerConfiguration of the ConfigmapValidator, e.g. when the configuration file changed
+func (h *ConfigmapValidator) InjectConfig(config *configv1alpha1.ControllerManagerConfiguration) {
+	h.configMutex.Lock()
+	defer h.configMutex.Unlock()
+
+	if h.Config == nil || !apiequality.Semantic.DeepEqual(h.Config.Kubeconfig.CASources, config.Kubeconfig.CASources) {
+		h.caSource = nil
 	}
 
+	h.Config = config
+}
+
+// getCASource returns the source of the cluster certificate authorities of the shoots, it is built from the configured ca sources on first use
+func (h *ConfigmapValidator) getCASource() (util.CASource, error) {
+	h.configMutex.RLock()
+	caSource := h.caSource
+	h.configMutex.RUnlock()
+
+	if caSource != nil {
+		return caSource, nil
+	}
+
+	h.configMutex.Lock()
+	defer h.configMutex.Unlock()
+
+	if h.caSource == nil {
+		caSource, err := util.NewCASource(h.Config.Kubeconfig.CASources, h.client, h.apiReader)
+		if err != nil {
+			return nil, err
+		}
+
+		h.caSource = caSource
+	}
+
+	return h.caSource, nil
+}
+
+func (h *ConfigmapValidator) validatingKubeconfigConfigMapFn(ctx context.Context, c *corev1.ConfigMap, oldC *corev1.ConfigMap, admissionReq admissionv1.AdmissionRequest) (bool, string, []string, error) {
 	userInfo := admissionReq.UserInfo
 
+	var transition string
+	if admissionReq.Operation == admissionv1.Update {
+		transition = kubeconfigConfigMapTransition(oldC, c)
+	}
+
+	if !hasKubeconfigRole(c) {
+		if transition == "" {
+			return true, "not a kubeconfig configmap", nil, nil
+		}
+
+		// the configMap leaves the kubeconfig role, hence only the transition itself is validated
+		if allowed, err := h.canManageConfigmaps(ctx, userInfo, c.Namespace, c.Name); err != nil {
+			return false, err.Error(), nil, nil
+		} else if !allowed {
+			return false, fmt.Sprintf("not allowed to manage configmaps, which is required for %s", transition), nil, nil
+		}
+
+		return true, "allowed to be admitted", nil, nil
+	}
+
+	fldValidations := getFieldValidations(c)
+	if err := validateRequiredFields(fldValidations); err != nil {
+		return false, err.Error(), nil, nil
+	}
+
 	// Validate that user has the permission to "manage" configMaps.
 	// Usually we only want to have the gardenlogin-controller-manager to have this permission and no one else, so that no one fiddles around with the kubeconfigs
-	if allowed, err := h.canManageConfigmapsAccessReview(ctx, userInfo, c.Namespace, c.Name); err != nil {
+	if allowed, err := h.canManageConfigmaps(ctx, userInfo, c.Namespace, c.Name); err != nil {
+		return false, err.Error(), nil, nil
+	} else if !allowed {
+		if transition != "" {
+			return false, fmt.Sprintf("not allowed to manage configmaps, which is required for %s", transition), nil, nil
+		}
+
+		return false, "not allowed to manage configmaps", nil, nil
+	}
+
+	allowed, reason, err := h.validatingKubeconfigContentFn(ctx, c, userInfo)
+	if err != nil || allowed || !h.getConfig().Webhooks.ConfigMapValidation.WarnOnly {
+		return allowed, reason, nil, err
+	}
+
+	// only the validation of the content is relaxed by the warn-only mode, the permission to manage configMaps is always required
+	h.Log.Info("kubeconfig would be denied, admitting it in warn-only mode", "namespace", c.Namespace, "name", c.Name, "reason", reason)
+
+	return true, "admitted in warn-only mode", []string{warnOnlyWarning(reason)}, nil
+}
+
+// validatingKubeconfigContentFn validates the kubeconfig of the given kubeconfig configMap, i.e. its scope, that it contains no credentials, that the shoots
+// referenced by aggregated kubeconfigs may be read by the given user and, in strict mode, its clusters against the Shoots.
+// In warn-only mode, a denial of these validations is returned as admission warning instead.
+func (h *ConfigmapValidator) validatingKubeconfigContentFn(ctx context.Context, c *corev1.ConfigMap, userInfo authenticationv1.UserInfo) (bool, string, error) {
+	scope, scopeErr := kubeconfigScope(c, field.NewPath("metadata", "labels").Key(constants.LabelKubeconfigScope))
+	if scopeErr != nil {
+		return false, scopeErr.Error(), nil
+	}
+
+	if errs := validateKubeconfig(c, scope, &h.getConfig().Kubeconfig, field.NewPath("data", "kubeconfig")); len(errs) > 0 {
+		return false, errs.ToAggregate().Error(), nil
+	}
+
+	if scope == constants.KubeconfigScopeProject || scope == constants.KubeconfigScopeBundle {
+		if allowed, reason, err := h.validatingShootRefsFn(ctx, c, userInfo); err != nil || !allowed {
+			return allowed, reason, err
+		}
+	}
+
+	if h.getConfig().Webhooks.ConfigMapValidation.Strict {
+		return h.validatingKubeconfigClustersFn(ctx, c, scope)
+	}
+
+	return true, "allowed to be admitted", nil
+}
+
+// validatingShootRefsFn verifies that the shoots referenced by the clusters of the given aggregated kubeconfig configMap exist and that the given user may read them,
+// so that the kubeconfig cannot publish shoots the user cannot access. The access is reviewed once per namespace with the permission to list the shoots and,
+// in case it is not granted, for each shoot with the permission to get it. The access reviews are skipped for the configured accessReviewSkippedUsers.
+func (h *ConfigmapValidator) validatingShootRefsFn(ctx context.Context, c *corev1.ConfigMap, userInfo authenticationv1.UserInfo) (bool, string, error) {
+	kubeconfig, err := clientcmd.Load([]byte(c.Data[constants.DataKeyKubeconfig]))
+	if err != nil {
+		return false, fmt.Sprintf("could not parse kubeconfig: %s", err), nil
+	}
+
+	keys := make([]client.ObjectKey, 0)
+	seen := make(map[client.ObjectKey]bool)
+
+	for _, shootRef := range clusterShootRefs(kubeconfig) {
+		key := client.ObjectKey{Namespace: shootRef.Namespace, Name: shootRef.Name}
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	// verify the shoots in a stable order, so that the same reason is returned for the same kubeconfig
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	skipAccessReview := false
+
+	for _, user := range h.getConfig().Webhooks.ConfigMapValidation.AccessReviewSkippedUsers {
+		if userInfo.Username == user {
+			skipAccessReview = true
+			break
+		}
+	}
+
+	canList := make(map[string]bool)
+
+	for _, key := range keys {
+		if _, _, err := util.GetShoot(ctx, h.client, key); err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, fmt.Sprintf("shoot %s referenced by kubeconfig not found", key), nil
+			}
+
+			return false, "failed to fetch shoot", err
+		}
+
+		if skipAccessReview {
+			continue
+		}
+
+		allowed, ok := canList[key.Namespace]
+		if !ok {
+			if allowed, err = util.CanListShoots(ctx, h.client, userInfo, key.Namespace); err != nil {
+				return false, "failed to review access to shoots", err
+			}
+
+			h.recordAccessReview()
+			canList[key.Namespace] = allowed
+		}
+
+		if allowed {
+			continue
+		}
+
+		if allowed, err = util.CanGetShoot(ctx, h.client, userInfo, key); err != nil {
+			return false, "failed to review access to shoot", err
+		} else if !allowed {
+			return false, fmt.Sprintf("not allowed to get shoot %s referenced by kubeconfig", key), nil
+		}
+	}
+
+	return true, "allowed to be admitted", nil
+}
+
+// validatingKubeconfigClustersFn verifies the clusters of the kubeconfig of the given kubeconfig configMap with the given scope, so that the kubeconfig cannot
+// redirect users to another kube-apiserver. The clusters of a <shoot>.kubeconfig configMap are verified against the Shoot named by the configMap,
+// the clusters of the project and bundle kubeconfigs against the Shoot referenced by each cluster and the clusters of the garden kubeconfig
+// against the configured garden cluster.
+func (h *ConfigmapValidator) validatingKubeconfigClustersFn(ctx context.Context, c *corev1.ConfigMap, scope string) (bool, string, error) {
+	fldPath := field.NewPath("data", "kubeconfig")
+
+	kubeconfig, err := clientcmd.Load([]byte(c.Data[constants.DataKeyKubeconfig]))
+	if err != nil {
+		return false, fmt.Sprintf("could not parse kubeconfig: %s", err), nil
+	}
+
+	if scope == constants.KubeconfigScopeGarden {
+		if errs := validateKubeconfigAgainstGarden(kubeconfig, &h.getConfig().Kubeconfig.Garden, fldPath); len(errs) > 0 {
+			return false, errs.ToAggregate().Error(), nil
+		}
+
+		return true, "allowed to be admitted", nil
+	}
+
+	clustersByShoot := make(map[client.ObjectKey]*clientcmdapi.Config)
+	shootRefs := clusterShootRefs(kubeconfig)
+
+	for name, cluster := range kubeconfig.Clusters {
+		// the kubeconfig of a single shoot is verified against the shoot named by the configMap
+		key := client.ObjectKey{Namespace: c.Namespace, Name: strings.TrimSuffix(c.Name, constants.KubeconfigConfigMapNameSuffix)}
+
+		if scope != "" {
+			shootRef, ok := shootRefs[name]
+			if !ok {
+				return false, fmt.Sprintf("cluster %s of kubeconfig does not reference a shoot", name), nil
+			}
+
+			key = client.ObjectKey{Namespace: shootRef.Namespace, Name: shootRef.Name}
+		}
+
+		if _, ok := clustersByShoot[key]; !ok {
+			clustersByShoot[key] = clientcmdapi.NewConfig()
+		}
+
+		clustersByShoot[key].Clusters[name] = cluster
+	}
+
+	keys := make([]client.ObjectKey, 0, len(clustersByShoot))
+	for key := range clustersByShoot {
+		keys = append(keys, key)
+	}
+
+	// verify the shoots in a stable order, so that the same reason is returned for the same kubeconfig
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	for _, key := range keys {
+		if allowed, reason, err := h.validatingShootClustersFn(ctx, clustersByShoot[key], key, fldPath); err != nil || !allowed {
+			return allowed, reason, err
+		}
+	}
+
+	return true, "allowed to be admitted", nil
+}
+
+// validatingShootClustersFn verifies the clusters of the given kubeconfig against the Shoot with the given key and its certificate authority
+func (h *ConfigmapValidator) validatingShootClustersFn(ctx context.Context, kubeconfig *clientcmdapi.Config, key client.ObjectKey, fldPath *field.Path) (bool, string, error) {
+	shoot, rotationPhase, err := util.GetShoot(ctx, h.client, key)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, fmt.Sprintf("shoot %s of kubeconfig configmap not found", key), nil
+		}
+
+		return false, "failed to fetch shoot", err
+	}
+
+	caSource, err := h.getCASource()
+	if err != nil {
+		return false, "failed to read certificate authority of shoot", err
+	}
+
+	caCert, err := caSource.ClusterCA(ctx, key, rotationPhase)
+	if err != nil {
+		if errors.Is(err, util.ErrCASourceMissing) || errors.Is(err, util.ErrCANotProvisioned) {
+			return false, fmt.Sprintf("could not read certificate authority of shoot: %s", err), nil
+		}
+
+		return false, "failed to read certificate authority of shoot", err
+	}
+
+	if errs := validateKubeconfigAgainstShoot(kubeconfig, shoot, caCert, fldPath); len(errs) > 0 {
+		return false, errs.ToAggregate().Error(), nil
+	}
+
+	return true, "allowed to be admitted", nil
+}
+
+// validatingKubeconfigConfigMapDeletionFn validates that only the gardenlogin-controller-manager and the users of the deletion allowlist delete kubeconfig configMaps
+func (h *ConfigmapValidator) validatingKubeconfigConfigMapDeletionFn(ctx context.Context, c *corev1.ConfigMap, admissionReq admissionv1.AdmissionRequest) (bool, string, error) {
+	if !hasKubeconfigRole(c) {
+		return true, "not a kubeconfig configmap", nil
+	}
+
+	userInfo := admissionReq.UserInfo
+
+	for _, user := range h.getConfig().Webhooks.ConfigMapValidation.DeletionAllowedUsers {
+		if userInfo.Username == user {
+			return true, "allowed to delete kubeconfig configmaps", nil
+		}
+	}
+
+	if allowed, err := h.canManageConfigmaps(ctx, userInfo, c.Namespace, c.Name); err != nil {
 		return false, err.Error(), nil
 	} else if !allowed {
 		return false, "not allowed to manage configmaps", nil
@@ -62,6 +372,27 @@ func (h *ConfigmapValidator) validatingKubeconfigConfigMapFn(ctx context.Context
 	return true, "allowed to be admitted", nil
 }
 
+// hasKubeconfigRole returns true in case the given configMap has the kubeconfig role label
+func hasKubeconfigRole(c *corev1.ConfigMap) bool {
+	return c.Labels[constants.GardenerOperationsRole] == constants.GardenerOperationsKubeconfig
+}
+
+// kubeconfigConfigMapTransition describes the change from the old to the new configMap in case it adds or removes the kubeconfig role label
+// or changes the controller ownerReference of a kubeconfig configMap. These transitions require the permission to manage configMaps, as they change which configMaps
+// the controllers are responsible for and whether the garbage collector deletes them. An empty string is returned for other changes.
+func kubeconfigConfigMapTransition(oldC *corev1.ConfigMap, c *corev1.ConfigMap) string {
+	switch {
+	case !hasKubeconfigRole(oldC) && hasKubeconfigRole(c):
+		return "adding the kubeconfig role label"
+	case hasKubeconfigRole(oldC) && !hasKubeconfigRole(c):
+		return "removing the kubeconfig role label"
+	case hasKubeconfigRole(c) && !apiequality.Semantic.DeepEqual(metav1.GetControllerOf(oldC), metav1.GetControllerOf(c)):
+		return "changing the controller ownerReference"
+	default:
+		return ""
+	}
+}
+
 type fldValidation struct {
 	value   *string
 	fldPath *field.Path
@@ -97,6 +428,49 @@ func validateRequiredField(val *string, fldPath *field.Path) error {
 	return nil
 }
 
+// canManageConfigmaps returns true in case the given user is allowed to manage the configMap with the given namespace and name.
+// The SubjectAccessReview is skipped for the configured accessReviewSkippedUsers and its result is cached in case the access review cache is enabled.
+func (h *ConfigmapValidator) canManageConfigmaps(ctx context.Context, userInfo authenticationv1.UserInfo, namespace string, name string) (bool, error) {
+	config := h.getConfig().Webhooks.ConfigMapValidation
+
+	for _, user := range config.AccessReviewSkippedUsers {
+		if userInfo.Username == user {
+			accessReviewsSkippedTotal.Inc()
+			return true, nil
+		}
+	}
+
+	if !config.AccessReviewCache.Enabled {
+		return h.canManageConfigmapsAccessReview(ctx, userInfo, namespace, name)
+	}
+
+	key, err := h.accessReviews.key(userInfo, namespace, name)
+	if err != nil {
+		return false, err
+	}
+
+	if allowed, ok := h.accessReviews.get(key); ok {
+		accessReviewCacheRequestsTotal.WithLabelValues(accessReviewCacheHit).Inc()
+		return allowed, nil
+	}
+
+	accessReviewCacheRequestsTotal.WithLabelValues(accessReviewCacheMiss).Inc()
+
+	allowed, err := h.canManageConfigmapsAccessReview(ctx, userInfo, namespace, name)
+	if err != nil {
+		return false, err
+	}
+
+	ttl := config.AccessReviewCache.DeniedTTL.Duration
+	if allowed {
+		ttl = config.AccessReviewCache.AllowedTTL.Duration
+	}
+
+	h.accessReviews.set(key, allowed, ttl)
+
+	return allowed, nil
+}
+
 func (h *ConfigmapValidator) canManageConfigmapsAccessReview(ctx context.Context, userInfo authenticationv1.UserInfo, namespace string, name string) (bool, error) {
 	extra := make(map[string]authorizationv1.ExtraValue)
 	for k, v := range userInfo.Extra {
@@ -119,14 +493,53 @@ func (h *ConfigmapValidator) canManageConfigmapsAccessReview(ctx context.Context
 		},
 	}
 	err := h.client.Create(ctx, subjectAccessReview)
+	if err == nil {
+		h.recordAccessReview()
+	}
 
 	return subjectAccessReview.Status.Allowed, err
 }
 
+// recordAccessReview records a successful SubjectAccessReview round-trip
+func (h *ConfigmapValidator) recordAccessReview() {
+	h.lastAccessReviewMutex.Lock()
+	defer h.lastAccessReviewMutex.Unlock()
+
+	h.lastAccessReview = time.Now()
+}
+
+// AccessReviewCheck is a readiness check that fails in case the SubjectAccessReviews, on which the validation of kubeconfig configMaps depends,
+// cannot be created. In case no SubjectAccessReview succeeded within the accessReviewCheckInterval, a SubjectAccessReview is created by the check.
+func (h *ConfigmapValidator) AccessReviewCheck(req *http.Request) error {
+	h.lastAccessReviewMutex.RLock()
+	lastAccessReview := h.lastAccessReview
+	h.lastAccessReviewMutex.RUnlock()
+
+	if time.Since(lastAccessReview) < accessReviewCheckInterval {
+		return nil
+	}
+
+	if h.client == nil {
+		return errors.New("client not injected yet")
+	}
+
+	// the result of the access review is not relevant, only that it can be created
+	_, err := h.canManageConfigmapsAccessReview(req.Context(), authenticationv1.UserInfo{Username: user.Anonymous}, metav1.NamespaceSystem, "")
+	if err != nil {
+		return fmt.Errorf("failed to create subject access review: %w", err)
+	}
+
+	return nil
+}
+
 var _ admission.Handler = &ConfigmapValidator{}
 
 // Handle handles admission requests.
 func (h *ConfigmapValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.AdmissionRequest.Operation == admissionv1.Delete {
+		return h.handleDelete(ctx, req)
+	}
+
 	obj := &corev1.ConfigMap{}
 	oldObj := &corev1.ConfigMap{}
 
@@ -152,17 +565,44 @@ func (h *ConfigmapValidator) Handle(ctx context.Context, req admission.Request)
 		}
 	}
 
-	allowed, reason, err := h.validatingKubeconfigConfigMapFn(ctx, obj, oldObj, req.AdmissionRequest)
+	warnings := kubeconfigConfigMapWarnings(obj, objSize, maxObjSize)
+
+	allowed, reason, contentWarnings, err := h.validatingKubeconfigConfigMapFn(ctx, obj, oldObj, req.AdmissionRequest)
+
+	return h.validationResponse(req, allowed, reason, err, append(warnings, contentWarnings...))
+}
+
+// handleDelete handles admission requests for the deletion of configMaps. The deleted configMap is passed as old object.
+func (h *ConfigmapValidator) handleDelete(ctx context.Context, req admission.Request) admission.Response {
+	oldObj := &corev1.ConfigMap{}
+	if err := h.decoder.DecodeRaw(req.AdmissionRequest.OldObject, oldObj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	allowed, reason, err := h.validatingKubeconfigConfigMapDeletionFn(ctx, oldObj, req.AdmissionRequest)
+
+	return h.validationResponse(req, allowed, reason, err, nil)
+}
+
+// validationResponse returns the admission response for the given validation result with the given warnings
+func (h *ConfigmapValidator) validationResponse(req admission.Request, allowed bool, reason string, err error, warnings []string) admission.Response {
+	log := h.Log.WithValues("namespace", req.Namespace, "name", req.Name, "operation", req.Operation, "dryRun", req.DryRun != nil && *req.DryRun)
+
 	if err != nil {
-		h.Log.Error(err, reason)
-		return admission.Errored(http.StatusInternalServerError, err)
+		log.Error(err, reason)
+		return admission.Errored(http.StatusInternalServerError, err).WithWarnings(warnings...)
 	}
 
 	if !allowed {
-		h.Log.Info("admission request denied", "reason", reason)
+		log.Info("admission request denied", "reason", reason)
 	}
 
-	return admission.ValidationResponse(allowed, reason)
+	return admission.ValidationResponse(allowed, reason).WithWarnings(warnings...)
+}
+
+// warnOnlyWarning returns the warning for a request that is admitted in warn-only mode although it would be denied for the given reason
+func warnOnlyWarning(reason string) string {
+	return fmt.Sprintf("request would be denied: %s", reason)
 }
 
 var _ inject.Client = &ConfigmapValidator{}
@@ -171,7 +611,27 @@ var _ inject.Client = &ConfigmapValidator{}
 
 // InjectClient injects the client.
 func (h *ConfigmapValidator) InjectClient(c client.Client) error {
+	h.configMutex.Lock()
+	defer h.configMutex.Unlock()
+
 	h.client = c
+	h.caSource = nil
+
+	return nil
+}
+
+var _ inject.APIReader = &ConfigmapValidator{}
+
+// An uncached reader will be automatically injected.
+
+// InjectAPIReader injects the reader, which is used to read the <shoot>.ca-cluster Secrets.
+func (h *ConfigmapValidator) InjectAPIReader(r client.Reader) error {
+	h.configMutex.Lock()
+	defer h.configMutex.Unlock()
+
+	h.apiReader = r
+	h.caSource = nil
+
 	return nil
 }
 
@@ -0,0 +1,434 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
+	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1/constants"
+)
+
+// accessReviewClient answers the creation of SubjectAccessReviews with the configured error. The reviews of the resources of the
+// namespaces listed in allowed are allowed, e.g. allowed["shoots"] = []string{"garden-dev"}.
+type accessReviewClient struct {
+	client.Client
+	err     error
+	allowed map[string][]string
+}
+
+func (c *accessReviewClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if review, ok := obj.(*authorizationv1.SubjectAccessReview); ok {
+		for _, namespace := range c.allowed[review.Spec.ResourceAttributes.Resource] {
+			if namespace == review.Spec.ResourceAttributes.Namespace {
+				review.Status.Allowed = true
+			}
+		}
+
+		return c.err
+	}
+
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+var _ = Describe("ConfigmapValidator", func() {
+	var (
+		validator *ConfigmapValidator
+		req       admission.Request
+	)
+
+	BeforeEach(func() {
+		config := &configv1alpha1.ControllerManagerConfiguration{}
+		configv1alpha1.SetDefaults_ControllerManagerConfiguration(config)
+
+		validator = &ConfigmapValidator{
+			Log:    logr.Discard(),
+			Config: config,
+		}
+	})
+
+	Describe("#AccessReviewCheck", func() {
+		var req *http.Request
+
+		BeforeEach(func() {
+			req = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+		})
+
+		It("should succeed after a recent successful access review", func() {
+			validator.recordAccessReview()
+
+			Expect(validator.AccessReviewCheck(req)).To(Succeed())
+		})
+
+		It("should create an access review in case none succeeded recently", func() {
+			Expect(validator.InjectClient(&accessReviewClient{Client: fake.NewClientBuilder().Build()})).To(Succeed())
+
+			Expect(validator.AccessReviewCheck(req)).To(Succeed())
+			Expect(validator.lastAccessReview).To(BeTemporally("~", time.Now(), time.Second))
+		})
+
+		It("should fail in case the access review cannot be created", func() {
+			Expect(validator.InjectClient(&accessReviewClient{Client: fake.NewClientBuilder().Build(), err: errors.New("unavailable")})).To(Succeed())
+
+			Expect(validator.AccessReviewCheck(req)).To(MatchError(ContainSubstring("unavailable")))
+			Expect(validator.lastAccessReview).To(BeZero())
+		})
+	})
+
+	Describe("#validatingKubeconfigConfigMapFn", func() {
+		var (
+			ctx          context.Context
+			admissionReq admissionv1.AdmissionRequest
+		)
+
+		// shoot returns a shoot with the given namespace and name that advertises its external address
+		shoot := func(namespace, name string) *unstructured.Unstructured {
+			return &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": gardencorev1beta1.SchemeGroupVersion.String(),
+				"kind":       "Shoot",
+				"metadata": map[string]interface{}{
+					"name":      name,
+					"namespace": namespace,
+				},
+				"status": map[string]interface{}{
+					"advertisedAddresses": []interface{}{
+						map[string]interface{}{"name": "external", "url": fmt.Sprintf("https://api.%s.%s.example.com", name, namespace)},
+					},
+				},
+			}}
+		}
+
+		// caCluster returns the <shoot>.ca-cluster configMap of the shoot with the given namespace and name
+		caCluster := func(namespace, name string) *corev1.ConfigMap {
+			return &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: name + ".ca-cluster", Namespace: namespace},
+				Data:       map[string]string{"ca.crt": "ca"},
+			}
+		}
+
+		// kubeconfigConfigMap returns a kubeconfig configMap with the given name and scope label, whose cluster references the given shoot and uses the given server
+		kubeconfigConfigMap := func(name, scope, shootNamespace, shootName, server string) *corev1.ConfigMap {
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "garden-dev",
+					Labels: map[string]string{
+						constants.GardenerOperationsRole: constants.GardenerOperationsKubeconfig,
+					},
+				},
+				Data: map[string]string{
+					constants.DataKeyKubeconfig: fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: %[1]s--%[2]s
+  cluster:
+    server: %[3]s
+    certificate-authority-data: Y2E=
+    extensions:
+    - name: client.authentication.k8s.io/exec
+      extension:
+        shootRef:
+          namespace: %[1]s
+          name: %[2]s
+        gardenClusterIdentity: landscape
+`, shootNamespace, shootName, server),
+				},
+			}
+
+			if scope != "" {
+				configMap.Labels[constants.LabelKubeconfigScope] = scope
+			}
+
+			return configMap
+		}
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			admissionReq = admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				UserInfo:  authenticationv1.UserInfo{Username: "controller"},
+			}
+
+			validator.Config.Kubeconfig.CASources = []configv1alpha1.CASourceType{configv1alpha1.CASourceConfigMap}
+			validator.Config.Webhooks.ConfigMapValidation.Strict = true
+			validator.Config.Webhooks.ConfigMapValidation.AccessReviewSkippedUsers = []string{"controller"}
+
+			scheme := runtime.NewScheme()
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+			// the shoots are unstructured, as they are read as unstructured objects
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				shoot("garden-dev", "foo"), caCluster("garden-dev", "foo"),
+				shoot("garden-prod", "bar"), caCluster("garden-prod", "bar"),
+			).Build()
+			Expect(validator.InjectClient(c)).To(Succeed())
+			Expect(validator.InjectAPIReader(c)).To(Succeed())
+		})
+
+		It("should accept the kubeconfig of the shoot", func() {
+			configMap := kubeconfigConfigMap("foo.kubeconfig", "", "garden-dev", "foo", "https://api.foo.garden-dev.example.com")
+
+			allowed, reason, _, err := validator.validatingKubeconfigConfigMapFn(ctx, configMap, nil, admissionReq)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(allowed).To(BeTrue(), reason)
+		})
+
+		It("should build the ca source once and again after the ca sources changed", func() {
+			configMap := kubeconfigConfigMap("foo.kubeconfig", "", "garden-dev", "foo", "https://api.foo.garden-dev.example.com")
+
+			allowed, reason, _, err := validator.validatingKubeconfigConfigMapFn(ctx, configMap, nil, admissionReq)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(allowed).To(BeTrue(), reason)
+
+			caSource := validator.caSource
+			Expect(caSource).NotTo(BeNil())
+
+			validator.InjectConfig(validator.Config.DeepCopy())
+			Expect(validator.caSource).To(BeIdenticalTo(caSource))
+
+			// the <shoot>.ca-cluster secret does not exist
+			config := validator.Config.DeepCopy()
+			config.Kubeconfig.CASources = []configv1alpha1.CASourceType{configv1alpha1.CASourceSecret}
+			validator.InjectConfig(config)
+
+			allowed, reason, _, err = validator.validatingKubeconfigConfigMapFn(ctx, configMap, nil, admissionReq)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(allowed).To(BeFalse())
+			Expect(reason).To(ContainSubstring("could not read certificate authority of shoot"))
+		})
+
+		It("should reject a forged scope label instead of skipping the verification against the shoot", func() {
+			configMap := kubeconfigConfigMap("foo.kubeconfig", constants.KubeconfigScopeBundle, "garden-dev", "foo", "https://rogue.example.com")
+
+			allowed, reason, _, err := validator.validatingKubeconfigConfigMapFn(ctx, configMap, nil, admissionReq)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(allowed).To(BeFalse())
+			Expect(reason).To(ContainSubstring("must not be set for the kubeconfig of a shoot"))
+		})
+
+		It("should verify the clusters of a bundle kubeconfig against the referenced shoots", func() {
+			configMap := kubeconfigConfigMap("sre.bundle.kubeconfig", constants.KubeconfigScopeBundle, "garden-prod", "bar", "https://api.bar.garden-prod.example.com")
+
+			allowed, reason, _, err := validator.validatingKubeconfigConfigMapFn(ctx, configMap, nil, admissionReq)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(allowed).To(BeTrue(), reason)
+
+			configMap = kubeconfigConfigMap("sre.bundle.kubeconfig", constants.KubeconfigScopeBundle, "garden-prod", "bar", "https://rogue.example.com")
+
+			allowed, reason, _, err = validator.validatingKubeconfigConfigMapFn(ctx, configMap, nil, admissionReq)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(allowed).To(BeFalse())
+			Expect(reason).To(ContainSubstring("must match one of the advertised addresses of the shoot"))
+		})
+
+		Context("shoot references of aggregated kubeconfigs", func() {
+			BeforeEach(func() {
+				validator.Config.Webhooks.ConfigMapValidation.Strict = false
+				admissionReq.UserInfo = authenticationv1.UserInfo{Username: "alice"}
+
+				c := validator.client
+				Expect(validator.InjectClient(&accessReviewClient{Client: c, allowed: map[string][]string{
+					"configmaps": {"garden-dev"},
+					"shoots":     {"garden-dev"},
+				}})).To(Succeed())
+			})
+
+			It("should accept a bundle referencing shoots the requester may read", func() {
+				configMap := kubeconfigConfigMap("sre.bundle.kubeconfig", constants.KubeconfigScopeBundle, "garden-dev", "foo", "https://api.foo.garden-dev.example.com")
+
+				allowed, reason, _, err := validator.validatingKubeconfigConfigMapFn(ctx, configMap, nil, admissionReq)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(allowed).To(BeTrue(), reason)
+			})
+
+			It("should reject a bundle referencing shoots the requester may not read", func() {
+				configMap := kubeconfigConfigMap("sre.bundle.kubeconfig", constants.KubeconfigScopeBundle, "garden-prod", "bar", "https://api.bar.garden-prod.example.com")
+
+				allowed, reason, _, err := validator.validatingKubeconfigConfigMapFn(ctx, configMap, nil, admissionReq)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(allowed).To(BeFalse())
+				Expect(reason).To(Equal("not allowed to get shoot garden-prod/bar referenced by kubeconfig"))
+			})
+
+			It("should reject a bundle referencing shoots that do not exist", func() {
+				configMap := kubeconfigConfigMap("sre.bundle.kubeconfig", constants.KubeconfigScopeBundle, "garden-dev", "baz", "https://api.baz.garden-dev.example.com")
+
+				allowed, reason, _, err := validator.validatingKubeconfigConfigMapFn(ctx, configMap, nil, admissionReq)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(allowed).To(BeFalse())
+				Expect(reason).To(Equal("shoot garden-dev/baz referenced by kubeconfig not found"))
+			})
+
+			It("should reject a forged bundle scope label on the project kubeconfig referencing shoots of other namespaces", func() {
+				configMap := kubeconfigConfigMap(constants.ProjectKubeconfigConfigMapName, constants.KubeconfigScopeBundle, "garden-prod", "bar", "https://api.bar.garden-prod.example.com")
+
+				allowed, reason, _, err := validator.validatingKubeconfigConfigMapFn(ctx, configMap, nil, admissionReq)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(allowed).To(BeFalse())
+				Expect(reason).To(ContainSubstring("supported values: \"project\""))
+			})
+
+			It("should reject an unknown scope label on a shoot kubeconfig referencing another shoot", func() {
+				configMap := kubeconfigConfigMap("foo.kubeconfig", "foo", "garden-prod", "bar", "https://api.bar.garden-prod.example.com")
+
+				allowed, reason, _, err := validator.validatingKubeconfigConfigMapFn(ctx, configMap, nil, admissionReq)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(allowed).To(BeFalse())
+				Expect(reason).To(ContainSubstring("must not be set for the kubeconfig of a shoot"))
+			})
+		})
+
+		Context("warn-only mode", func() {
+			BeforeEach(func() {
+				validator.Config.Webhooks.ConfigMapValidation.WarnOnly = true
+			})
+
+			It("should admit a kubeconfig that would be denied by the strict validation with a warning", func() {
+				configMap := kubeconfigConfigMap("foo.kubeconfig", "", "garden-dev", "foo", "https://rogue.example.com")
+
+				allowed, reason, warnings, err := validator.validatingKubeconfigConfigMapFn(ctx, configMap, nil, admissionReq)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(allowed).To(BeTrue(), reason)
+				Expect(warnings).To(ConsistOf(ContainSubstring("request would be denied: data.kubeconfig")))
+			})
+
+			It("should still reject a requester that is not allowed to manage configmaps", func() {
+				admissionReq.UserInfo = authenticationv1.UserInfo{Username: "alice"}
+				Expect(validator.InjectClient(&accessReviewClient{Client: validator.client})).To(Succeed())
+
+				configMap := kubeconfigConfigMap("foo.kubeconfig", "", "garden-dev", "foo", "https://rogue.example.com")
+
+				allowed, reason, warnings, err := validator.validatingKubeconfigConfigMapFn(ctx, configMap, nil, admissionReq)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(allowed).To(BeFalse())
+				Expect(reason).To(Equal("not allowed to manage configmaps"))
+				Expect(warnings).To(BeEmpty())
+			})
+
+			It("should still reject the deletion by a requester that is not allowed to manage configmaps", func() {
+				admissionReq.Operation = admissionv1.Delete
+				admissionReq.UserInfo = authenticationv1.UserInfo{Username: "alice"}
+				Expect(validator.InjectClient(&accessReviewClient{Client: validator.client})).To(Succeed())
+
+				configMap := kubeconfigConfigMap("foo.kubeconfig", "", "garden-dev", "foo", "https://api.foo.garden-dev.example.com")
+
+				allowed, reason, err := validator.validatingKubeconfigConfigMapDeletionFn(ctx, configMap, admissionReq)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(allowed).To(BeFalse())
+				Expect(reason).To(Equal("not allowed to manage configmaps"))
+			})
+		})
+
+		It("should reject the clusters of a bundle kubeconfig that reference no existing shoot", func() {
+			configMap := kubeconfigConfigMap("sre.bundle.kubeconfig", constants.KubeconfigScopeBundle, "garden-prod", "baz", "https://api.baz.garden-prod.example.com")
+
+			allowed, reason, _, err := validator.validatingKubeconfigConfigMapFn(ctx, configMap, nil, admissionReq)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(allowed).To(BeFalse())
+			Expect(reason).To(ContainSubstring("not found"))
+		})
+	})
+
+	Describe("#kubeconfigConfigMapTransition", func() {
+		var oldConfigMap, configMap *corev1.ConfigMap
+
+		BeforeEach(func() {
+			oldConfigMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo.kubeconfig",
+					Namespace: "garden-dev",
+					Labels: map[string]string{
+						constants.GardenerOperationsRole: constants.GardenerOperationsKubeconfig,
+					},
+					OwnerReferences: []metav1.OwnerReference{
+						*metav1.NewControllerRef(&metav1.ObjectMeta{Name: "foo", UID: "1"}, gardencorev1beta1.SchemeGroupVersion.WithKind("Shoot")),
+					},
+				},
+			}
+			configMap = oldConfigMap.DeepCopy()
+		})
+
+		It("should not report other changes", func() {
+			configMap.Data = map[string]string{constants.DataKeyKubeconfig: "foo"}
+			configMap.Labels["foo"] = "bar"
+
+			Expect(kubeconfigConfigMapTransition(oldConfigMap, configMap)).To(BeEmpty())
+		})
+
+		It("should report adding the kubeconfig role label", func() {
+			delete(oldConfigMap.Labels, constants.GardenerOperationsRole)
+
+			Expect(kubeconfigConfigMapTransition(oldConfigMap, configMap)).To(Equal("adding the kubeconfig role label"))
+		})
+
+		It("should report removing the kubeconfig role label", func() {
+			configMap.Labels[constants.GardenerOperationsRole] = "foo"
+
+			Expect(kubeconfigConfigMapTransition(oldConfigMap, configMap)).To(Equal("removing the kubeconfig role label"))
+		})
+
+		It("should report changing the controller ownerReference", func() {
+			configMap.OwnerReferences[0].UID = "2"
+			Expect(kubeconfigConfigMapTransition(oldConfigMap, configMap)).To(Equal("changing the controller ownerReference"))
+
+			configMap.OwnerReferences = nil
+			Expect(kubeconfigConfigMapTransition(oldConfigMap, configMap)).To(Equal("changing the controller ownerReference"))
+		})
+
+		It("should not report ownerReference changes of other configmaps", func() {
+			delete(oldConfigMap.Labels, constants.GardenerOperationsRole)
+			delete(configMap.Labels, constants.GardenerOperationsRole)
+			configMap.OwnerReferences = nil
+
+			Expect(kubeconfigConfigMapTransition(oldConfigMap, configMap)).To(BeEmpty())
+		})
+	})
+
+	Describe("#validationResponse", func() {
+		It("should return the warnings of allowed requests", func() {
+			resp := validator.validationResponse(req, true, "allowed to be admitted", nil, []string{"foo"})
+
+			Expect(resp.Allowed).To(BeTrue())
+			Expect(resp.Warnings).To(ConsistOf("foo"))
+		})
+
+		It("should deny requests", func() {
+			resp := validator.validationResponse(req, false, "not allowed to manage configmaps", nil, []string{"foo"})
+
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(resp.Result.Reason).To(BeEquivalentTo("not allowed to manage configmaps"))
+			Expect(resp.Warnings).To(ConsistOf("foo"))
+		})
+
+		It("should return errors", func() {
+			resp := validator.validationResponse(req, false, "failed to fetch shoot", errors.New("foo"), nil)
+
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(resp.Result.Code).To(BeEquivalentTo(http.StatusInternalServerError))
+		})
+	})
+})
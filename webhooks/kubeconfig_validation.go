@@ -0,0 +1,443 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
+	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1"
+	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1/constants"
+)
+
+// execExtensionName is the name of the cluster extension that kubectl passes to the credential plugin
+const execExtensionName = "client.authentication.k8s.io/exec"
+
+// objectSizeWarningPercentage is the percentage of the maximum object size, from which on a warning is returned for kubeconfig configMaps
+const objectSizeWarningPercentage = 90
+
+// deprecatedExecAPIVersions maps the deprecated exec API versions to the warning that is returned for kubeconfigs using them
+var deprecatedExecAPIVersions = map[string]string{
+	"client.authentication.k8s.io/v1alpha1": "exec API version client.authentication.k8s.io/v1alpha1 is deprecated and not supported by kubectl v1.24 onwards, use client.authentication.k8s.io/v1beta1 or client.authentication.k8s.io/v1",
+}
+
+// kubeconfigConfigMapWarnings returns the warnings for the given kubeconfig configMap with the given size in bytes,
+// which do not lead to the denial of the request
+func kubeconfigConfigMapWarnings(configMap *corev1.ConfigMap, objSize, maxObjSize int) []string {
+	if !hasKubeconfigRole(configMap) {
+		return nil
+	}
+
+	var warnings []string
+
+	if !strings.HasSuffix(configMap.Name, constants.KubeconfigConfigMapNameSuffix) {
+		warnings = append(warnings, fmt.Sprintf("configmap has the %s=%s label, but its name does not end with %s", constants.GardenerOperationsRole, constants.GardenerOperationsKubeconfig, constants.KubeconfigConfigMapNameSuffix))
+	}
+
+	if objSize <= maxObjSize && objSize*100 >= maxObjSize*objectSizeWarningPercentage {
+		warnings = append(warnings, fmt.Sprintf("configmap has %d bytes, which is close to the maximum of %d bytes", objSize, maxObjSize))
+	}
+
+	kubeconfig, err := clientcmd.Load([]byte(configMap.Data[constants.DataKeyKubeconfig]))
+	if err != nil {
+		// reported by validateKubeconfig
+		return warnings
+	}
+
+	var execWarnings []string
+
+	for name, authInfo := range kubeconfig.AuthInfos {
+		if authInfo.Exec == nil {
+			continue
+		}
+
+		if warning, ok := deprecatedExecAPIVersions[authInfo.Exec.APIVersion]; ok {
+			execWarnings = append(execWarnings, fmt.Sprintf("user %s: %s", name, warning))
+		}
+	}
+
+	// sort the warnings, as the users are iterated in random order
+	sort.Strings(execWarnings)
+
+	return append(warnings, execWarnings...)
+}
+
+// kubeconfigScope returns the scope of the given kubeconfig configMap, which is derived from its name: the project.kubeconfig and garden.kubeconfig configMaps
+// hold the project and garden kubeconfig, the <bundle>.bundle.kubeconfig configMaps the kubeconfig of a bundle and all other configMaps the kubeconfig of a single shoot,
+// for which an empty scope is returned. As the kubeconfig of a shoot named like the project or garden kubeconfig takes precedence, these configMaps hold the kubeconfig
+// of a single shoot in case the scope label is not set. A scope label that is missing, unknown or does not match the name is rejected, so that the label cannot be used
+// to select a more lenient validation.
+func kubeconfigScope(configMap *corev1.ConfigMap, fldPath *field.Path) (string, *field.Error) {
+	scope, labelled := configMap.Labels[constants.LabelKubeconfigScope]
+
+	var expected string
+
+	switch {
+	case configMap.Name == constants.ProjectKubeconfigConfigMapName:
+		expected = constants.KubeconfigScopeProject
+	case configMap.Name == constants.GardenKubeconfigConfigMapName:
+		expected = constants.KubeconfigScopeGarden
+	case strings.HasSuffix(configMap.Name, constants.BundleKubeconfigConfigMapNameSuffix):
+		// shoot names must not contain dots, hence the configMap cannot hold the kubeconfig of a shoot
+		if !labelled {
+			return "", field.Required(fldPath, fmt.Sprintf("must be %s for configmaps named <bundle>%s", constants.KubeconfigScopeBundle, constants.BundleKubeconfigConfigMapNameSuffix))
+		}
+
+		expected = constants.KubeconfigScopeBundle
+	}
+
+	switch {
+	case !labelled:
+		return "", nil
+	case expected == "":
+		return "", field.Forbidden(fldPath, "must not be set for the kubeconfig of a shoot")
+	case scope != expected:
+		return "", field.NotSupported(fldPath, scope, []string{expected})
+	default:
+		return scope, nil
+	}
+}
+
+// validateKubeconfig validates the kubeconfig of the given kubeconfig configMap with the given scope, see kubeconfigScope. It ensures that the kubeconfig contains
+// no credentials, that its exec sections match the exec section that is configured for the scope of the configMap and that each cluster references
+// a shoot the configMap is responsible for.
+func validateKubeconfig(configMap *corev1.ConfigMap, scope string, config *configv1alpha1.KubeconfigConfiguration, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	kubeconfig, err := clientcmd.Load([]byte(configMap.Data[constants.DataKeyKubeconfig]))
+	if err != nil {
+		return append(allErrs, field.Invalid(fldPath, "<omitted>", fmt.Sprintf("could not parse kubeconfig: %s", err)))
+	}
+
+	policy := allowedExec(scope, config)
+
+	for name, authInfo := range kubeconfig.AuthInfos {
+		allErrs = append(allErrs, validateAuthInfo(authInfo, policy, fldPath.Child("users").Key(name))...)
+	}
+
+	shootRefs := clusterShootRefs(kubeconfig)
+
+	for name, cluster := range kubeconfig.Clusters {
+		clusterPath := fldPath.Child("clusters").Key(name)
+
+		for extensionName, extension := range cluster.Extensions {
+			allErrs = append(allErrs, validateClusterExtension(configMap, scope, extension, clusterPath.Child("extensions").Key(extensionName))...)
+		}
+
+		if scope == constants.KubeconfigScopeGarden {
+			continue
+		}
+
+		shootRef, ok := shootRefs[name]
+
+		switch {
+		case !ok:
+			allErrs = append(allErrs, field.Required(clusterPath, "cluster must reference a shoot, either in the exec plugin config of its extensions or in the exec args of its user"))
+		case len(cluster.Extensions) == 0:
+			// the shoot reference of a legacy kubeconfig is passed in the exec args, the extensions are validated by validateClusterExtension
+			allErrs = append(allErrs, validateShootRef(configMap, scope, shootRef, clusterPath.Child("shootRef"))...)
+		}
+	}
+
+	return allErrs
+}
+
+// execPolicy describes the exec sections the users of a kubeconfig may contain
+type execPolicy struct {
+	// command is the command to execute
+	command string
+	// args are the args the exec section must start with
+	args []string
+	// flags are the names of the flags that may follow the args, e.g. to pass the shoot reference in legacy kubeconfigs
+	flags []string
+	// env are the environment variables of the exec section
+	env []configv1alpha1.ExecEnvVar
+}
+
+// legacyFlags are the flags with which legacy kubeconfigs pass the shoot reference and the garden cluster identity to the credential plugin
+var legacyFlags = []string{"--name", "--namespace", "--garden-cluster-identity"}
+
+// allowedExec returns the policy for the exec sections of a kubeconfig with the given scope. Nil is returned in case no exec section is allowed.
+func allowedExec(scope string, config *configv1alpha1.KubeconfigConfiguration) *execPolicy {
+	if scope != constants.KubeconfigScopeGarden {
+		return &execPolicy{
+			command: config.Exec.Command,
+			args:    config.Exec.Args,
+			flags:   legacyFlags,
+			env:     config.Exec.Env,
+		}
+	}
+
+	switch {
+	case config.Garden.OIDC != nil:
+		return &execPolicy{
+			command: "kubectl",
+			args:    config.Garden.OIDC.Args(),
+		}
+	case config.Garden.Exec != nil:
+		return &execPolicy{
+			command: config.Garden.Exec.Command,
+			args:    config.Garden.Exec.Args,
+			env:     config.Garden.Exec.Env,
+		}
+	default:
+		return nil
+	}
+}
+
+func validateAuthInfo(authInfo *clientcmdapi.AuthInfo, policy *execPolicy, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	forbidden := func(set bool, child string) {
+		if set {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child(child), "kubeconfig must not contain credentials"))
+		}
+	}
+
+	forbidden(authInfo.Token != "", "token")
+	forbidden(authInfo.TokenFile != "", "tokenFile")
+	forbidden(authInfo.ClientCertificate != "" || len(authInfo.ClientCertificateData) > 0, "client-certificate")
+	forbidden(authInfo.ClientKey != "" || len(authInfo.ClientKeyData) > 0, "client-key")
+	forbidden(authInfo.Username != "" || authInfo.Password != "", "username")
+	forbidden(authInfo.AuthProvider != nil, "auth-provider")
+
+	if exec := authInfo.Exec; exec != nil {
+		allErrs = append(allErrs, validateExec(exec, policy, fldPath.Child("exec"))...)
+	}
+
+	return allErrs
+}
+
+// validateExec validates that the given exec section calls the command of the given policy with its args, followed only by the flags of the policy,
+// and with its environment variables, so that the kubeconfig can neither call another command nor another plugin or subcommand of the command
+func validateExec(exec *clientcmdapi.ExecConfig, policy *execPolicy, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if policy == nil {
+		return append(allErrs, field.Forbidden(fldPath, "kubeconfig must not contain exec sections"))
+	}
+
+	if exec.Command != policy.command {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("command"), exec.Command, []string{policy.command}))
+	}
+
+	argsPath := fldPath.Child("args")
+
+	if len(exec.Args) < len(policy.args) || !equality.Semantic.DeepEqual(exec.Args[:len(policy.args)], policy.args) {
+		allErrs = append(allErrs, field.Invalid(argsPath, strings.Join(exec.Args, " "), fmt.Sprintf("must start with %q", strings.Join(policy.args, " "))))
+	} else {
+		seen := make(map[string]bool)
+
+		for i, arg := range exec.Args[len(policy.args):] {
+			flag := strings.SplitN(arg, "=", 2)[0]
+
+			if !isOneOf(flag, policy.flags) || seen[flag] {
+				allErrs = append(allErrs, field.Forbidden(argsPath.Index(len(policy.args)+i), fmt.Sprintf("must be one of the flags %s, each passed at most once", strings.Join(policy.flags, ", "))))
+			}
+
+			seen[flag] = true
+		}
+	}
+
+	env := make([]configv1alpha1.ExecEnvVar, 0, len(exec.Env))
+	for _, e := range exec.Env {
+		env = append(env, configv1alpha1.ExecEnvVar{Name: e.Name, Value: e.Value})
+	}
+
+	if len(env) != len(policy.env) || (len(env) > 0 && !equality.Semantic.DeepEqual(env, policy.env)) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("env"), "<omitted>", "must match the configured environment variables"))
+	}
+
+	return allErrs
+}
+
+// isOneOf returns true in case the given value is contained in the given values
+func isOneOf(value string, values []string) bool {
+	for _, v := range values {
+		if value == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validateClusterExtension(configMap *corev1.ConfigMap, scope string, extension runtime.Object, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if scope == constants.KubeconfigScopeGarden {
+		return append(allErrs, field.Forbidden(fldPath, "garden kubeconfig must not reference shoots"))
+	}
+
+	execPluginConfig, err := decodeExecPluginConfig(extension)
+	if err != nil {
+		return append(allErrs, field.Invalid(fldPath, "<omitted>", err.Error()))
+	}
+
+	return append(allErrs, validateShootRef(configMap, scope, execPluginConfig.ShootRef, fldPath.Child("shootRef"))...)
+}
+
+// validateShootRef validates that the given shoot reference of a cluster of the given kubeconfig configMap with the given scope references a shoot
+// the configMap is responsible for. The shoots referenced by bundle kubeconfigs are verified by the validating webhook against the API server,
+// see ConfigmapValidator.validatingShootRefsFn.
+func validateShootRef(configMap *corev1.ConfigMap, scope string, shootRef v1alpha1.ShootRef, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if shootRef.Namespace == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("namespace"), "namespace of shoot is required"))
+	}
+
+	if shootRef.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("name"), "name of shoot is required"))
+	}
+
+	switch scope {
+	case "":
+		// the kubeconfig of a single shoot, named <shoot>.kubeconfig
+		if shootRef.Namespace != configMap.Namespace || shootRef.Name != strings.TrimSuffix(configMap.Name, constants.KubeconfigConfigMapNameSuffix) {
+			allErrs = append(allErrs, field.Invalid(fldPath, fmt.Sprintf("%s/%s", shootRef.Namespace, shootRef.Name), "must reference the shoot of the configmap"))
+		}
+	case constants.KubeconfigScopeProject:
+		if shootRef.Namespace != configMap.Namespace {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("namespace"), shootRef.Namespace, "must reference a shoot of the namespace of the configmap"))
+		}
+	case constants.KubeconfigScopeBundle:
+		// bundles may reference the shoots of all namespaces
+	default:
+		allErrs = append(allErrs, field.Forbidden(fldPath, fmt.Sprintf("kubeconfig with scope %q must not reference shoots", scope)))
+	}
+
+	return allErrs
+}
+
+// decodeExecPluginConfig decodes the given cluster extension into the exec plugin config of the gardenlogin credential plugin
+func decodeExecPluginConfig(extension runtime.Object) (*v1alpha1.ExecPluginConfig, error) {
+	unknown, ok := extension.(*runtime.Unknown)
+	if !ok {
+		return nil, fmt.Errorf("unexpected extension type %T", extension)
+	}
+
+	execPluginConfig := &v1alpha1.ExecPluginConfig{}
+
+	decoder := json.NewDecoder(bytes.NewReader(unknown.Raw))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(execPluginConfig); err != nil {
+		return nil, fmt.Errorf("could not decode exec plugin config: %w", err)
+	}
+
+	return execPluginConfig, nil
+}
+
+// clusterShootRefs returns the references to the shoots of the clusters of the given kubeconfig by cluster name. The reference is read from the exec plugin config
+// in the cluster extensions or, for legacy kubeconfigs, from the --namespace and --name args of the exec sections of the users of the contexts of the cluster.
+// Clusters without a reference are omitted.
+func clusterShootRefs(kubeconfig *clientcmdapi.Config) map[string]v1alpha1.ShootRef {
+	shootRefs := make(map[string]v1alpha1.ShootRef)
+
+	for name, cluster := range kubeconfig.Clusters {
+		if extension, ok := cluster.Extensions[execExtensionName]; ok {
+			if execPluginConfig, err := decodeExecPluginConfig(extension); err == nil {
+				shootRefs[name] = execPluginConfig.ShootRef
+			}
+		}
+	}
+
+	for _, context := range kubeconfig.Contexts {
+		if _, ok := shootRefs[context.Cluster]; ok {
+			continue
+		}
+
+		authInfo, ok := kubeconfig.AuthInfos[context.AuthInfo]
+		if !ok || authInfo.Exec == nil {
+			continue
+		}
+
+		if shootRef, ok := legacyShootRef(authInfo.Exec.Args); ok {
+			shootRefs[context.Cluster] = shootRef
+		}
+	}
+
+	return shootRefs
+}
+
+// legacyShootRef returns the reference to the shoot that is passed with the --namespace and --name args to the credential plugin by legacy kubeconfigs
+func legacyShootRef(args []string) (v1alpha1.ShootRef, bool) {
+	shootRef := v1alpha1.ShootRef{}
+
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--namespace="):
+			shootRef.Namespace = strings.TrimPrefix(arg, "--namespace=")
+		case strings.HasPrefix(arg, "--name="):
+			shootRef.Name = strings.TrimPrefix(arg, "--name=")
+		}
+	}
+
+	return shootRef, shootRef.Namespace != "" && shootRef.Name != ""
+}
+
+// validateKubeconfigAgainstShoot validates that the servers of the given kubeconfig match the advertised addresses of the given shoot
+// and that the certificate authorities match the given ca certificate of the shoot.
+func validateKubeconfigAgainstShoot(kubeconfig *clientcmdapi.Config, shoot *gardencorev1beta1.Shoot, caCert []byte, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	advertisedHosts := make(map[string]bool)
+
+	for _, address := range shoot.Status.AdvertisedAddresses {
+		if u, err := url.Parse(address.URL); err == nil {
+			advertisedHosts[u.Host] = true
+		}
+	}
+
+	for name, cluster := range kubeconfig.Clusters {
+		clusterPath := fldPath.Child("clusters").Key(name)
+
+		if u, err := url.Parse(cluster.Server); err != nil || u.Scheme != "https" || !advertisedHosts[u.Host] {
+			allErrs = append(allErrs, field.Invalid(clusterPath.Child("server"), cluster.Server, "must match one of the advertised addresses of the shoot"))
+		}
+
+		if cluster.CertificateAuthority != "" || !bytes.Equal(cluster.CertificateAuthorityData, caCert) {
+			allErrs = append(allErrs, field.Invalid(clusterPath.Child("certificate-authority-data"), "<omitted>", "must match the certificate authority of the shoot"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateKubeconfigAgainstGarden validates that the servers of the given kubeconfig match the server of the given garden kubeconfig configuration
+// and that the certificate authorities match its certificate authority.
+func validateKubeconfigAgainstGarden(kubeconfig *clientcmdapi.Config, garden *configv1alpha1.GardenKubeconfigConfiguration, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for name, cluster := range kubeconfig.Clusters {
+		clusterPath := fldPath.Child("clusters").Key(name)
+
+		if cluster.Server != garden.Server {
+			allErrs = append(allErrs, field.Invalid(clusterPath.Child("server"), cluster.Server, "must match the server of the garden cluster"))
+		}
+
+		if cluster.CertificateAuthority != "" || !bytes.Equal(cluster.CertificateAuthorityData, []byte(garden.CertificateAuthority)) {
+			allErrs = append(allErrs, field.Invalid(clusterPath.Child("certificate-authority-data"), "<omitted>", "must match the certificate authority of the garden cluster"))
+		}
+	}
+
+	return allErrs
+}
@@ -0,0 +1,503 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhooks
+
+import (
+	"fmt"
+	"strings"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	configv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/config/v1alpha1"
+	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1"
+	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1/constants"
+)
+
+var _ = Describe("Kubeconfig validation", func() {
+	var (
+		config    *configv1alpha1.KubeconfigConfiguration
+		configMap *corev1.ConfigMap
+	)
+
+	// kubeconfig returns a kubeconfig for the given shoot with the given user
+	kubeconfig := func(namespace, name, user string) string {
+		return fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: %[1]s--%[2]s
+  cluster:
+    server: https://api.%[2]s.%[1]s.example.com
+    extensions:
+    - name: client.authentication.k8s.io/exec
+      extension:
+        shootRef:
+          namespace: %[1]s
+          name: %[2]s
+        gardenClusterIdentity: landscape
+contexts:
+- name: %[1]s--%[2]s
+  context:
+    cluster: %[1]s--%[2]s
+    user: %[1]s--%[2]s
+users:
+- name: %[1]s--%[2]s
+  user:
+%[3]s
+`, namespace, name, user)
+	}
+
+	execUser := `    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: kubectl
+      args:
+      - gardenlogin
+      - get-client-certificate`
+
+	fields := func(errs field.ErrorList) []string {
+		var fields []string
+		for _, err := range errs {
+			fields = append(fields, err.Field)
+		}
+
+		return fields
+	}
+
+	// validate validates the kubeconfig of the configMap with the scope derived from the configMap
+	validate := func() field.ErrorList {
+		scope, err := kubeconfigScope(configMap, field.NewPath("metadata", "labels").Key(constants.LabelKubeconfigScope))
+		Expect(err).To(BeNil())
+
+		return validateKubeconfig(configMap, scope, config, field.NewPath("data", "kubeconfig"))
+	}
+
+	BeforeEach(func() {
+		cfg := &configv1alpha1.ControllerManagerConfiguration{}
+		configv1alpha1.SetDefaults_ControllerManagerConfiguration(cfg)
+		config = &cfg.Kubeconfig
+
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo.kubeconfig",
+				Namespace: "garden-dev",
+				Labels: map[string]string{
+					constants.GardenerOperationsRole: constants.GardenerOperationsKubeconfig,
+				},
+			},
+			Data: map[string]string{
+				constants.DataKeyKubeconfig: kubeconfig("garden-dev", "foo", execUser),
+			},
+		}
+	})
+
+	It("should accept the kubeconfig of the shoot", func() {
+		Expect(validate()).To(BeEmpty())
+	})
+
+	It("should reject a kubeconfig that cannot be parsed", func() {
+		configMap.Data[constants.DataKeyKubeconfig] = "foo-kubeconfig"
+
+		Expect(fields(validate())).To(ConsistOf("data.kubeconfig"))
+	})
+
+	DescribeTable("should reject credentials",
+		func(user string, expectedField string) {
+			configMap.Data[constants.DataKeyKubeconfig] = kubeconfig("garden-dev", "foo", user)
+
+			Expect(fields(validate())).To(ConsistOf(expectedField))
+		},
+		Entry("token", "    token: foo", "data.kubeconfig.users[garden-dev--foo].token"),
+		Entry("client key", "    client-key-data: Zm9v", "data.kubeconfig.users[garden-dev--foo].client-key"),
+		Entry("basic auth", "    username: foo\n    password: bar", "data.kubeconfig.users[garden-dev--foo].username"),
+		Entry("auth provider", "    auth-provider:\n      name: oidc", "data.kubeconfig.users[garden-dev--foo].auth-provider"),
+	)
+
+	It("should reject an exec section with another command", func() {
+		configMap.Data[constants.DataKeyKubeconfig] = kubeconfig("garden-dev", "foo", `    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: curl
+      args:
+      - gardenlogin
+      - get-client-certificate`)
+
+		Expect(fields(validate())).To(ConsistOf("data.kubeconfig.users[garden-dev--foo].exec.command"))
+	})
+
+	It("should reject an exec section calling another plugin of the command", func() {
+		configMap.Data[constants.DataKeyKubeconfig] = kubeconfig("garden-dev", "foo", `    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: kubectl
+      args:
+      - some-plugin
+      - --exfil`)
+
+		Expect(fields(validate())).To(ConsistOf("data.kubeconfig.users[garden-dev--foo].exec.args"))
+	})
+
+	It("should only accept the legacy flags following the configured args", func() {
+		configMap.Data[constants.DataKeyKubeconfig] = kubeconfig("garden-dev", "foo", `    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: kubectl
+      args:
+      - gardenlogin
+      - get-client-certificate
+      - --name=foo
+      - --namespace=garden-dev
+      - --garden-cluster-identity=landscape`)
+
+		Expect(validate()).To(BeEmpty())
+
+		configMap.Data[constants.DataKeyKubeconfig] = kubeconfig("garden-dev", "foo", `    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: kubectl
+      args:
+      - gardenlogin
+      - get-client-certificate
+      - --name=foo
+      - --name=bar
+      - --server=https://rogue.example.com`)
+
+		Expect(fields(validate())).To(ConsistOf(
+			"data.kubeconfig.users[garden-dev--foo].exec.args[3]",
+			"data.kubeconfig.users[garden-dev--foo].exec.args[4]",
+		))
+	})
+
+	It("should reject environment variables that are not configured", func() {
+		configMap.Data[constants.DataKeyKubeconfig] = kubeconfig("garden-dev", "foo", execUser+`
+      env:
+      - name: GARDENLOGIN_CONFIG
+        value: /tmp/rogue`)
+
+		Expect(fields(validate())).To(ConsistOf("data.kubeconfig.users[garden-dev--foo].exec.env"))
+
+		config.Exec.Env = []configv1alpha1.ExecEnvVar{{Name: "GARDENLOGIN_CONFIG", Value: "/tmp/rogue"}}
+		Expect(validate()).To(BeEmpty())
+	})
+
+	It("should require a shoot reference for every cluster", func() {
+		configMap.Data[constants.DataKeyKubeconfig] = `apiVersion: v1
+kind: Config
+clusters:
+- name: foo
+  cluster:
+    server: https://api.foo.example.com
+`
+
+		Expect(fields(validate())).To(ConsistOf("data.kubeconfig.clusters[foo]"))
+	})
+
+	It("should validate the shoot reference of legacy kubeconfigs", func() {
+		configMap.Data[constants.DataKeyKubeconfig] = `apiVersion: v1
+kind: Config
+clusters:
+- name: bar
+  cluster:
+    server: https://api.bar.example.com
+contexts:
+- name: bar
+  context:
+    cluster: bar
+    user: bar
+users:
+- name: bar
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: kubectl
+      args:
+      - gardenlogin
+      - get-client-certificate
+      - --name=bar
+      - --namespace=garden-dev
+`
+
+		Expect(fields(validate())).To(ConsistOf("data.kubeconfig.clusters[bar].shootRef"))
+	})
+
+	It("should reject a cluster extension referencing another shoot", func() {
+		configMap.Data[constants.DataKeyKubeconfig] = kubeconfig("garden-dev", "bar", execUser)
+
+		Expect(fields(validate())).To(ConsistOf("data.kubeconfig.clusters[garden-dev--bar].extensions[client.authentication.k8s.io/exec].shootRef"))
+	})
+
+	It("should reject a cluster extension that is no exec plugin config", func() {
+		configMap.Data[constants.DataKeyKubeconfig] = `apiVersion: v1
+kind: Config
+clusters:
+- name: foo
+  cluster:
+    server: https://api.foo.example.com
+    extensions:
+    - name: client.authentication.k8s.io/exec
+      extension:
+        foo: bar
+`
+
+		Expect(fields(validate())).To(ConsistOf(
+			"data.kubeconfig.clusters[foo].extensions[client.authentication.k8s.io/exec]",
+			"data.kubeconfig.clusters[foo]",
+		))
+	})
+
+	Context("project kubeconfig", func() {
+		BeforeEach(func() {
+			configMap.Name = constants.ProjectKubeconfigConfigMapName
+			configMap.Labels[constants.LabelKubeconfigScope] = constants.KubeconfigScopeProject
+		})
+
+		It("should accept the shoots of the namespace", func() {
+			configMap.Data[constants.DataKeyKubeconfig] = kubeconfig("garden-dev", "bar", execUser)
+
+			Expect(validate()).To(BeEmpty())
+		})
+
+		It("should reject shoots of other namespaces", func() {
+			configMap.Data[constants.DataKeyKubeconfig] = kubeconfig("garden-prod", "bar", execUser)
+
+			Expect(fields(validate())).To(ConsistOf("data.kubeconfig.clusters[garden-prod--bar].extensions[client.authentication.k8s.io/exec].shootRef.namespace"))
+		})
+	})
+
+	Context("bundle kubeconfig", func() {
+		It("should accept shoots of other namespaces", func() {
+			configMap.Name = "foo.bundle.kubeconfig"
+			configMap.Labels[constants.LabelKubeconfigScope] = constants.KubeconfigScopeBundle
+			configMap.Data[constants.DataKeyKubeconfig] = kubeconfig("garden-prod", "bar", execUser)
+
+			Expect(validate()).To(BeEmpty())
+		})
+	})
+
+	Context("garden kubeconfig", func() {
+		BeforeEach(func() {
+			configMap.Name = constants.GardenKubeconfigConfigMapName
+			configMap.Labels[constants.LabelKubeconfigScope] = constants.KubeconfigScopeGarden
+			config.Garden = configv1alpha1.GardenKubeconfigConfiguration{
+				Exec: &configv1alpha1.GardenExecConfiguration{Command: "gardenctl"},
+			}
+		})
+
+		It("should only allow the configured garden exec command", func() {
+			configMap.Data[constants.DataKeyKubeconfig] = `apiVersion: v1
+kind: Config
+users:
+- name: garden
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: gardenctl
+`
+			Expect(validate()).To(BeEmpty())
+
+			configMap.Data[constants.DataKeyKubeconfig] = kubeconfig("garden-dev", "foo", execUser)
+			Expect(fields(validate())).To(ConsistOf(
+				"data.kubeconfig.users[garden-dev--foo].exec.command",
+				"data.kubeconfig.users[garden-dev--foo].exec.args[0]",
+				"data.kubeconfig.users[garden-dev--foo].exec.args[1]",
+				"data.kubeconfig.clusters[garden-dev--foo].extensions[client.authentication.k8s.io/exec]",
+			))
+		})
+
+		It("should only allow the kubelogin args of the configured oidc provider", func() {
+			config.Garden = configv1alpha1.GardenKubeconfigConfiguration{
+				OIDC: &configv1alpha1.GardenOIDCConfiguration{IssuerURL: "https://issuer.example.com", ClientID: "gardenlogin"},
+			}
+
+			configMap.Data[constants.DataKeyKubeconfig] = `apiVersion: v1
+kind: Config
+users:
+- name: garden
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: kubectl
+      args:
+      - oidc-login
+      - get-token
+      - --oidc-issuer-url=https://issuer.example.com
+      - --oidc-client-id=gardenlogin
+`
+			Expect(validate()).To(BeEmpty())
+
+			configMap.Data[constants.DataKeyKubeconfig] = strings.Replace(configMap.Data[constants.DataKeyKubeconfig], "https://issuer.example.com", "https://rogue.example.com", 1)
+			Expect(fields(validate())).To(ConsistOf("data.kubeconfig.users[garden].exec.args"))
+		})
+	})
+
+	Describe("#kubeconfigScope", func() {
+		scopePath := field.NewPath("metadata", "labels").Key(constants.LabelKubeconfigScope)
+
+		DescribeTable("should derive the scope from the name",
+			func(name string, label string, expectedScope string) {
+				configMap.Name = name
+				if label != "" {
+					configMap.Labels[constants.LabelKubeconfigScope] = label
+				}
+
+				scope, err := kubeconfigScope(configMap, scopePath)
+				Expect(err).To(BeNil())
+				Expect(scope).To(Equal(expectedScope))
+			},
+			Entry("shoot kubeconfig", "foo.kubeconfig", "", ""),
+			Entry("project kubeconfig", constants.ProjectKubeconfigConfigMapName, constants.KubeconfigScopeProject, constants.KubeconfigScopeProject),
+			Entry("garden kubeconfig", constants.GardenKubeconfigConfigMapName, constants.KubeconfigScopeGarden, constants.KubeconfigScopeGarden),
+			Entry("bundle kubeconfig", "sre.bundle.kubeconfig", constants.KubeconfigScopeBundle, constants.KubeconfigScopeBundle),
+			Entry("kubeconfig of a shoot named project", constants.ProjectKubeconfigConfigMapName, "", ""),
+		)
+
+		DescribeTable("should reject a forged scope label",
+			func(name string, label string, expectedType field.ErrorType) {
+				configMap.Name = name
+				if label != "" {
+					configMap.Labels[constants.LabelKubeconfigScope] = label
+				}
+
+				_, err := kubeconfigScope(configMap, scopePath)
+				Expect(err).To(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(expectedType),
+					"Field": Equal(scopePath.String()),
+				})))
+			},
+			Entry("scope label on a shoot kubeconfig", "foo.kubeconfig", constants.KubeconfigScopeBundle, field.ErrorTypeForbidden),
+			Entry("unknown scope", constants.ProjectKubeconfigConfigMapName, "foo", field.ErrorTypeNotSupported),
+			Entry("scope not matching the name", constants.ProjectKubeconfigConfigMapName, constants.KubeconfigScopeBundle, field.ErrorTypeNotSupported),
+			Entry("missing scope of a bundle kubeconfig", "sre.bundle.kubeconfig", "", field.ErrorTypeRequired),
+		)
+	})
+
+	Describe("#clusterShootRefs", func() {
+		It("should read the shoot references from the cluster extensions", func() {
+			kubeconfig, err := clientcmd.Load([]byte(kubeconfig("garden-dev", "foo", execUser)))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(clusterShootRefs(kubeconfig)).To(Equal(map[string]v1alpha1.ShootRef{
+				"garden-dev--foo": {Namespace: "garden-dev", Name: "foo"},
+			}))
+		})
+
+		It("should read the shoot references of legacy kubeconfigs from the exec args", func() {
+			kubeconfig := clientcmdapi.NewConfig()
+			kubeconfig.Clusters["foo"] = &clientcmdapi.Cluster{Server: "https://api.foo.garden-dev.example.com"}
+			kubeconfig.Clusters["bar"] = &clientcmdapi.Cluster{Server: "https://api.bar.garden-dev.example.com"}
+			kubeconfig.AuthInfos["foo"] = &clientcmdapi.AuthInfo{Exec: &clientcmdapi.ExecConfig{
+				Command: "kubectl",
+				Args:    []string{"gardenlogin", "get-client-certificate", "--name=foo", "--namespace=garden-dev", "--garden-cluster-identity=landscape"},
+			}}
+			kubeconfig.Contexts["foo"] = &clientcmdapi.Context{Cluster: "foo", AuthInfo: "foo"}
+			kubeconfig.Contexts["bar"] = &clientcmdapi.Context{Cluster: "bar", AuthInfo: "missing"}
+
+			Expect(clusterShootRefs(kubeconfig)).To(Equal(map[string]v1alpha1.ShootRef{
+				"foo": {Namespace: "garden-dev", Name: "foo"},
+			}))
+		})
+	})
+
+	Describe("#kubeconfigConfigMapWarnings", func() {
+		It("should not warn about the kubeconfig of the shoot", func() {
+			Expect(kubeconfigConfigMapWarnings(configMap, 1000, 10000)).To(BeEmpty())
+		})
+
+		It("should warn about a configmap close to the maximum object size", func() {
+			Expect(kubeconfigConfigMapWarnings(configMap, 9000, 10000)).To(ConsistOf(ContainSubstring("close to the maximum of 10000 bytes")))
+		})
+
+		It("should warn about a kubeconfig configmap without the kubeconfig suffix", func() {
+			configMap.Name = "foo"
+
+			Expect(kubeconfigConfigMapWarnings(configMap, 1000, 10000)).To(ConsistOf(ContainSubstring("does not end with .kubeconfig")))
+		})
+
+		It("should warn about deprecated exec API versions", func() {
+			configMap.Data[constants.DataKeyKubeconfig] = kubeconfig("garden-dev", "foo", `    exec:
+      apiVersion: client.authentication.k8s.io/v1alpha1
+      command: kubectl`)
+
+			Expect(kubeconfigConfigMapWarnings(configMap, 1000, 10000)).To(ConsistOf(ContainSubstring("user garden-dev--foo: exec API version client.authentication.k8s.io/v1alpha1 is deprecated")))
+		})
+	})
+
+	Describe("#validateKubeconfigAgainstGarden", func() {
+		var garden *configv1alpha1.GardenKubeconfigConfiguration
+
+		BeforeEach(func() {
+			garden = &configv1alpha1.GardenKubeconfigConfiguration{Server: "https://api.garden.example.com", CertificateAuthority: "ca"}
+		})
+
+		kubeconfigWithCluster := func(server string, caData []byte) *clientcmdapi.Config {
+			kubeconfig := clientcmdapi.NewConfig()
+			kubeconfig.Clusters["garden"] = &clientcmdapi.Cluster{
+				Server:                   server,
+				CertificateAuthorityData: caData,
+			}
+
+			return kubeconfig
+		}
+
+		It("should accept the server and the ca of the garden cluster", func() {
+			Expect(validateKubeconfigAgainstGarden(kubeconfigWithCluster("https://api.garden.example.com", []byte("ca")), garden, field.NewPath("data", "kubeconfig"))).To(BeEmpty())
+		})
+
+		It("should reject another server and certificate authority", func() {
+			Expect(fields(validateKubeconfigAgainstGarden(kubeconfigWithCluster("https://rogue.example.com", []byte("rogue")), garden, field.NewPath("data", "kubeconfig")))).To(ConsistOf(
+				"data.kubeconfig.clusters[garden].server",
+				"data.kubeconfig.clusters[garden].certificate-authority-data",
+			))
+		})
+	})
+
+	Describe("#validateKubeconfigAgainstShoot", func() {
+		var (
+			shoot  *gardencorev1beta1.Shoot
+			caCert = []byte("ca")
+		)
+
+		BeforeEach(func() {
+			shoot = &gardencorev1beta1.Shoot{
+				Status: gardencorev1beta1.ShootStatus{
+					AdvertisedAddresses: []gardencorev1beta1.ShootAdvertisedAddress{
+						{Name: "external", URL: "https://api.foo.garden-dev.example.com"},
+						{Name: "internal", URL: "https://api.foo.garden-dev.internal.example.com"},
+					},
+				},
+			}
+		})
+
+		kubeconfigWithCluster := func(server string, caData []byte) *clientcmdapi.Config {
+			kubeconfig := clientcmdapi.NewConfig()
+			kubeconfig.Clusters["garden-dev--foo-external"] = &clientcmdapi.Cluster{
+				Server:                   server,
+				CertificateAuthorityData: caData,
+			}
+
+			return kubeconfig
+		}
+
+		It("should accept the advertised addresses and the ca of the shoot", func() {
+			Expect(validateKubeconfigAgainstShoot(kubeconfigWithCluster("https://api.foo.garden-dev.internal.example.com", caCert), shoot, caCert, field.NewPath("data", "kubeconfig"))).To(BeEmpty())
+		})
+
+		It("should reject another server", func() {
+			Expect(fields(validateKubeconfigAgainstShoot(kubeconfigWithCluster("https://rogue.example.com", caCert), shoot, caCert, field.NewPath("data", "kubeconfig")))).To(ConsistOf(
+				"data.kubeconfig.clusters[garden-dev--foo-external].server",
+			))
+		})
+
+		It("should reject another certificate authority", func() {
+			Expect(fields(validateKubeconfigAgainstShoot(kubeconfigWithCluster("https://api.foo.garden-dev.example.com", []byte("rogue")), shoot, caCert, field.NewPath("data", "kubeconfig")))).To(ConsistOf(
+				"data.kubeconfig.clusters[garden-dev--foo-external].certificate-authority-data",
+			))
+		})
+	})
+})
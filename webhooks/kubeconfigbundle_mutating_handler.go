@@ -0,0 +1,133 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	gardenloginv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/gardenlogin/v1alpha1"
+	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1/constants"
+	"github.com/gardener/gardenlogin-controller-manager/internal/util"
+)
+
+// KubeconfigBundleMutator handles KubeconfigBundle.
+// It denies bundles that reference namespaces in which the requesting user is not allowed to list shoots, or whose configMaps cannot be read
+// by all readers of the bundle namespace, and records the requesting user
+// in the gardenlogin.gardener.cloud/requested-by annotation, so that the KubeconfigBundle controller can verify the authorization again later on.
+type KubeconfigBundleMutator struct {
+	client client.Client
+	Log    logr.Logger
+
+	// Decoder decodes objects
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = &KubeconfigBundleMutator{}
+
+// Handle handles admission requests.
+func (h *KubeconfigBundleMutator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	obj := &gardenloginv1alpha1.KubeconfigBundle{}
+	if err := h.decoder.Decode(req, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if req.AdmissionRequest.Operation == admissionv1.Update {
+		oldObj := &gardenloginv1alpha1.KubeconfigBundle{}
+		if err := h.decoder.DecodeRaw(req.AdmissionRequest.OldObject, oldObj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+
+		// the requester only changes if the spec changes, e.g. the garbage collector updating the metadata must not take over the bundle.
+		// The annotation is restored in case someone else tries to change it.
+		if requestedBy, ok := oldObj.Annotations[constants.AnnotationRequestedBy]; ok && apiequality.Semantic.DeepEqual(oldObj.Spec, obj.Spec) {
+			return h.patchResponse(req, obj, requestedBy)
+		}
+	}
+
+	userInfo := req.AdmissionRequest.UserInfo
+
+	for _, namespace := range obj.Spec.Namespaces {
+		allowed, err := util.CanListShoots(ctx, h.client, userInfo, namespace)
+		if err != nil {
+			h.Log.Error(err, "failed to review access", "namespace", namespace)
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+
+		if !allowed {
+			reason := fmt.Sprintf("not allowed to list shoots in namespace %s", namespace)
+			h.Log.Info("admission request denied", "reason", reason, "user", userInfo.Username)
+
+			return admission.Denied(reason)
+		}
+
+		// the bundled kubeconfig can be read by everyone who can read the configMaps of the bundle namespace
+		reader, err := util.UnauthorizedConfigMapReader(ctx, h.client, obj.Namespace, namespace)
+		if err != nil {
+			h.Log.Error(err, "failed to review access of the readers of the bundle namespace", "namespace", namespace)
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+
+		if reader != "" {
+			reason := fmt.Sprintf("%s can read configmaps in namespace %s but not in namespace %s", reader, obj.Namespace, namespace)
+			h.Log.Info("admission request denied", "reason", reason, "user", userInfo.Username)
+
+			return admission.Denied(reason)
+		}
+	}
+
+	requestedBy, err := json.Marshal(userInfo)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	return h.patchResponse(req, obj, string(requestedBy))
+}
+
+// patchResponse returns a response that patches the given requestedBy annotation into the object of the request
+func (h *KubeconfigBundleMutator) patchResponse(req admission.Request, obj *gardenloginv1alpha1.KubeconfigBundle, requestedBy string) admission.Response {
+	if obj.Annotations == nil {
+		obj.Annotations = make(map[string]string)
+	}
+
+	obj.Annotations[constants.AnnotationRequestedBy] = requestedBy
+
+	marshaled, err := json.Marshal(obj)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+var _ inject.Client = &KubeconfigBundleMutator{}
+
+// A client will be automatically injected.
+
+// InjectClient injects the client.
+func (h *KubeconfigBundleMutator) InjectClient(c client.Client) error {
+	h.client = c
+	return nil
+}
+
+// KubeconfigBundleMutator implements admission.DecoderInjector.
+// A decoder will be automatically injected.
+
+// InjectDecoder injects the decoder.
+func (h *KubeconfigBundleMutator) InjectDecoder(d *admission.Decoder) error {
+	h.decoder = d
+	return nil
+}
@@ -0,0 +1,230 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	gardenloginv1alpha1 "github.com/gardener/gardenlogin-controller-manager/api/gardenlogin/v1alpha1"
+	"github.com/gardener/gardenlogin-controller-manager/api/v1alpha1/constants"
+)
+
+// subjectAccessReviewClient answers the creation of SubjectAccessReviews with the result of allowed and the configured error
+type subjectAccessReviewClient struct {
+	client.Client
+	err     error
+	allowed func(spec authorizationv1.SubjectAccessReviewSpec) bool
+}
+
+func (c *subjectAccessReviewClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if review, ok := obj.(*authorizationv1.SubjectAccessReview); ok {
+		review.Status.Allowed = c.allowed(review.Spec)
+		return c.err
+	}
+
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+var _ = Describe("KubeconfigBundleMutator", func() {
+	var (
+		ctx     context.Context
+		mutator *KubeconfigBundleMutator
+		c       *subjectAccessReviewClient
+		bundle  *gardenloginv1alpha1.KubeconfigBundle
+
+		// shootListers are the users allowed to list the shoots per namespace
+		shootListers map[string][]string
+		// configMapReaders are the groups allowed to read the configMaps per namespace
+		configMapReaders map[string][]string
+	)
+
+	contains := func(values []string, value string) bool {
+		for _, v := range values {
+			if v == value {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	request := func(operation admissionv1.Operation, obj *gardenloginv1alpha1.KubeconfigBundle, oldObj *gardenloginv1alpha1.KubeconfigBundle) admission.Request {
+		raw, err := json.Marshal(obj)
+		Expect(err).ToNot(HaveOccurred())
+
+		req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: operation,
+			Object:    runtime.RawExtension{Raw: raw},
+			UserInfo:  authenticationv1.UserInfo{Username: "alice", Groups: []string{"sre"}},
+		}}
+
+		if oldObj != nil {
+			oldRaw, err := json.Marshal(oldObj)
+			Expect(err).ToNot(HaveOccurred())
+
+			req.OldObject = runtime.RawExtension{Raw: oldRaw}
+		}
+
+		return req
+	}
+
+	// requestedBy returns the requested-by annotation patched by the given response
+	requestedBy := func(resp admission.Response) string {
+		for _, patch := range resp.Patches {
+			switch patch.Path {
+			case "/metadata/annotations":
+				annotations, ok := patch.Value.(map[string]interface{})
+				Expect(ok).To(BeTrue())
+
+				value, _ := annotations[constants.AnnotationRequestedBy].(string)
+
+				return value
+			case "/metadata/annotations/gardenlogin.gardener.cloud~1requested-by":
+				value, _ := patch.Value.(string)
+				return value
+			}
+		}
+
+		return ""
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(gardenloginv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		shootListers = map[string][]string{
+			"garden-sre":       {"alice"},
+			"garden-project-a": {"alice"},
+		}
+		configMapReaders = map[string][]string{
+			"garden-sre":       {"sre"},
+			"garden-project-a": {"sre"},
+		}
+
+		roleBinding := &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "readers", Namespace: "garden-sre"},
+			Subjects: []rbacv1.Subject{
+				{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "sre"},
+			},
+		}
+
+		c = &subjectAccessReviewClient{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(roleBinding).Build(),
+			allowed: func(spec authorizationv1.SubjectAccessReviewSpec) bool {
+				attributes := spec.ResourceAttributes
+
+				switch attributes.Resource {
+				case "shoots":
+					return attributes.Verb == "list" && contains(shootListers[attributes.Namespace], spec.User)
+				case "configmaps":
+					return len(spec.Groups) > 0 && contains(configMapReaders[attributes.Namespace], spec.Groups[0])
+				default:
+					return false
+				}
+			},
+		}
+
+		decoder, err := admission.NewDecoder(scheme)
+		Expect(err).ToNot(HaveOccurred())
+
+		mutator = &KubeconfigBundleMutator{Log: logr.Discard()}
+		Expect(mutator.InjectClient(c)).To(Succeed())
+		Expect(mutator.InjectDecoder(decoder)).To(Succeed())
+
+		bundle = &gardenloginv1alpha1.KubeconfigBundle{
+			ObjectMeta: metav1.ObjectMeta{Name: "bundle", Namespace: "garden-sre"},
+			Spec: gardenloginv1alpha1.KubeconfigBundleSpec{
+				Namespaces: []string{"garden-sre", "garden-project-a"},
+			},
+		}
+	})
+
+	It("should record the requester of an allowed bundle", func() {
+		resp := mutator.Handle(ctx, request(admissionv1.Create, bundle, nil))
+
+		Expect(resp.Allowed).To(BeTrue())
+
+		userInfo := &authenticationv1.UserInfo{}
+		Expect(json.Unmarshal([]byte(requestedBy(resp)), userInfo)).To(Succeed())
+		Expect(userInfo.Username).To(Equal("alice"))
+		Expect(userInfo.Groups).To(ConsistOf("sre"))
+	})
+
+	It("should deny a bundle referencing a namespace in which the requester may not list shoots", func() {
+		shootListers["garden-project-a"] = nil
+
+		resp := mutator.Handle(ctx, request(admissionv1.Create, bundle, nil))
+
+		Expect(resp.Allowed).To(BeFalse())
+		Expect(resp.Result.Reason).To(BeEquivalentTo("not allowed to list shoots in namespace garden-project-a"))
+	})
+
+	It("should deny a bundle whose namespace readers may not read the configmaps of a source namespace", func() {
+		configMapReaders["garden-project-a"] = nil
+
+		resp := mutator.Handle(ctx, request(admissionv1.Create, bundle, nil))
+
+		Expect(resp.Allowed).To(BeFalse())
+		Expect(resp.Result.Reason).To(BeEquivalentTo("Group sre can read configmaps in namespace garden-sre but not in namespace garden-project-a"))
+	})
+
+	It("should fail in case the access cannot be reviewed", func() {
+		c.err = errors.New("unavailable")
+
+		resp := mutator.Handle(ctx, request(admissionv1.Create, bundle, nil))
+
+		Expect(resp.Allowed).To(BeFalse())
+		Expect(resp.Result.Code).To(BeEquivalentTo(http.StatusInternalServerError))
+	})
+
+	It("should keep the requester in case the spec is not changed", func() {
+		oldBundle := bundle.DeepCopy()
+		oldBundle.Annotations = map[string]string{constants.AnnotationRequestedBy: `{"username":"bob"}`}
+
+		bundle.Annotations = map[string]string{constants.AnnotationRequestedBy: `{"username":"alice"}`}
+		bundle.Labels = map[string]string{"foo": "bar"}
+
+		resp := mutator.Handle(ctx, request(admissionv1.Update, bundle, oldBundle))
+
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(requestedBy(resp)).To(Equal(`{"username":"bob"}`))
+	})
+
+	It("should review the access again and record the new requester in case the spec is changed", func() {
+		oldBundle := bundle.DeepCopy()
+		oldBundle.Annotations = map[string]string{constants.AnnotationRequestedBy: `{"username":"bob"}`}
+		oldBundle.Spec.Namespaces = []string{"garden-sre"}
+
+		bundle.Annotations = map[string]string{constants.AnnotationRequestedBy: `{"username":"bob"}`}
+		shootListers["garden-project-a"] = nil
+
+		resp := mutator.Handle(ctx, request(admissionv1.Update, bundle, oldBundle))
+
+		Expect(resp.Allowed).To(BeFalse())
+		Expect(resp.Result.Reason).To(BeEquivalentTo("not allowed to list shoots in namespace garden-project-a"))
+	})
+})
@@ -0,0 +1,45 @@
+/*
+SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhooks
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// accessReviewCacheHit is the result label value for access reviews that were answered from the cache
+	accessReviewCacheHit = "hit"
+	// accessReviewCacheMiss is the result label value for access reviews that required a SubjectAccessReview
+	accessReviewCacheMiss = "miss"
+)
+
+var (
+	// accessReviewCacheRequestsTotal counts the lookups of the access review cache, labelled by the result (hit or miss)
+	accessReviewCacheRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gardenlogin_webhook_access_review_cache_requests_total",
+			Help: "Total number of access review cache lookups of the configMap validating webhook per result",
+		},
+		[]string{"result"},
+	)
+
+	// accessReviewsSkippedTotal counts the access reviews that were skipped, because the user is configured in accessReviewSkippedUsers
+	accessReviewsSkippedTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "gardenlogin_webhook_access_reviews_skipped_total",
+			Help: "Total number of access reviews of the configMap validating webhook that were skipped for trusted users",
+		},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		accessReviewCacheRequestsTotal,
+		accessReviewsSkippedTotal,
+	)
+}